@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// config holds the server settings. Values are read from the JSON file given
+// by -config, then overridden by any flag set explicitly on the command line.
+type config struct {
+	Engine    string `json:"engine"`
+	Transport string `json:"transport"`
+	Addr      string `json:"addr"`
+	ReadOnly  bool   `json:"read_only"`
+	// ProjectsFile is where Compose project locations are remembered.
+	// Empty means the default location under the user config directory.
+	ProjectsFile string `json:"projects_file"`
+	// StatsInterval is the background stats sampling interval in seconds;
+	// 0 disables recording.
+	StatsInterval int `json:"stats_interval"`
+	// StatsRetention is how many minutes of stats history are kept.
+	StatsRetention int `json:"stats_retention"`
+	// StatsFile persists recorded stats across restarts when set.
+	StatsFile string `json:"stats_file"`
+	// AlertInterval is how often alert rules are evaluated, in seconds;
+	// 0 disables alerts.
+	AlertInterval int `json:"alert_interval"`
+	// CopyDir confines the host side of container_cp_to and
+	// container_cp_from; empty disables host copies.
+	CopyDir string `json:"copy_dir"`
+	// BackupDir holds volume backups; empty disables the backup tools.
+	BackupDir string `json:"backup_dir"`
+}
+
+// loadConfig parses the command line into a config.
+func loadConfig(fs *flag.FlagSet, args []string) (*config, error) {
+	var cfg config
+	configPath := fs.String("config", "", "path to a JSON config file; flags set on the command line take precedence")
+	fs.StringVar(&cfg.Engine, "engine", "cli", `how to talk to Docker: "cli" runs the docker binary, "api" uses the Engine API socket (DOCKER_HOST or unix:///var/run/docker.sock)`)
+	fs.StringVar(&cfg.Transport, "transport", "stdio", `MCP transport: "stdio" for a single local client, "http" to serve streamable HTTP and legacy SSE`)
+	fs.StringVar(&cfg.Addr, "addr", "localhost:8080", "listen address for -transport http")
+	fs.BoolVar(&cfg.ReadOnly, "read-only", false, "only register tools that observe containers (no exec, restart, compose up/down)")
+	fs.StringVar(&cfg.ProjectsFile, "projects-file", "", "JSON file remembering Compose project directories (default: orbstack-mcp/projects.json under the user config directory)")
+	fs.IntVar(&cfg.StatsInterval, "stats-interval", 0, "record container stats in the background every N seconds for stats_history (0 disables recording)")
+	fs.IntVar(&cfg.StatsRetention, "stats-retention", 60, "minutes of recorded stats history to keep per container")
+	fs.StringVar(&cfg.StatsFile, "stats-file", "", "JSON file to persist recorded stats history across restarts (default: memory only)")
+	fs.IntVar(&cfg.AlertInterval, "alert-interval", 10, "evaluate alert rules every N seconds (0 disables the alert tools)")
+	fs.StringVar(&cfg.CopyDir, "copy-dir", "", "host directory container_cp_to copies from and container_cp_from may save to (default: host copies disabled)")
+	fs.StringVar(&cfg.BackupDir, "backup-dir", "", "host directory volume_backup writes archives to and volume_restore reads them from (default: volume backups disabled)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configPath == "" {
+		return &cfg, nil
+	}
+
+	// Remember the flags that were set explicitly so they can win over the file.
+	explicit := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = f.Value.String()
+	})
+
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	// Decode over the flag defaults so keys missing from the file keep them.
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", *configPath, err)
+	}
+
+	for name, value := range explicit {
+		if err := fs.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"read_only": true, "transport": "http"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want config
+	}{
+		{"defaults", nil, config{Engine: "cli", Transport: "stdio", Addr: "localhost:8080", StatsRetention: 60, AlertInterval: 10}},
+		{"flags only", []string{"-read-only", "-engine", "api"}, config{Engine: "api", Transport: "stdio", Addr: "localhost:8080", ReadOnly: true, StatsRetention: 60, AlertInterval: 10}},
+		{"file over defaults", []string{"-config", path}, config{Engine: "cli", Transport: "http", Addr: "localhost:8080", ReadOnly: true, StatsRetention: 60, AlertInterval: 10}},
+		{"projects file", []string{"-projects-file", "/tmp/projects.json"}, config{Engine: "cli", Transport: "stdio", Addr: "localhost:8080", ProjectsFile: "/tmp/projects.json", StatsRetention: 60, AlertInterval: 10}},
+		{"alerts disabled", []string{"-alert-interval", "0"}, config{Engine: "cli", Transport: "stdio", Addr: "localhost:8080", StatsRetention: 60}},
+		{"stats recorder", []string{"-stats-interval", "5", "-stats-retention", "30", "-stats-file", "/tmp/stats.json"}, config{Engine: "cli", Transport: "stdio", Addr: "localhost:8080", StatsInterval: 5, StatsRetention: 30, StatsFile: "/tmp/stats.json", AlertInterval: 10}},
+		{"copy dir", []string{"-copy-dir", "/tmp/copies"}, config{Engine: "cli", Transport: "stdio", Addr: "localhost:8080", StatsRetention: 60, AlertInterval: 10, CopyDir: "/tmp/copies"}},
+		{"backup dir", []string{"-backup-dir", "/tmp/backups"}, config{Engine: "cli", Transport: "stdio", Addr: "localhost:8080", StatsRetention: 60, AlertInterval: 10, BackupDir: "/tmp/backups"}},
+		{"flags over file", []string{"-config", path, "-transport", "stdio", "-read-only=false"}, config{Engine: "cli", Transport: "stdio", Addr: "localhost:8080", StatsRetention: 60, AlertInterval: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *cfg != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, *cfg)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_BadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"read_only": "yes"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path}); err == nil {
+		t.Error("expected error for malformed config file")
+	}
+}
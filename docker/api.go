@@ -0,0 +1,456 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// DefaultHost is the daemon address used when DOCKER_HOST is not set.
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// API implements Executor by talking to the Docker Engine HTTP API directly.
+// Commands without a native implementation are delegated to the CLI, so API
+// can be used anywhere an Executor is expected.
+type API struct {
+	client   *http.Client
+	baseURL  string
+	fallback Executor
+}
+
+// NewAPI returns an API client for the given daemon address
+// ("unix:///path/to/docker.sock" or "tcp://host:port").
+// An empty host falls back to DOCKER_HOST, then to DefaultHost.
+func NewAPI(host string) (*API, error) {
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	if host == "" {
+		host = DefaultHost
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host %q: %w", host, err)
+	}
+
+	a := &API{fallback: NewCLI()}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		a.baseURL = "http://docker"
+		a.client = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socket)
+				},
+			},
+		}
+	case "tcp", "http":
+		a.baseURL = "http://" + u.Host
+		a.client = &http.Client{}
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme %q: must be unix or tcp", u.Scheme)
+	}
+	return a, nil
+}
+
+// apiError is returned for non-2xx responses from the daemon.
+type apiError struct {
+	method     string
+	path       string
+	statusCode int
+	message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("docker API %s %s: %d: %s", e.method, e.path, e.statusCode, e.message)
+}
+
+func isNotFound(err error) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && ae.statusCode == http.StatusNotFound
+}
+
+// get performs a GET request and decodes the JSON response body into v.
+func (a *API) get(ctx context.Context, path string, query url.Values, v any) error {
+	target := a.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker API GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("docker API GET %s: %w", path, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &msg) != nil || msg.Message == "" {
+			msg.Message = strings.TrimSpace(string(body))
+		}
+		return &apiError{method: http.MethodGet, path: path, statusCode: resp.StatusCode, message: msg.Message}
+	}
+
+	if raw, ok := v.(*json.RawMessage); ok {
+		*raw = body
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("docker API GET %s: failed to decode response: %w", path, err)
+	}
+	return nil
+}
+
+// apiContainer mirrors the fields we use from "GET /containers/json".
+type apiContainer struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	State   string            `json:"State"`
+	Status  string            `json:"Status"`
+	Created int64             `json:"Created"`
+	Labels  map[string]string `json:"Labels"`
+	Ports   []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+	NetworkSettings struct {
+		Networks map[string]json.RawMessage `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// ListContainers implements ContainerLister.
+func (a *API) ListContainers(ctx context.Context, opts ListOptions) ([]Container, error) {
+	query := url.Values{}
+	if opts.All {
+		query.Set("all", "1")
+	}
+	if len(opts.Labels) > 0 {
+		filters, err := json.Marshal(map[string][]string{"label": opts.Labels})
+		if err != nil {
+			return nil, err
+		}
+		query.Set("filters", string(filters))
+	}
+
+	var raw []apiContainer
+	if err := a.get(ctx, "/containers/json", query, &raw); err != nil {
+		return nil, err
+	}
+
+	containers := make([]Container, 0, len(raw))
+	for _, r := range raw {
+		c := Container{
+			ID:      r.ID,
+			Image:   r.Image,
+			State:   r.State,
+			Status:  r.Status,
+			Labels:  r.Labels,
+			Created: time.Unix(r.Created, 0),
+		}
+		if len(r.Names) > 0 {
+			c.Name = strings.TrimPrefix(r.Names[0], "/")
+		}
+		for _, p := range r.Ports {
+			c.Ports = append(c.Ports, Port{IP: p.IP, PrivatePort: p.PrivatePort, PublicPort: p.PublicPort, Type: p.Type})
+		}
+		for name := range r.NetworkSettings.Networks {
+			c.Networks = append(c.Networks, name)
+		}
+		sort.Strings(c.Networks)
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+// apiStats mirrors the fields we use from "GET /containers/{id}/stats".
+type apiStats struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	CPUStats    apiCPUStats `json:"cpu_stats"`
+	PreCPUStats apiCPUStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	BlkioStats struct {
+		IOServiceBytesRecursive []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+	PidsStats struct {
+		Current uint64 `json:"current"`
+	} `json:"pids_stats"`
+}
+
+type apiCPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  uint32 `json:"online_cpus"`
+}
+
+// toStats converts a raw sample using the same formulas as "docker stats".
+func (s *apiStats) toStats() Stats {
+	st := Stats{
+		ID:       s.ID,
+		Name:     strings.TrimPrefix(s.Name, "/"),
+		MemLimit: s.MemoryStats.Limit,
+		PIDs:     s.PidsStats.Current,
+	}
+
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(s.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		st.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+
+	// Page cache is not counted as used memory: cgroup v1 reports it as
+	// total_inactive_file, cgroup v2 as inactive_file.
+	st.MemUsage = s.MemoryStats.Usage
+	cache, ok := s.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		cache = s.MemoryStats.Stats["inactive_file"]
+	}
+	if cache < st.MemUsage {
+		st.MemUsage -= cache
+	}
+	if st.MemLimit > 0 {
+		st.MemPercent = float64(st.MemUsage) / float64(st.MemLimit) * 100
+	}
+
+	for _, n := range s.Networks {
+		st.NetRx += n.RxBytes
+		st.NetTx += n.TxBytes
+	}
+	for _, b := range s.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(b.Op) {
+		case "read":
+			st.BlockRead += b.Value
+		case "write":
+			st.BlockWrite += b.Value
+		}
+	}
+	return st
+}
+
+// ContainerStats implements StatsReader.
+func (a *API) ContainerStats(ctx context.Context, container string) ([]Stats, error) {
+	if container != "" {
+		st, err := a.containerStats(ctx, container)
+		if err != nil {
+			return nil, err
+		}
+		return []Stats{st}, nil
+	}
+
+	running, err := a.ListContainers(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Each non-streaming stats request blocks until the daemon has two
+	// samples to compute CPU usage from, so fetch them concurrently.
+	stats := make([]Stats, len(running))
+	errs := make([]error, len(running))
+	var wg sync.WaitGroup
+	for i, c := range running {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats[i], errs[i] = a.containerStats(ctx, c.ID)
+		}()
+	}
+	wg.Wait()
+
+	result := make([]Stats, 0, len(running))
+	for i := range running {
+		if errs[i] != nil {
+			// The container may have stopped since it was listed.
+			if isNotFound(errs[i]) {
+				continue
+			}
+			return nil, errs[i]
+		}
+		result = append(result, stats[i])
+	}
+	return result, nil
+}
+
+func (a *API) containerStats(ctx context.Context, container string) (Stats, error) {
+	query := url.Values{"stream": {"false"}}
+	var raw apiStats
+	if err := a.get(ctx, "/containers/"+url.PathEscape(container)+"/stats", query, &raw); err != nil {
+		return Stats{}, err
+	}
+	return raw.toStats(), nil
+}
+
+// Exec implements Executor. "docker inspect" on containers is served by the
+// API; everything else is delegated to the CLI.
+func (a *API) Exec(ctx context.Context, args ...string) (string, error) {
+	if len(args) > 0 && args[0] == "inspect" {
+		if out, ok, err := a.inspect(ctx, args[1:]); ok {
+			return out, err
+		}
+	}
+	return a.fallback.Exec(ctx, args...)
+}
+
+// ExecCombined implements Executor by delegating to the CLI.
+func (a *API) ExecCombined(ctx context.Context, args ...string) (string, error) {
+	return a.fallback.ExecCombined(ctx, args...)
+}
+
+// Stream implements Executor by delegating to the CLI.
+func (a *API) Stream(ctx context.Context, onLine func(line string), args ...string) error {
+	return a.fallback.Stream(ctx, onLine, args...)
+}
+
+// StreamCombined implements Executor by delegating to the CLI.
+func (a *API) StreamCombined(ctx context.Context, onLine func(line string), args ...string) error {
+	return a.fallback.StreamCombined(ctx, onLine, args...)
+}
+
+// ExecTo implements Executor by delegating to the CLI.
+func (a *API) ExecTo(ctx context.Context, w io.Writer, args ...string) error {
+	return a.fallback.ExecTo(ctx, w, args...)
+}
+
+// inspect serves "docker inspect [--format T] NAME..." for containers.
+// ok is false when the arguments or the objects are not something the API
+// path handles (other flags, templates using unknown functions, images,
+// volumes, ...), in which case the caller should fall back to the CLI.
+func (a *API) inspect(ctx context.Context, args []string) (out string, ok bool, err error) {
+	var format string
+	var names []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--format" || args[i] == "-f":
+			if i+1 >= len(args) {
+				return "", false, nil
+			}
+			format = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			return "", false, nil
+		default:
+			names = append(names, args[i])
+		}
+	}
+	if len(names) == 0 {
+		return "", false, nil
+	}
+
+	var tmpl *template.Template
+	if format != "" {
+		tmpl, err = template.New("inspect").Funcs(templateFuncs).Parse(format)
+		if err != nil {
+			return "", false, nil
+		}
+	}
+
+	objects := make([]json.RawMessage, 0, len(names))
+	for _, name := range names {
+		var raw json.RawMessage
+		if err := a.get(ctx, "/containers/"+url.PathEscape(name)+"/json", nil, &raw); err != nil {
+			if isNotFound(err) {
+				return "", false, nil
+			}
+			return "", true, err
+		}
+		objects = append(objects, raw)
+	}
+
+	if tmpl == nil {
+		pretty, err := json.MarshalIndent(objects, "", "    ")
+		if err != nil {
+			return "", true, err
+		}
+		return string(pretty) + "\n", true, nil
+	}
+
+	var b strings.Builder
+	for _, raw := range objects {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var data map[string]any
+		if err := dec.Decode(&data); err != nil {
+			return "", true, fmt.Errorf("failed to decode inspect JSON: %w", err)
+		}
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", true, fmt.Errorf("template: %w", err)
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), true, nil
+}
+
+// templateFuncs is the subset of the docker CLI template functions
+// supported by the API path.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	// index overrides the builtin so that missing map keys render as an
+	// empty string, as they do for the CLI's typed structs.
+	"index": func(item any, keys ...any) (any, error) {
+		for _, key := range keys {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return "", nil
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("index: key %v is not a string", key)
+			}
+			item = m[k]
+		}
+		if item == nil {
+			return "", nil
+		}
+		return item, nil
+	},
+}
@@ -0,0 +1,315 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestAPI starts a fake Engine API on a temporary unix socket and returns
+// an API client connected to it. Unhandled commands go to a Mock.
+func newTestAPI(t *testing.T, handler http.Handler) (*API, *Mock) {
+	t.Helper()
+
+	// Unix socket paths are limited to ~104 bytes, so avoid t.TempDir's
+	// long per-test directory names.
+	dir, err := os.MkdirTemp("", "dockerapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: handler}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+
+	api, err := NewAPI("unix://" + socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := NewMock()
+	api.fallback = mock
+	return api, mock
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestNewAPI_Hosts(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "tcp://127.0.0.1:2375")
+	api, err := NewAPI("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.baseURL != "http://127.0.0.1:2375" {
+		t.Errorf("expected DOCKER_HOST to be used, got base URL %q", api.baseURL)
+	}
+
+	t.Setenv("DOCKER_HOST", "")
+	api, err = NewAPI("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.baseURL != "http://docker" {
+		t.Errorf("expected default unix socket, got base URL %q", api.baseURL)
+	}
+
+	if _, err := NewAPI("ssh://user@host"); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+}
+
+func TestAPI_ListContainers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "1" {
+			t.Errorf("expected all=1, got %q", r.URL.RawQuery)
+		}
+		var filters map[string][]string
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters); err != nil {
+			t.Errorf("failed to parse filters: %v", err)
+		}
+		if got := filters["label"]; len(got) != 1 || got[0] != "com.docker.compose.project=myapp" {
+			t.Errorf("unexpected label filter: %v", got)
+		}
+		writeJSON(w, []map[string]any{{
+			"Id":      "abc123",
+			"Names":   []string{"/myapp-web-1"},
+			"Image":   "nginx:latest",
+			"State":   "running",
+			"Status":  "Up 2 hours",
+			"Created": 1704067200,
+			"Labels": map[string]string{
+				"com.docker.compose.project":              "myapp",
+				"com.docker.compose.project.config_files": "/src/a.yml,/src/b.yml",
+			},
+			"Ports": []map[string]any{
+				{"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"},
+				{"PrivatePort": 443, "Type": "tcp"},
+			},
+			"NetworkSettings": map[string]any{
+				"Networks": map[string]any{"myapp_default": map[string]any{}},
+			},
+		}})
+	})
+	api, _ := newTestAPI(t, mux)
+
+	containers, err := api.ListContainers(context.Background(), ListOptions{All: true, Labels: []string{"com.docker.compose.project=myapp"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 1 {
+		t.Fatalf("expected 1 container, got %d", len(containers))
+	}
+
+	c := containers[0]
+	if c.Name != "myapp-web-1" {
+		t.Errorf("expected leading slash to be trimmed, got %q", c.Name)
+	}
+	// Label values containing commas survive intact, unlike docker ps output.
+	if got := c.Labels["com.docker.compose.project.config_files"]; got != "/src/a.yml,/src/b.yml" {
+		t.Errorf("unexpected config_files label: %q", got)
+	}
+	if got := FormatPorts(c.Ports); got != "0.0.0.0:8080->80/tcp, 443/tcp" {
+		t.Errorf("unexpected ports: %q", got)
+	}
+	if len(c.Networks) != 1 || c.Networks[0] != "myapp_default" {
+		t.Errorf("unexpected networks: %v", c.Networks)
+	}
+	if c.Created.Unix() != 1704067200 {
+		t.Errorf("unexpected created time: %v", c.Created)
+	}
+}
+
+const statsFixture = `{
+  "id": "abc123",
+  "name": "/web",
+  "cpu_stats": {"cpu_usage": {"total_usage": 300000000}, "system_cpu_usage": 20000000000, "online_cpus": 4},
+  "precpu_stats": {"cpu_usage": {"total_usage": 100000000}, "system_cpu_usage": 10000000000, "online_cpus": 4},
+  "memory_stats": {"usage": 209715200, "limit": 1073741824, "stats": {"inactive_file": 104857600}},
+  "networks": {"eth0": {"rx_bytes": 1000, "tx_bytes": 2000}, "eth1": {"rx_bytes": 500, "tx_bytes": 0}},
+  "blkio_stats": {"io_service_bytes_recursive": [{"op": "read", "value": 4096}, {"op": "write", "value": 8192}]},
+  "pids_stats": {"current": 7}
+}`
+
+func TestAPI_ContainerStats_Single(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/web/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("stream") != "false" {
+			t.Errorf("expected stream=false, got %q", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, statsFixture)
+	})
+	api, _ := newTestAPI(t, mux)
+
+	stats, err := api.ContainerStats(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(stats))
+	}
+
+	s := stats[0]
+	if s.Name != "web" {
+		t.Errorf("expected name 'web', got %q", s.Name)
+	}
+	// (200M / 10000M) * 4 CPUs * 100
+	if s.CPUPercent < 7.99 || s.CPUPercent > 8.01 {
+		t.Errorf("expected CPU 8%%, got %f", s.CPUPercent)
+	}
+	// 200MiB usage minus 100MiB inactive_file cache.
+	if s.MemUsage != 104857600 {
+		t.Errorf("expected mem usage 104857600, got %d", s.MemUsage)
+	}
+	if s.MemPercent < 9.76 || s.MemPercent > 9.77 {
+		t.Errorf("expected mem 9.77%%, got %f", s.MemPercent)
+	}
+	if s.NetRx != 1500 || s.NetTx != 2000 {
+		t.Errorf("expected net 1500/2000, got %d/%d", s.NetRx, s.NetTx)
+	}
+	if s.BlockRead != 4096 || s.BlockWrite != 8192 {
+		t.Errorf("expected block 4096/8192, got %d/%d", s.BlockRead, s.BlockWrite)
+	}
+	if s.PIDs != 7 {
+		t.Errorf("expected 7 PIDs, got %d", s.PIDs)
+	}
+}
+
+func TestAPI_ContainerStats_AllSkipsVanished(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "" {
+			t.Errorf("expected only running containers, got %q", r.URL.RawQuery)
+		}
+		writeJSON(w, []map[string]any{
+			{"Id": "web", "Names": []string{"/web"}},
+			{"Id": "gone", "Names": []string{"/gone"}},
+		})
+	})
+	mux.HandleFunc("GET /containers/web/stats", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, statsFixture)
+	})
+	mux.HandleFunc("GET /containers/gone/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, map[string]string{"message": "No such container: gone"})
+	})
+	api, _ := newTestAPI(t, mux)
+
+	stats, err := api.ContainerStats(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Name != "web" {
+		t.Errorf("expected only web stats, got %+v", stats)
+	}
+}
+
+func TestAPI_ErrorMessage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		writeJSON(w, map[string]string{"message": "daemon is shutting down"})
+	})
+	api, _ := newTestAPI(t, mux)
+
+	_, err := api.ListContainers(context.Background(), ListOptions{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "daemon is shutting down") {
+		t.Errorf("expected status and daemon message in error, got: %v", err)
+	}
+}
+
+const inspectFixture = `{
+  "Id": "abc123",
+  "State": {"Status": "running", "Health": {"Status": "healthy", "FailingStreak": 0}},
+  "Config": {"Labels": {"com.docker.compose.project.working_dir": "/src/myapp"}, "Healthcheck": {"Interval": 30000000000}}
+}`
+
+func TestAPI_ExecInspect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/web/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, inspectFixture)
+	})
+	api, mock := newTestAPI(t, mux)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"json func", []string{"inspect", "--format", "{{json .State.Health}}", "web"}, `{"FailingStreak":0,"Status":"healthy"}` + "\n"},
+		{"large numbers keep precision", []string{"inspect", "--format", "{{json .Config.Healthcheck}}", "web"}, `{"Interval":30000000000}` + "\n"},
+		{"index existing label", []string{"inspect", "--format", `{{index .Config.Labels "com.docker.compose.project.working_dir"}}`, "web"}, "/src/myapp\n"},
+		{"index missing label", []string{"inspect", "--format", `{{index .Config.Labels "missing"}}`, "web"}, "\n"},
+		{"missing section", []string{"inspect", "--format", "{{json .State.Missing}}", "web"}, "null\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := api.Exec(ctx, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	raw, err := api.Exec(ctx, "inspect", "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var data []map[string]any
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatalf("expected JSON array from plain inspect: %v", err)
+	}
+	if len(data) != 1 || data[0]["Id"] != "abc123" {
+		t.Errorf("unexpected inspect output: %s", raw)
+	}
+
+	if len(mock.Calls()) != 0 {
+		t.Errorf("expected inspect to be served by the API, got CLI calls %v", mock.Calls())
+	}
+}
+
+func TestAPI_ExecFallsBackToCLI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/myimage/json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, map[string]string{"message": "No such container: myimage"})
+	})
+	api, mock := newTestAPI(t, mux)
+	ctx := context.Background()
+
+	mock.On("inspect myimage", "[image]", nil)
+	mock.On("logs --tail 10 web", "log line\n", nil)
+	mock.On("restart --time 10 web", "web\n", nil)
+
+	// Not a container: docker inspect also handles images, volumes, etc.
+	if out, err := api.Exec(ctx, "inspect", "myimage"); err != nil || out != "[image]" {
+		t.Errorf("expected CLI fallback for non-container inspect, got %q, %v", out, err)
+	}
+	if out, err := api.ExecCombined(ctx, "logs", "--tail", "10", "web"); err != nil || out != "log line\n" {
+		t.Errorf("expected CLI fallback for logs, got %q, %v", out, err)
+	}
+	if out, err := api.Exec(ctx, "restart", "--time", "10", "web"); err != nil || out != "web\n" {
+		t.Errorf("expected CLI fallback for restart, got %q, %v", out, err)
+	}
+}
@@ -1,12 +1,20 @@
 package docker
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"time"
 )
 
+// streamWaitDelay is how long a streaming command may take to exit after
+// being interrupted before it is killed.
+const streamWaitDelay = 5 * time.Second
+
 // CLI implements Executor by shelling out to the docker binary.
 type CLI struct{}
 
@@ -20,7 +28,7 @@ func (c *CLI) Exec(ctx context.Context, args ...string) (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, stderr.String())
+		return stdout.String(), fmt.Errorf("docker %s: %w: %s", args[0], err, stderr.String())
 	}
 	return stdout.String(), nil
 }
@@ -35,3 +43,111 @@ func (c *CLI) ExecCombined(ctx context.Context, args ...string) (string, error)
 	}
 	return combined.String(), nil
 }
+
+func (c *CLI) ExecTo(ctx context.Context, w io.Writer, args ...string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	stdout := &stopWriter{w: w, stop: cancel}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if stdout.err != nil {
+		return stdout.err
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("docker %s: %w: %s", args[0], err, stderr.String())
+	}
+	return nil
+}
+
+// stopWriter passes writes through to w and calls stop on the first failed
+// write, so a command whose output is no longer wanted is not left blocked
+// on a full pipe.
+type stopWriter struct {
+	w    io.Writer
+	stop func()
+	err  error
+}
+
+func (s *stopWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	if err != nil && s.err == nil {
+		s.err = err
+		s.stop()
+	}
+	return n, err
+}
+
+func (c *CLI) Stream(ctx context.Context, onLine func(line string), args ...string) error {
+	return c.stream(ctx, onLine, false, args)
+}
+
+func (c *CLI) StreamCombined(ctx context.Context, onLine func(line string), args ...string) error {
+	return c.stream(ctx, onLine, true, args)
+}
+
+// stream runs docker and passes each line of stdout, and of stderr too when
+// combined is set, to onLine.
+func (c *CLI) stream(ctx context.Context, onLine func(line string), combined bool, args []string) error {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	// Interrupt rather than kill on cancellation, so docker can shut down
+	// its connection to the daemon cleanly.
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = streamWaitDelay
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	var stderr bytes.Buffer
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if combined {
+		cmd.Stderr = w
+	}
+	if err := cmd.Start(); err != nil {
+		w.Close()
+		return fmt.Errorf("docker %s: %w", args[0], err)
+	}
+	// Close our copy of the write end so reads hit EOF when docker exits.
+	w.Close()
+
+	var last string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		last = scanner.Text()
+		onLine(last)
+	}
+	if err := scanner.Err(); err != nil {
+		// A line too long to scan stops reading; stop docker and drain the
+		// pipe so it is not left blocked on a full pipe.
+		cancel()
+		io.Copy(io.Discard, r)
+		cmd.Wait()
+		if parent.Err() != nil {
+			return parent.Err()
+		}
+		return fmt.Errorf("docker %s: failed to read output: %w", args[0], err)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if parent.Err() != nil {
+			return parent.Err()
+		}
+		if !combined {
+			last = stderr.String()
+		}
+		return fmt.Errorf("docker %s: %w: %s", args[0], err, last)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDocker puts a shell script named docker first on PATH.
+func fakeDocker(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestCLI_Exec_FailureKeepsStdout(t *testing.T) {
+	fakeDocker(t, "echo '{\"id\":\"abc\"}'; echo 'Error: No such object: gone' >&2; exit 1\n")
+
+	out, err := NewCLI().Exec(context.Background(), "inspect", "abc", "gone")
+	if err == nil || !strings.Contains(err.Error(), "No such object: gone") {
+		t.Errorf("expected the missing object in the error, got %v", err)
+	}
+	if out != "{\"id\":\"abc\"}\n" {
+		t.Errorf("expected the output for the object that exists, got %q", out)
+	}
+}
+
+func TestCLI_StreamCombined(t *testing.T) {
+	fakeDocker(t, "echo out; echo err >&2; echo \"args: $*\"\n")
+
+	var lines []string
+	err := NewCLI().StreamCombined(context.Background(), func(line string) {
+		lines = append(lines, line)
+	}, "logs", "-f", "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Join(lines, "|"); got != "out|err|args: logs -f web" {
+		t.Errorf("unexpected lines: %q", got)
+	}
+}
+
+func TestCLI_StreamCombined_Cancel(t *testing.T) {
+	fakeDocker(t, "echo started\nexec sleep 30\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var lines []string
+	err := NewCLI().StreamCombined(ctx, func(line string) {
+		lines = append(lines, line)
+	}, "logs", "-f", "web")
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "started" {
+		t.Errorf("expected output before cancellation, got %v", lines)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the command to stop promptly on cancellation, took %s", elapsed)
+	}
+}
+
+func TestCLI_StreamCombined_Failure(t *testing.T) {
+	fakeDocker(t, "echo 'Error: No such container: ghost' >&2; exit 1\n")
+
+	err := NewCLI().StreamCombined(context.Background(), func(string) {}, "logs", "-f", "ghost")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "No such container: ghost") {
+		t.Errorf("expected docker output in error, got: %v", err)
+	}
+}
+
+func TestCLI_StreamCombined_LineTooLong(t *testing.T) {
+	// One 2MiB line, then more output than a pipe buffers.
+	fakeDocker(t, "echo first\nhead -c 2097152 /dev/zero | tr '\\0' x\necho\nhead -c 1048576 /dev/zero\nexec sleep 30\n")
+
+	start := time.Now()
+	var lines []string
+	err := NewCLI().StreamCombined(context.Background(), func(line string) {
+		lines = append(lines, line)
+	}, "build", ".")
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("expected a line too long error, got %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "first" {
+		t.Errorf("expected the lines before the long one, got %d lines", len(lines))
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the command to stop promptly, took %s", elapsed)
+	}
+}
+
+func TestCLI_Stream_StdoutOnly(t *testing.T) {
+	fakeDocker(t, "echo '{\"a\":1}'; echo warning >&2; echo '{\"a\":2}'\n")
+
+	var lines []string
+	err := NewCLI().Stream(context.Background(), func(line string) {
+		lines = append(lines, line)
+	}, "events", "--format", "{{json .}}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(lines, "|"); got != `{"a":1}|{"a":2}` {
+		t.Errorf("expected only stdout lines, got %q", got)
+	}
+}
+
+func TestCLI_Stream_CancelAndFailure(t *testing.T) {
+	fakeDocker(t, "if [ \"$1\" = ghost ]; then echo 'Error: bad filter' >&2; exit 1; fi\necho started\nexec sleep 30\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var lines []string
+	err := NewCLI().Stream(ctx, func(line string) { lines = append(lines, line) }, "events")
+	if !errors.Is(err, context.DeadlineExceeded) || len(lines) != 1 {
+		t.Errorf("expected one line then a deadline error, got %v / %v", lines, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the command to stop promptly on cancellation, took %s", elapsed)
+	}
+
+	err = NewCLI().Stream(context.Background(), func(string) {}, "ghost")
+	if err == nil || !strings.Contains(err.Error(), "bad filter") {
+		t.Errorf("expected stderr in error, got %v", err)
+	}
+}
+
+func TestCLI_ExecTo(t *testing.T) {
+	fakeDocker(t, "if [ \"$1\" = ghost ]; then echo 'Error: No such container: ghost' >&2; exit 1; fi\nprintf 'a\\000b'; echo warning >&2\n")
+
+	var buf bytes.Buffer
+	if err := NewCLI().ExecTo(context.Background(), &buf, "cp", "web:/bin/app", "-"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "a\x00b" {
+		t.Errorf("expected raw stdout only, got %q", buf.String())
+	}
+
+	err := NewCLI().ExecTo(context.Background(), &buf, "ghost")
+	if err == nil || !strings.Contains(err.Error(), "No such container: ghost") {
+		t.Errorf("expected stderr in error, got %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errTooMuch }
+
+var errTooMuch = errors.New("too much output")
+
+func TestCLI_ExecTo_WriteErrorStopsCommand(t *testing.T) {
+	fakeDocker(t, "echo started\nexec sleep 30\n")
+
+	start := time.Now()
+	err := NewCLI().ExecTo(context.Background(), failingWriter{}, "export", "web")
+	if !errors.Is(err, errTooMuch) {
+		t.Errorf("expected the write error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the command to stop promptly, took %s", elapsed)
+	}
+}
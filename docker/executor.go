@@ -1,14 +1,53 @@
 package docker
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // Executor abstracts docker CLI execution for testability.
 type Executor interface {
 	// Exec runs "docker <args>" and returns stdout.
-	// Returns error (wrapping stderr) on non-zero exit.
+	// Returns error (wrapping stderr) on non-zero exit, along with whatever
+	// was written to stdout, such as the containers a "docker inspect" did
+	// find when some of those it names no longer exist.
 	Exec(ctx context.Context, args ...string) (string, error)
 
 	// ExecCombined runs "docker <args>" and returns combined stdout+stderr.
 	// Useful for "docker logs" which outputs to both streams.
 	ExecCombined(ctx context.Context, args ...string) (string, error)
+
+	// Stream runs "docker <args>" and calls onLine for each line of stdout
+	// as it is produced, for long-running commands whose stdout is
+	// machine-readable, such as "docker events --format". Stderr is only
+	// used to describe a failure. Like StreamCombined, it stops the command
+	// and returns ctx.Err() once ctx is done.
+	Stream(ctx context.Context, onLine func(line string), args ...string) error
+
+	// StreamCombined runs "docker <args>" and calls onLine for each line of
+	// combined stdout+stderr as it is produced, for long-running commands
+	// such as "docker logs -f". It returns when the command exits or ctx is
+	// done, in which case the command is stopped and ctx.Err() is returned.
+	StreamCombined(ctx context.Context, onLine func(line string), args ...string) error
+
+	// ExecTo runs "docker <args>" and copies its stdout to w as it is
+	// produced, for raw or binary output such as the tar stream written by
+	// "docker cp CONTAINER:PATH -". Stderr is only used to describe a
+	// failure. If a write to w fails, the command is stopped and the write
+	// error is returned.
+	ExecTo(ctx context.Context, w io.Writer, args ...string) error
+}
+
+// ContainerLister is implemented by executors that can list containers as
+// typed values, without going through "docker ps" text output.
+type ContainerLister interface {
+	ListContainers(ctx context.Context, opts ListOptions) ([]Container, error)
+}
+
+// StatsReader is implemented by executors that can read resource usage as
+// typed values, without going through "docker stats" text output.
+type StatsReader interface {
+	// ContainerStats returns a single stats sample for the given container,
+	// or for every running container when container is empty.
+	ContainerStats(ctx context.Context, container string) ([]Stats, error)
 }
@@ -3,14 +3,20 @@ package docker
 import (
 	"context"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
+	"sync"
 )
 
 // Mock implements Executor for testing.
-// Register expected command outputs with On().
+// Register expected command outputs with On() or OnSequence().
+// It is safe for concurrent use.
 type Mock struct {
-	calls   [][]string
-	results map[string]mockResult
+	mu        sync.Mutex
+	calls     [][]string
+	results   map[string]mockResult
+	sequences map[string][]string
 }
 
 type mockResult struct {
@@ -27,12 +33,28 @@ func NewMock() *Mock {
 // On registers a response for a specific docker command.
 // The key is the joined args (e.g., "ps --format {{json .}}").
 func (m *Mock) On(args string, output string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.results[args] = mockResult{output: output, err: err}
 }
 
+// OnSequence registers successive outputs for a command that is run
+// repeatedly, such as a status poll. Each call returns the next output; once
+// exhausted, the last output is repeated.
+func (m *Mock) OnSequence(args string, outputs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sequences == nil {
+		m.sequences = make(map[string][]string)
+	}
+	m.sequences[args] = outputs
+}
+
 // Calls returns all recorded invocations.
 func (m *Mock) Calls() [][]string {
-	return m.calls
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.calls)
 }
 
 func (m *Mock) Exec(ctx context.Context, args ...string) (string, error) {
@@ -43,9 +65,55 @@ func (m *Mock) ExecCombined(ctx context.Context, args ...string) (string, error)
 	return m.exec(args)
 }
 
+// Stream behaves like StreamCombined; the mock does not distinguish stdout
+// from stderr.
+func (m *Mock) Stream(ctx context.Context, onLine func(line string), args ...string) error {
+	return m.StreamCombined(ctx, onLine, args...)
+}
+
+// StreamCombined emits the registered output line by line, stopping early
+// if ctx is done, then returns the registered error.
+func (m *Mock) StreamCombined(ctx context.Context, onLine func(line string), args ...string) error {
+	output, err := m.exec(args)
+	if output == "" {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		onLine(line)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// ExecTo writes the registered output to w, then returns the registered
+// error, or the write error if w refuses the output.
+func (m *Mock) ExecTo(ctx context.Context, w io.Writer, args ...string) error {
+	output, err := m.exec(args)
+	if output != "" {
+		if _, werr := io.WriteString(w, output); werr != nil {
+			return werr
+		}
+	}
+	return err
+}
+
 func (m *Mock) exec(args []string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.calls = append(m.calls, args)
 	key := strings.Join(args, " ")
+	if seq := m.sequences[key]; len(seq) > 0 {
+		output := seq[0]
+		if len(seq) > 1 {
+			m.sequences[key] = seq[1:]
+		}
+		return output, nil
+	}
 	if r, ok := m.results[key]; ok {
 		return r.output, r.err
 	}
@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ListOptions filters the result of ContainerLister.ListContainers.
+type ListOptions struct {
+	// All includes stopped containers.
+	All bool
+	// Labels restricts the result to containers carrying every label,
+	// given as "key" or "key=value".
+	Labels []string
+}
+
+// Container is a container summary, as returned by "GET /containers/json".
+type Container struct {
+	ID       string
+	Name     string
+	Image    string
+	State    string
+	Status   string
+	Labels   map[string]string
+	Ports    []Port
+	Networks []string
+	Created  time.Time
+}
+
+// Port is a single port mapping of a container.
+type Port struct {
+	IP          string
+	PrivatePort int
+	PublicPort  int
+	Type        string
+}
+
+// String formats the port the same way "docker ps" does,
+// e.g. "0.0.0.0:8080->80/tcp" or "5432/tcp".
+func (p Port) String() string {
+	if p.PublicPort == 0 {
+		return fmt.Sprintf("%d/%s", p.PrivatePort, p.Type)
+	}
+	return fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type)
+}
+
+// FormatPorts joins ports into the comma-separated form used by "docker ps".
+func FormatPorts(ports []Port) string {
+	parts := make([]string, 0, len(ports))
+	for _, p := range ports {
+		parts = append(parts, p.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Stats is a single resource usage sample of a container.
+type Stats struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	CPUPercent float64 `json:"cpu_percent"`
+	MemUsage   uint64  `json:"mem_usage_bytes"`
+	MemLimit   uint64  `json:"mem_limit_bytes"`
+	MemPercent float64 `json:"mem_percent"`
+	NetRx      uint64  `json:"net_rx_bytes"`
+	NetTx      uint64  `json:"net_tx_bytes"`
+	BlockRead  uint64  `json:"block_read_bytes"`
+	BlockWrite uint64  `json:"block_write_bytes"`
+	PIDs       uint64  `json:"pids"`
+}
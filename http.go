@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// shutdownTimeout bounds how long serveHTTP waits for in-flight requests
+// after the context is cancelled.
+const shutdownTimeout = 10 * time.Second
+
+// newHTTPHandler serves the streamable HTTP transport on /mcp and the legacy
+// SSE transport on /sse. Every session gets its own server from newServer,
+// so sessions never share per-session state such as log levels or
+// subscriptions.
+func newHTTPHandler(newServer func() *mcp.Server) http.Handler {
+	getServer := func(*http.Request) *mcp.Server { return newServer() }
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(getServer, &mcp.StreamableHTTPOptions{
+		SessionTimeout: 30 * time.Minute,
+	}))
+	mux.Handle("/sse", mcp.NewSSEHandler(getServer, nil))
+	return mux
+}
+
+// serveHTTP listens on addr until ctx is cancelled, then shuts down
+// gracefully.
+func serveHTTP(ctx context.Context, addr string, newServer func() *mcp.Server) error {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: newHTTPHandler(newServer),
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("orbstack-mcp listening on http://%s/mcp (SSE: http://%s/sse)", addr, addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// SSE streams are long-lived and never become idle, so force-close
+	// whatever is left once the grace period is over.
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+	"github.com/otsukatsuka/orbstack-mcp/tools"
+)
+
+func TestHTTPHandler_SessionPerClient(t *testing.T) {
+	var servers atomic.Int32
+	newServer := func() *mcp.Server {
+		servers.Add(1)
+		server := mcp.NewServer(&mcp.Implementation{Name: "orbstack-mcp", Version: "test"}, nil)
+		tools.RegisterAll(server, docker.NewMock(), tools.Options{})
+		return server
+	}
+
+	ts := httptest.NewServer(newHTTPHandler(newServer))
+	defer ts.Close()
+
+	ctx := context.Background()
+	transports := map[string]mcp.Transport{
+		"streamable": &mcp.StreamableClientTransport{Endpoint: ts.URL + "/mcp"},
+		"sse":        &mcp.SSEClientTransport{Endpoint: ts.URL + "/sse"},
+	}
+
+	var toolCounts []int
+	for name, transport := range transports {
+		client := mcp.NewClient(&mcp.Implementation{Name: name, Version: "test"}, nil)
+		session, err := client.Connect(ctx, transport, nil)
+		if err != nil {
+			t.Fatalf("%s: connect failed: %v", name, err)
+		}
+		defer session.Close()
+
+		res, err := session.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("%s: list tools failed: %v", name, err)
+		}
+		toolCounts = append(toolCounts, len(res.Tools))
+	}
+
+	if toolCounts[0] == 0 || toolCounts[0] != toolCounts[1] {
+		t.Errorf("expected both transports to expose the same non-empty tool set, got %v", toolCounts)
+	}
+	if got := servers.Load(); got != 2 {
+		t.Errorf("expected one server per session, got %d servers", got)
+	}
+}
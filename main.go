@@ -2,7 +2,14 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/otsukatsuka/orbstack-mcp/docker"
@@ -10,18 +17,100 @@ import (
 )
 
 func main() {
-	server := mcp.NewServer(
-		&mcp.Implementation{
-			Name:    "orbstack-mcp",
-			Version: "0.1.0",
-		},
-		nil,
-	)
-
-	exec := docker.NewCLI()
-	tools.RegisterAll(server, exec)
-
-	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+	cfg, err := loadConfig(flag.CommandLine, os.Args[1:])
+	if err != nil {
 		log.Fatal(err)
 	}
+
+	exec, err := newExecutor(cfg.Engine)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	projects, err := openProjects(cfg.ProjectsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var recorder *tools.StatsRecorder
+	if cfg.StatsInterval > 0 {
+		recorder, err = tools.NewStatsRecorder(exec, tools.StatsRecorderOptions{
+			Interval:  time.Duration(cfg.StatsInterval) * time.Second,
+			Retention: time.Duration(cfg.StatsRetention) * time.Minute,
+			File:      cfg.StatsFile,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		done := make(chan struct{})
+		go func() {
+			recorder.Run(ctx)
+			close(done)
+		}()
+		// Stop the recorder and let it save its history before exiting,
+		// even when the transport returns without a signal.
+		defer func() {
+			stop()
+			<-done
+		}()
+	}
+
+	var alerts *tools.AlertScheduler
+	if cfg.AlertInterval > 0 {
+		alerts = tools.NewAlertScheduler(exec, time.Duration(cfg.AlertInterval)*time.Second)
+		go alerts.Run(ctx)
+	}
+
+	newServer := func() *mcp.Server {
+		server := mcp.NewServer(
+			&mcp.Implementation{
+				Name:    "orbstack-mcp",
+				Version: "0.1.0",
+			},
+			nil,
+		)
+		tools.RegisterAll(server, exec, tools.Options{ReadOnly: cfg.ReadOnly, Projects: projects, Stats: recorder, Alerts: alerts, CopyDir: cfg.CopyDir, BackupDir: cfg.BackupDir})
+		return server
+	}
+
+	switch cfg.Transport {
+	case "stdio":
+		err = newServer().Run(ctx, &mcp.StdioTransport{})
+	case "http":
+		err = serveHTTP(ctx, cfg.Addr, newServer)
+	default:
+		err = fmt.Errorf("unknown transport %q: must be stdio or http", cfg.Transport)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// openProjects opens the Compose project registry at path, or at the default
+// location under the user config directory when path is empty. Without a
+// user config directory, projects are only remembered in memory.
+func openProjects(path string) (*tools.ProjectRegistry, error) {
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return tools.NewProjectRegistry(), nil
+		}
+		path = filepath.Join(dir, "orbstack-mcp", "projects.json")
+	}
+	return tools.OpenProjectRegistry(path)
+}
+
+// newExecutor returns the docker.Executor backend selected by -engine.
+func newExecutor(engine string) (docker.Executor, error) {
+	switch engine {
+	case "cli":
+		return docker.NewCLI(), nil
+	case "api":
+		return docker.NewAPI("")
+	default:
+		return nil, fmt.Errorf("unknown engine %q: must be cli or api", engine)
+	}
 }
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var errAlertsDisabled = fmt.Errorf("alerts are disabled; start the server with -alert-interval to enable them")
+
+type alertAddArgs struct {
+	Container string  `json:"container,omitempty" jsonschema:"container name or ID the rule applies to"`
+	Project   string  `json:"project,omitempty" jsonschema:"Compose project the rule applies to (every container of it)"`
+	Service   string  `json:"service,omitempty" jsonschema:"restrict a project rule to one service"`
+	Condition string  `json:"condition" jsonschema:"cpu_percent, mem_percent (threshold, percent of limit), restart_count (restart count increased) or unhealthy (health became unhealthy)"`
+	Above     float64 `json:"above,omitempty" jsonschema:"threshold for cpu_percent and mem_percent"`
+	For       int     `json:"for_seconds,omitempty" jsonschema:"seconds the threshold must be exceeded before alerting (default: 0, alert on the first sample)"`
+}
+
+type alertAddOutput struct {
+	Rule alertRule `json:"rule"`
+}
+
+type alertListArgs struct{}
+
+type alertRuleStatus struct {
+	alertRule
+	Firing []string `json:"firing" jsonschema:"containers the rule is currently tripped for"`
+}
+
+type alertListOutput struct {
+	IntervalSeconds float64           `json:"interval_seconds" jsonschema:"how often rules are evaluated"`
+	Rules           []alertRuleStatus `json:"rules"`
+	Recent          []alertEvent      `json:"recent" jsonschema:"most recent alerts, oldest first"`
+}
+
+type alertRemoveArgs struct {
+	ID string `json:"id" jsonschema:"rule ID as returned by alert_add, e.g. alert-1"`
+}
+
+type alertRemoveOutput struct {
+	ID string `json:"id"`
+}
+
+func handleAlertAdd(alerts *AlertScheduler, args alertAddArgs) (string, alertAddOutput, error) {
+	var out alertAddOutput
+	if alerts == nil {
+		return "", out, errAlertsDisabled
+	}
+	switch {
+	case args.Container != "" && args.Project != "":
+		return "", out, fmt.Errorf("specify either container or project, not both")
+	case args.Service != "" && args.Project == "":
+		return "", out, fmt.Errorf("service requires project")
+	case args.Container == "" && args.Project == "":
+		return "", out, fmt.Errorf("container or project is required")
+	}
+	switch args.Condition {
+	case conditionCPUPercent, conditionMemPercent:
+		if args.Above <= 0 {
+			return "", out, fmt.Errorf("%s requires a positive above threshold", args.Condition)
+		}
+	case conditionRestartCount, conditionUnhealthy:
+	default:
+		return "", out, fmt.Errorf("unknown condition %q: must be cpu_percent, mem_percent, restart_count or unhealthy", args.Condition)
+	}
+	if args.For < 0 {
+		return "", out, fmt.Errorf("for_seconds must not be negative")
+	}
+
+	out.Rule = alerts.add(alertRule{
+		Container: args.Container,
+		Project:   args.Project,
+		Service:   args.Service,
+		Condition: args.Condition,
+		Above:     args.Above,
+		For:       args.For,
+	})
+	return fmt.Sprintf("Added %s: %s on %s, checked every %s. Alerts are sent as logging notifications from logger %q once logging is enabled with logging/setLevel.",
+		out.Rule.ID, out.Rule.describe(), out.Rule.scope(), alerts.interval, alertLogger), out, nil
+}
+
+func handleAlertList(alerts *AlertScheduler) (string, alertListOutput, error) {
+	var out alertListOutput
+	if alerts == nil {
+		return "", out, errAlertsDisabled
+	}
+	rules, firing, recent := alerts.list()
+	out.IntervalSeconds = alerts.interval.Seconds()
+	out.Recent = recent
+	for _, r := range rules {
+		out.Rules = append(out.Rules, alertRuleStatus{alertRule: r, Firing: firing[r.ID]})
+	}
+	if len(out.Rules) == 0 && len(out.Recent) == 0 {
+		return "No alert rules defined.", out, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%-10s  %-30s  %-30s  %s\n", "ID", "SCOPE", "RULE", "FIRING"))
+	for _, r := range out.Rules {
+		b.WriteString(fmt.Sprintf("%-10s  %-30s  %-30s  %s\n", r.ID, r.scope(), r.describe(), strings.Join(r.Firing, ", ")))
+	}
+	if len(out.Recent) > 0 {
+		b.WriteString("\nRecent alerts:\n")
+		for _, e := range out.Recent {
+			b.WriteString(fmt.Sprintf("  %s  %s\n", e.Time.Format(time.RFC3339), e.Message))
+		}
+	}
+	return b.String(), out, nil
+}
+
+func handleAlertRemove(alerts *AlertScheduler, args alertRemoveArgs) (string, alertRemoveOutput, error) {
+	out := alertRemoveOutput{ID: args.ID}
+	if alerts == nil {
+		return "", out, errAlertsDisabled
+	}
+	if !alerts.remove(args.ID) {
+		return "", out, fmt.Errorf("no alert rule %q", args.ID)
+	}
+	return fmt.Sprintf("Removed alert rule %s.", args.ID), out, nil
+}
+
+func registerAlertRules(server *mcp.Server, alerts *AlertScheduler) {
+	if alerts != nil {
+		server.AddReceivingMiddleware(alerts.trackSessions)
+	}
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "alert_add",
+		Description: "Add an alert rule for a container or Compose project: cpu_percent or mem_percent above a threshold (optionally for a number of seconds), restart count increased, or health became unhealthy. When a rule trips, the server sends a warning logging notification (logger \"alerts\") to connected clients, and an info notification when it recovers.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertAddArgs) (*mcp.CallToolResult, alertAddOutput, error) {
+		result, out, err := handleAlertAdd(alerts, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "alert_list",
+		Description: "List alert rules, the containers each is currently firing for, and the most recent alerts.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertListArgs) (*mcp.CallToolResult, alertListOutput, error) {
+		result, out, err := handleAlertList(alerts)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "alert_remove",
+		Description: "Remove an alert rule by ID.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args alertRemoveArgs) (*mcp.CallToolResult, alertRemoveOutput, error) {
+		result, out, err := handleAlertRemove(alerts, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
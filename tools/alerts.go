@@ -0,0 +1,478 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// alertLogger is the logger name of alert notifications.
+const alertLogger = "alerts"
+
+// Alert conditions.
+const (
+	conditionCPUPercent   = "cpu_percent"
+	conditionMemPercent   = "mem_percent"
+	conditionRestartCount = "restart_count"
+	conditionUnhealthy    = "unhealthy"
+)
+
+// alertRule is one user-defined rule. Threshold rules trip when the metric
+// stays above Above for For; the others trip on a state change.
+type alertRule struct {
+	ID        string    `json:"id"`
+	Container string    `json:"container,omitempty"`
+	Project   string    `json:"project,omitempty"`
+	Service   string    `json:"service,omitempty"`
+	Condition string    `json:"condition" jsonschema:"cpu_percent, mem_percent, restart_count or unhealthy"`
+	Above     float64   `json:"above,omitempty" jsonschema:"threshold for cpu_percent and mem_percent"`
+	For       int       `json:"for_seconds,omitempty" jsonschema:"how long the threshold must be exceeded before the rule trips"`
+	Created   time.Time `json:"created"`
+
+	// seq orders rules by creation.
+	seq int
+}
+
+func (r alertRule) scope() string {
+	return targetSelector{Container: r.Container, Project: r.Project, Service: r.Service}.String()
+}
+
+func (r alertRule) describe() string {
+	switch r.Condition {
+	case conditionCPUPercent, conditionMemPercent:
+		d := fmt.Sprintf("%s > %g", r.Condition, r.Above)
+		if r.For > 0 {
+			d += fmt.Sprintf(" for %ds", r.For)
+		}
+		return d
+	case conditionRestartCount:
+		return "restart count increased"
+	default:
+		return "health became unhealthy"
+	}
+}
+
+// matches reports whether the rule applies to container c. A container
+// given by ID must be at least as long as hasIDPrefix requires, so a short
+// name such as db does not match every ID that starts with it.
+func (r alertRule) matches(c containerInfo) bool {
+	if r.Container != "" {
+		return c.Names == r.Container || hasIDPrefix(c.ID, r.Container)
+	}
+	if c.composeProject() != r.Project {
+		return false
+	}
+	return r.Service == "" || c.Labels["com.docker.compose.service"] == r.Service
+}
+
+// alertEvent is sent to clients when a rule trips or recovers.
+type alertEvent struct {
+	Rule      string    `json:"rule"`
+	Container string    `json:"container"`
+	Condition string    `json:"condition"`
+	Resolved  bool      `json:"resolved,omitempty" jsonschema:"the condition cleared after the rule tripped"`
+	Value     float64   `json:"value"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+}
+
+// ruleState is what the scheduler remembers about one rule and container
+// between ticks.
+type ruleState struct {
+	breachSince  time.Time
+	firing       bool
+	restartCount int
+	seenRestarts bool
+	health       string
+}
+
+// AlertScheduler evaluates every alert rule from a single polling loop and
+// pushes alerts to all connected sessions as MCP logging notifications.
+// Polling only happens while rules exist, and each tick costs at most one
+// docker ps, one docker stats and one docker inspect regardless of how many
+// rules there are.
+type AlertScheduler struct {
+	exec     docker.Executor
+	clock    clock
+	interval time.Duration
+
+	mu       sync.Mutex
+	nextID   int
+	rules    map[string]alertRule
+	states   map[string]*ruleState
+	recent   []alertEvent
+	sessions map[*mcp.ServerSession]bool
+}
+
+// maxRecentAlerts bounds the alerts remembered for alert_list.
+const maxRecentAlerts = 50
+
+// NewAlertScheduler returns a scheduler polling every interval; call Run to
+// start it.
+func NewAlertScheduler(exec docker.Executor, interval time.Duration) *AlertScheduler {
+	return newAlertScheduler(exec, realClock{}, interval)
+}
+
+func newAlertScheduler(exec docker.Executor, clk clock, interval time.Duration) *AlertScheduler {
+	return &AlertScheduler{
+		exec:     exec,
+		clock:    clk,
+		interval: interval,
+		rules:    make(map[string]alertRule),
+		states:   make(map[string]*ruleState),
+		sessions: make(map[*mcp.ServerSession]bool),
+	}
+}
+
+// Run evaluates the rules every interval until ctx is done. Errors are
+// logged and do not stop the scheduler.
+func (a *AlertScheduler) Run(ctx context.Context) {
+	for {
+		events, err := a.evaluate(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("alerts: %v", err)
+		}
+		a.broadcast(ctx, events)
+		if a.clock.Sleep(ctx, a.interval) != nil {
+			return
+		}
+	}
+}
+
+// trackSessions is receiving middleware that remembers every session
+// talking to the server, so that alerts reach all of them. Sessions are
+// forgotten once they end.
+func (a *AlertScheduler) trackSessions(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if ss, ok := req.GetSession().(*mcp.ServerSession); ok && ss != nil {
+			a.mu.Lock()
+			known := a.sessions[ss]
+			a.sessions[ss] = true
+			a.mu.Unlock()
+			if !known {
+				go func() {
+					ss.Wait()
+					a.mu.Lock()
+					delete(a.sessions, ss)
+					a.mu.Unlock()
+				}()
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// broadcast sends events to every tracked session. Sessions only receive
+// them once the client has enabled logging with logging/setLevel.
+func (a *AlertScheduler) broadcast(ctx context.Context, events []alertEvent) {
+	if len(events) == 0 {
+		return
+	}
+	a.mu.Lock()
+	sessions := make([]*mcp.ServerSession, 0, len(a.sessions))
+	for ss := range a.sessions {
+		sessions = append(sessions, ss)
+	}
+	a.mu.Unlock()
+
+	for _, e := range events {
+		level := mcp.LoggingLevel("warning")
+		if e.Resolved {
+			level = "info"
+		}
+		for _, ss := range sessions {
+			ss.Log(ctx, &mcp.LoggingMessageParams{Level: level, Logger: alertLogger, Data: e})
+		}
+	}
+}
+
+func (a *AlertScheduler) add(rule alertRule) alertRule {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nextID++
+	rule.seq = a.nextID
+	rule.ID = fmt.Sprintf("alert-%d", a.nextID)
+	rule.Created = a.clock.Now()
+	a.rules[rule.ID] = rule
+	return rule
+}
+
+func (a *AlertScheduler) remove(id string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.rules[id]; !ok {
+		return false
+	}
+	delete(a.rules, id)
+	for key := range a.states {
+		if strings.HasPrefix(key, id+"/") {
+			delete(a.states, key)
+		}
+	}
+	return true
+}
+
+// list returns the rules ordered by creation, the containers each rule is
+// currently firing for, and the most recent alerts.
+func (a *AlertScheduler) list() ([]alertRule, map[string][]string, []alertEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rules := make([]alertRule, 0, len(a.rules))
+	for _, r := range a.rules {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].seq < rules[j].seq })
+
+	firing := make(map[string][]string)
+	for key, st := range a.states {
+		if st.firing {
+			id, container, _ := strings.Cut(key, "/")
+			firing[id] = append(firing[id], container)
+		}
+	}
+	for _, containers := range firing {
+		sort.Strings(containers)
+	}
+	return rules, firing, append([]alertEvent(nil), a.recent...)
+}
+
+// alertTarget is the polled data for one container.
+type alertTarget struct {
+	info         containerInfo
+	stats        *docker.Stats
+	state        *containerState
+	restartCount int
+	inspected    bool
+}
+
+// alertInspect is the per-container output of the batched docker inspect.
+type alertInspect struct {
+	ID           string         `json:"id"`
+	RestartCount int            `json:"restart_count"`
+	State        containerState `json:"state"`
+}
+
+const alertInspectFormat = `{"id":{{json .Id}},"restart_count":{{.RestartCount}},"state":{{json .State}}}`
+
+// evaluate polls the containers the rules refer to and returns the alerts
+// that tripped or resolved during this tick.
+func (a *AlertScheduler) evaluate(ctx context.Context) ([]alertEvent, error) {
+	a.mu.Lock()
+	rules := make([]alertRule, 0, len(a.rules))
+	for _, r := range a.rules {
+		rules = append(rules, r)
+	}
+	a.mu.Unlock()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].seq < rules[j].seq })
+
+	containers, err := psContainers(ctx, a.exec, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	// Work out which containers each rule covers and what must be polled.
+	targets := make(map[string]*alertTarget)
+	matched := make(map[string][]*alertTarget)
+	var needStats bool
+	var inspectIDs []string
+	for _, r := range rules {
+		for _, c := range containers {
+			if !r.matches(c) {
+				continue
+			}
+			t, ok := targets[c.ID]
+			if !ok {
+				t = &alertTarget{info: c}
+				targets[c.ID] = t
+			}
+			matched[r.ID] = append(matched[r.ID], t)
+			switch r.Condition {
+			case conditionCPUPercent, conditionMemPercent:
+				needStats = true
+			default:
+				if t.state == nil {
+					t.state = &containerState{}
+					inspectIDs = append(inspectIDs, c.ID)
+				}
+			}
+		}
+	}
+
+	if needStats {
+		stats, _, err := readStats(ctx, a.exec, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stats: %w", err)
+		}
+		for i := range stats {
+			for _, t := range targets {
+				if stats[i].Name == t.info.Names || (stats[i].ID != "" && (strings.HasPrefix(t.info.ID, stats[i].ID) || strings.HasPrefix(stats[i].ID, t.info.ID))) {
+					t.stats = &stats[i]
+				}
+			}
+		}
+	}
+	if len(inspectIDs) > 0 {
+		// A container removed since ps ran fails the whole command, but
+		// the others are still printed; the missing ones are dropped below.
+		out, err := a.exec.Exec(ctx, append([]string{"inspect", "--format", alertInspectFormat}, inspectIDs...)...)
+		if err != nil && !isNoSuchObject(err) {
+			return nil, fmt.Errorf("failed to inspect containers: %w", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			var ins alertInspect
+			if err := json.Unmarshal([]byte(line), &ins); err != nil {
+				continue
+			}
+			for _, t := range targets {
+				if strings.HasPrefix(ins.ID, t.info.ID) {
+					*t.state = ins.State
+					t.restartCount = ins.RestartCount
+					t.inspected = true
+				}
+			}
+		}
+		for _, r := range rules {
+			if r.Condition == conditionRestartCount || r.Condition == conditionUnhealthy {
+				matched[r.ID] = slices.DeleteFunc(matched[r.ID], func(t *alertTarget) bool { return !t.inspected })
+			}
+		}
+	}
+
+	now := a.clock.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var events []alertEvent
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		if _, ok := a.rules[r.ID]; !ok {
+			// Removed while polling.
+			continue
+		}
+		for _, t := range matched[r.ID] {
+			key := r.ID + "/" + t.info.Names
+			seen[key] = true
+			st, ok := a.states[key]
+			if !ok {
+				st = &ruleState{}
+				a.states[key] = st
+			}
+			if e, ok := evaluateRule(r, t, st, now); ok {
+				events = append(events, e)
+			}
+		}
+	}
+	// Forget containers that went away or no longer match, resolving the
+	// rules that were firing for them.
+	for _, key := range slices.Sorted(maps.Keys(a.states)) {
+		if seen[key] {
+			continue
+		}
+		id, container, _ := strings.Cut(key, "/")
+		if r, ok := a.rules[id]; ok && a.states[key].firing {
+			events = append(events, alertEvent{
+				Rule:      id,
+				Container: container,
+				Condition: r.Condition,
+				Resolved:  true,
+				Time:      now,
+				Message:   fmt.Sprintf("%s: container is gone, no longer checked (rule %s: %s)", container, id, r.describe()),
+			})
+		}
+		delete(a.states, key)
+	}
+
+	a.recent = append(a.recent, events...)
+	if len(a.recent) > maxRecentAlerts {
+		a.recent = a.recent[len(a.recent)-maxRecentAlerts:]
+	}
+	return events, nil
+}
+
+// evaluateRule advances the state of one rule for one container and returns
+// an event when it trips or resolves.
+func evaluateRule(r alertRule, t *alertTarget, st *ruleState, now time.Time) (alertEvent, bool) {
+	e := alertEvent{Rule: r.ID, Container: t.info.Names, Condition: r.Condition, Time: now}
+
+	switch r.Condition {
+	case conditionCPUPercent, conditionMemPercent:
+		if t.stats == nil {
+			// Not running; a breach can't continue across a stop.
+			st.breachSince = time.Time{}
+			if st.firing {
+				st.firing = false
+				e.Resolved = true
+				e.Message = fmt.Sprintf("%s: stopped, %s no longer measured (rule %s: %s)", t.info.Names, r.Condition, r.ID, r.describe())
+				return e, true
+			}
+			return e, false
+		}
+		e.Value = t.stats.CPUPercent
+		if r.Condition == conditionMemPercent {
+			e.Value = t.stats.MemPercent
+		}
+		if e.Value <= r.Above {
+			st.breachSince = time.Time{}
+			if st.firing {
+				st.firing = false
+				e.Resolved = true
+				e.Message = fmt.Sprintf("%s: %s back to %.1f%% (rule %s: %s)", t.info.Names, r.Condition, e.Value, r.ID, r.describe())
+				return e, true
+			}
+			return e, false
+		}
+		if st.breachSince.IsZero() {
+			st.breachSince = now
+		}
+		held := now.Sub(st.breachSince)
+		if st.firing || held < time.Duration(r.For)*time.Second {
+			return e, false
+		}
+		st.firing = true
+		e.Message = fmt.Sprintf("%s: %s at %.1f%% for %s (rule %s: %s)", t.info.Names, r.Condition, e.Value, held.Round(time.Second), r.ID, r.describe())
+		return e, true
+
+	case conditionRestartCount:
+		e.Value = float64(t.restartCount)
+		prev, seen := st.restartCount, st.seenRestarts
+		st.restartCount, st.seenRestarts = t.restartCount, true
+		if !seen || t.restartCount <= prev {
+			return e, false
+		}
+		e.Message = fmt.Sprintf("%s: restarted %d time(s), restart count now %d, state %s (rule %s)", t.info.Names, t.restartCount-prev, t.restartCount, t.state.describe(), r.ID)
+		return e, true
+
+	default:
+		health := ""
+		if t.state.Health != nil {
+			health = t.state.Health.Status
+		}
+		prev := st.health
+		st.health = health
+		switch {
+		case health == "unhealthy" && prev != "unhealthy":
+			st.firing = true
+			e.Value = float64(t.state.Health.FailingStreak)
+			e.Message = fmt.Sprintf("%s: health became unhealthy after %d failed checks (rule %s)", t.info.Names, t.state.Health.FailingStreak, r.ID)
+			return e, true
+		case prev == "unhealthy" && health != "unhealthy" && st.firing:
+			st.firing = false
+			e.Resolved = true
+			e.Message = fmt.Sprintf("%s: health is %s again (rule %s)", t.info.Names, t.state.describe(), r.ID)
+			return e, true
+		}
+		return e, false
+	}
+}
@@ -0,0 +1,270 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	alertPsCmd      = "ps -a --format {{json .}}"
+	alertStatsCmd   = "stats --no-stream --format {{json .}}"
+	alertInspectCmd = "inspect --format " + alertInspectFormat
+)
+
+const alertPsOutput = `{"ID":"aaa111","Names":"shop-web-1","State":"running","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}
+{"ID":"bbb222","Names":"shop-db-1","State":"running","Labels":"com.docker.compose.project=shop,com.docker.compose.service=db"}
+{"ID":"ccc333","Names":"other","State":"running"}`
+
+func alertStats(webMem, dbMem string) string {
+	return `{"Name":"shop-web-1","ID":"aaa111","CPUPerc":"1.00%","MemUsage":"10MiB / 1GiB","MemPerc":"` + webMem + `","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"1"}
+{"Name":"shop-db-1","ID":"bbb222","CPUPerc":"1.00%","MemUsage":"10MiB / 1GiB","MemPerc":"` + dbMem + `","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"1"}`
+}
+
+// tick evaluates the rules once and advances the clock by the interval.
+func tick(t *testing.T, a *AlertScheduler, clk *fakeClock) []alertEvent {
+	t.Helper()
+	events, err := a.evaluate(context.Background())
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	clk.Advance(a.interval)
+	return events
+}
+
+func TestAlertScheduler_ThresholdForDuration(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(alertPsCmd, alertPsOutput, nil)
+	mock.OnSequence(alertStatsCmd,
+		alertStats("95.00%", "10.00%"),
+		alertStats("96.00%", "10.00%"),
+		alertStats("97.00%", "10.00%"),
+		alertStats("98.00%", "10.00%"),
+		alertStats("50.00%", "10.00%"),
+	)
+
+	clk := newFakeClock()
+	a := newAlertScheduler(mock, clk, 10*time.Second)
+	if _, _, err := handleAlertAdd(a, alertAddArgs{Project: "shop", Condition: "mem_percent", Above: 90, For: 20}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	// Breached at 0s and 10s: not yet held for 20s.
+	for i := 0; i < 2; i++ {
+		if events := tick(t, a, clk); len(events) != 0 {
+			t.Fatalf("tick %d: expected no alert yet, got %+v", i, events)
+		}
+	}
+	events := tick(t, a, clk)
+	if len(events) != 1 || events[0].Container != "shop-web-1" || events[0].Value != 97 || events[0].Resolved {
+		t.Fatalf("expected one alert for shop-web-1, got %+v", events)
+	}
+	if !strings.Contains(events[0].Message, "for 20s") {
+		t.Errorf("expected duration in message, got %q", events[0].Message)
+	}
+	// Still breached: the rule does not fire again.
+	if events := tick(t, a, clk); len(events) != 0 {
+		t.Errorf("expected no repeat alert, got %+v", events)
+	}
+	_, firing, _ := a.list()
+	if got := firing["alert-1"]; len(got) != 1 || got[0] != "shop-web-1" {
+		t.Errorf("expected rule to be firing for shop-web-1, got %v", firing)
+	}
+	events = tick(t, a, clk)
+	if len(events) != 1 || !events[0].Resolved {
+		t.Errorf("expected a resolved event, got %+v", events)
+	}
+}
+
+func TestAlertScheduler_RestartAndHealth(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(alertPsCmd, alertPsOutput, nil)
+	mock.OnSequence(alertInspectCmd+" bbb222",
+		`{"id":"bbb222full","restart_count":1,"state":{"Status":"running","Health":{"Status":"healthy"}}}`,
+		`{"id":"bbb222full","restart_count":3,"state":{"Status":"running","Health":{"Status":"unhealthy","FailingStreak":4}}}`,
+		`{"id":"bbb222full","restart_count":3,"state":{"Status":"running","Health":{"Status":"healthy"}}}`,
+	)
+
+	clk := newFakeClock()
+	a := newAlertScheduler(mock, clk, 10*time.Second)
+	for _, cond := range []string{"restart_count", "unhealthy"} {
+		if _, _, err := handleAlertAdd(a, alertAddArgs{Project: "shop", Service: "db", Condition: cond}); err != nil {
+			t.Fatalf("add %s: %v", cond, err)
+		}
+	}
+
+	if events := tick(t, a, clk); len(events) != 0 {
+		t.Fatalf("expected the first tick to set baselines, got %+v", events)
+	}
+	events := tick(t, a, clk)
+	if len(events) != 2 {
+		t.Fatalf("expected restart and health alerts, got %+v", events)
+	}
+	if events[0].Condition != "restart_count" || events[0].Value != 3 || !strings.Contains(events[0].Message, "restarted 2 time(s)") {
+		t.Errorf("unexpected restart alert %+v", events[0])
+	}
+	if events[1].Condition != "unhealthy" || !strings.Contains(events[1].Message, "4 failed checks") {
+		t.Errorf("unexpected health alert %+v", events[1])
+	}
+	events = tick(t, a, clk)
+	if len(events) != 1 || events[0].Condition != "unhealthy" || !events[0].Resolved {
+		t.Errorf("expected health to resolve, got %+v", events)
+	}
+
+	// Only one docker inspect per tick covers both rules.
+	inspects := 0
+	for _, call := range mock.Calls() {
+		if call[0] == "inspect" {
+			inspects++
+		}
+	}
+	if inspects != 3 {
+		t.Errorf("expected 3 batched inspects, got %d", inspects)
+	}
+}
+
+func TestAlertScheduler_ContainersGoAway(t *testing.T) {
+	mock := docker.NewMock()
+	mock.OnSequence(alertPsCmd, alertPsOutput, alertPsOutput, `{"ID":"ccc333","Names":"other","State":"running"}`)
+	// inspect fails for shop-web-1, as when it is removed between ps and
+	// inspect, but still prints shop-db-1.
+	mock.On(alertInspectCmd+" aaa111 bbb222", `{"id":"bbb222full","restart_count":0,"state":{"Status":"running","Health":{"Status":"unhealthy","FailingStreak":3}}}`,
+		fmt.Errorf("docker inspect: exit status 1: Error: No such object: aaa111"))
+	mock.OnSequence(alertStatsCmd, alertStats("95.00%", "10.00%"), `{"Name":"shop-db-1","ID":"bbb222","CPUPerc":"1.00%","MemUsage":"10MiB / 1GiB","MemPerc":"10.00%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"1"}`)
+
+	clk := newFakeClock()
+	a := newAlertScheduler(mock, clk, 10*time.Second)
+	for _, args := range []alertAddArgs{
+		{Project: "shop", Condition: "unhealthy"},
+		{Container: "shop-web-1", Condition: "mem_percent", Above: 90},
+	} {
+		if _, _, err := handleAlertAdd(a, args); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	events := tick(t, a, clk)
+	if len(events) != 2 || events[0].Container != "shop-db-1" || events[1].Container != "shop-web-1" {
+		t.Fatalf("expected the remaining containers to be evaluated despite the failed inspect, got %+v", events)
+	}
+	// shop-web-1 stops: its memory alert resolves.
+	events = tick(t, a, clk)
+	if len(events) != 1 || !events[0].Resolved || events[0].Container != "shop-web-1" || !strings.Contains(events[0].Message, "stopped") {
+		t.Fatalf("expected the memory alert to resolve when the container stopped, got %+v", events)
+	}
+	// shop-db-1 is removed: its health alert resolves.
+	events = tick(t, a, clk)
+	if len(events) != 1 || !events[0].Resolved || events[0].Container != "shop-db-1" || !strings.Contains(events[0].Message, "gone") {
+		t.Fatalf("expected the health alert to resolve when the container went away, got %+v", events)
+	}
+	if _, firing, _ := a.list(); len(firing) != 0 {
+		t.Errorf("expected nothing firing, got %v", firing)
+	}
+}
+
+func TestAlertRule_Matches(t *testing.T) {
+	db := containerInfo{ID: "0f1e2d3c4b5a", Names: "db"}
+	other := containerInfo{ID: "db41c0ffee99", Names: "cache"}
+	r := alertRule{Container: "db"}
+	if !r.matches(db) || r.matches(other) {
+		t.Errorf("expected rule for db to match only the container named db")
+	}
+	if r := (alertRule{Container: "db41c0"}); !r.matches(other) || r.matches(db) {
+		t.Errorf("expected an ID prefix to match only its container")
+	}
+}
+
+func TestAlertScheduler_IdleWithoutRules(t *testing.T) {
+	mock := docker.NewMock()
+	clk := newFakeClock()
+	a := newAlertScheduler(mock, clk, time.Second)
+	tick(t, a, clk)
+
+	if _, _, err := handleAlertAdd(a, alertAddArgs{Container: "other", Condition: "unhealthy"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, _, err := handleAlertRemove(a, alertRemoveArgs{ID: "alert-1"}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	tick(t, a, clk)
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("expected no docker calls without rules, got %v", calls)
+	}
+	if _, _, err := handleAlertRemove(a, alertRemoveArgs{ID: "alert-1"}); err == nil {
+		t.Error("expected error removing an unknown rule")
+	}
+}
+
+func TestHandleAlertAdd_Validation(t *testing.T) {
+	a := newAlertScheduler(docker.NewMock(), newFakeClock(), time.Second)
+	tests := []struct {
+		name string
+		args alertAddArgs
+		want string
+	}{
+		{"no target", alertAddArgs{Condition: "unhealthy"}, "container or project is required"},
+		{"both targets", alertAddArgs{Container: "c", Project: "p", Condition: "unhealthy"}, "not both"},
+		{"service without project", alertAddArgs{Container: "c", Service: "s", Condition: "unhealthy"}, "service requires project"},
+		{"unknown condition", alertAddArgs{Container: "c", Condition: "disk"}, "unknown condition"},
+		{"missing threshold", alertAddArgs{Container: "c", Condition: "cpu_percent"}, "positive above"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := handleAlertAdd(a, tt.args); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, _, err := handleAlertList(nil); err != errAlertsDisabled {
+		t.Errorf("expected disabled error, got %v", err)
+	}
+}
+
+func TestAlertScheduler_NotifiesSessions(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(alertPsCmd, alertPsOutput, nil)
+	mock.On(alertInspectCmd+" ccc333", `{"id":"ccc333","restart_count":0,"state":{"Status":"running","Health":{"Status":"unhealthy","FailingStreak":3}}}`, nil)
+
+	clk := newFakeClock()
+	a := newAlertScheduler(mock, clk, time.Second)
+	server := newTestServer()
+	RegisterAll(server, mock, Options{Alerts: a})
+
+	received := make(chan *mcp.LoggingMessageParams, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			received <- req.Params
+		},
+	})
+	session := connectClient(t, server, client)
+	ctx := context.Background()
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("set logging level: %v", err)
+	}
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "alert_add",
+		Arguments: map[string]any{"container": "other", "condition": "unhealthy"},
+	}); err != nil {
+		t.Fatalf("alert_add: %v", err)
+	}
+
+	a.broadcast(ctx, tick(t, a, clk))
+	select {
+	case msg := <-received:
+		if msg.Level != "warning" || msg.Logger != alertLogger {
+			t.Errorf("unexpected notification %+v", msg)
+		}
+		data, _ := msg.Data.(map[string]any)
+		if data["container"] != "other" || data["rule"] != "alert-1" {
+			t.Errorf("unexpected alert data %v", msg.Data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no alert notification received")
+	}
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"context"
+	"time"
+)
+
+// clock abstracts time so that sampling and polling loops can be tested
+// without waiting.
+type clock interface {
+	Now() time.Time
+	// Sleep pauses for d or until ctx is done, returning ctx.Err() in the
+	// latter case.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
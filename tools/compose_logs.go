@@ -1,85 +1,142 @@
 package tools
 
 import (
+	"container/heap"
 	"context"
-	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/otsukatsuka/orbstack-mcp/docker"
 )
 
 type composeLogsArgs struct {
-	Project    string `json:"project" jsonschema:"Compose project name"`
-	Tail       int    `json:"tail,omitempty" jsonschema:"number of lines to show from the end of the logs (default: 100)"`
-	Since      string `json:"since,omitempty" jsonschema:"show logs since timestamp (e.g. 2021-01-01T00:00:00Z) or relative (e.g. 42m for 42 minutes)"`
-	Timestamps bool   `json:"timestamps,omitempty" jsonschema:"show timestamps in log output"`
+	Project    string   `json:"project" jsonschema:"Compose project name"`
+	Services   []string `json:"services,omitempty" jsonschema:"only include these services (default: all)"`
+	Tail       int      `json:"tail,omitempty" jsonschema:"number of lines to show from the end of each container's logs (default: 100)"`
+	Since      string   `json:"since,omitempty" jsonschema:"show logs since timestamp (e.g. 2021-01-01T00:00:00Z) or relative (e.g. 42m for 42 minutes)"`
+	Until      string   `json:"until,omitempty" jsonschema:"show logs until timestamp (e.g. 2021-01-01T00:00:00Z) or relative (e.g. 42m for 42 minutes)"`
+	Timestamps bool     `json:"timestamps,omitempty" jsonschema:"show timestamps in log output"`
 }
 
-type composeLogContainer struct {
-	ID     string            `json:"ID"`
-	Names  string            `json:"Names"`
-	Labels map[string]string `json:"Labels"`
+type composeLogsOutput struct {
+	Project string           `json:"project"`
+	Lines   []composeLogLine `json:"lines" jsonschema:"log lines of all services in chronological order"`
+	Errors  []string         `json:"errors,omitempty" jsonschema:"services whose logs could not be fetched"`
 }
 
-// parseComposeLogContainers parses docker ps JSON output into composeLogContainer structs.
-// Docker ps --format '{{json .}}' outputs one JSON object per line, but the Labels
-// field is a comma-separated key=value string, not a JSON map.
-func parseComposeLogContainers(output string) ([]composeLogContainer, error) {
-	var containers []composeLogContainer
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+type composeLogLine struct {
+	Service   string `json:"service"`
+	Timestamp string `json:"timestamp,omitempty" jsonschema:"RFC 3339 timestamp docker recorded for the line"`
+	Text      string `json:"text"`
+}
+
+// logLine is a single log line of a Compose service, split from the
+// timestamp docker logs --timestamps prefixes it with.
+type logLine struct {
+	service string
+	time    time.Time
+	stamp   string
+	text    string
+}
+
+// parseTimestampedLogs splits docker logs --timestamps output into lines.
+// A line without a parseable timestamp inherits the previous line's time so
+// that it stays in place during the merge.
+func parseTimestampedLogs(service, output string) []logLine {
+	var lines []logLine
+	var last time.Time
+	for _, raw := range strings.Split(output, "\n") {
+		if raw == "" {
 			continue
 		}
-		// Docker outputs Labels as a comma-separated string like "key1=val1,key2=val2".
-		// We first unmarshal into a raw struct to handle that.
-		var raw struct {
-			ID     string `json:"ID"`
-			Names  string `json:"Names"`
-			Labels string `json:"Labels"`
-		}
-		if err := json.Unmarshal([]byte(line), &raw); err != nil {
-			return nil, fmt.Errorf("failed to parse container JSON: %w", err)
-		}
-		labels := make(map[string]string)
-		if raw.Labels != "" {
-			for _, pair := range strings.Split(raw.Labels, ",") {
-				kv := strings.SplitN(pair, "=", 2)
-				if len(kv) == 2 {
-					labels[kv[0]] = kv[1]
-				}
+		line := logLine{service: service, time: last, text: raw}
+		if stamp, text, ok := strings.Cut(raw, " "); ok {
+			if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+				line.time, line.stamp, line.text = t, stamp, text
+				last = t
 			}
 		}
-		containers = append(containers, composeLogContainer{
-			ID:     raw.ID,
-			Names:  raw.Names,
-			Labels: labels,
-		})
+		lines = append(lines, line)
 	}
-	return containers, nil
+	return lines
 }
 
-func handleComposeLogs(ctx context.Context, exec docker.Executor, args composeLogsArgs) (string, error) {
-	// Find containers belonging to the Compose project.
-	psOutput, err := exec.Exec(ctx, "ps", "-a", "--format", "{{json .}}", "--filter", "label=com.docker.compose.project="+args.Project)
-	if err != nil {
-		return "", fmt.Errorf("failed to list containers: %w", err)
+// logCursor points at the next unmerged line of one stream.
+type logCursor struct {
+	stream int
+	pos    int
+}
+
+// logMergeHeap orders cursors by the time of their next line. Ties are broken
+// by stream index, which keeps the merge stable: lines with identical
+// timestamps come out in stream order, and each stream keeps its own order.
+type logMergeHeap struct {
+	streams [][]logLine
+	cursors []logCursor
+}
+
+func (h *logMergeHeap) Len() int { return len(h.cursors) }
+
+func (h *logMergeHeap) Less(i, j int) bool {
+	a, b := h.cursors[i], h.cursors[j]
+	ta, tb := h.streams[a.stream][a.pos].time, h.streams[b.stream][b.pos].time
+	if !ta.Equal(tb) {
+		return ta.Before(tb)
 	}
+	return a.stream < b.stream
+}
+
+func (h *logMergeHeap) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *logMergeHeap) Push(x any) { h.cursors = append(h.cursors, x.(logCursor)) }
 
-	if strings.TrimSpace(psOutput) == "" {
-		return "", fmt.Errorf("no containers found for Compose project %q", args.Project)
+func (h *logMergeHeap) Pop() any {
+	c := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return c
+}
+
+// mergeLogStreams k-way merges per-container log streams, each already in
+// chronological order, into a single chronological sequence.
+func mergeLogStreams(streams [][]logLine) []logLine {
+	h := &logMergeHeap{streams: streams}
+	total := 0
+	for i, s := range streams {
+		if len(s) > 0 {
+			h.cursors = append(h.cursors, logCursor{stream: i})
+		}
+		total += len(s)
+	}
+	heap.Init(h)
+
+	merged := make([]logLine, 0, total)
+	for h.Len() > 0 {
+		c := h.cursors[0]
+		merged = append(merged, streams[c.stream][c.pos])
+		if c.pos+1 < len(streams[c.stream]) {
+			h.cursors[0].pos++
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
 	}
+	return merged
+}
 
-	containers, err := parseComposeLogContainers(psOutput)
+func handleComposeLogs(ctx context.Context, exec docker.Executor, args composeLogsArgs) (string, composeLogsOutput, error) {
+	out := composeLogsOutput{Project: args.Project}
+
+	// Find containers belonging to the Compose project.
+	containers, err := psContainers(ctx, exec, true, "com.docker.compose.project="+args.Project)
 	if err != nil {
-		return "", err
+		return "", out, fmt.Errorf("failed to list containers: %w", err)
 	}
 
 	if len(containers) == 0 {
-		return "", fmt.Errorf("no containers found for Compose project %q", args.Project)
+		return "", out, fmt.Errorf("no containers found for Compose project %q", args.Project)
 	}
 
 	tail := args.Tail
@@ -87,54 +144,68 @@ func handleComposeLogs(ctx context.Context, exec docker.Executor, args composeLo
 		tail = 100
 	}
 
-	// Collect logs from each container.
-	var result strings.Builder
+	// Collect timestamped logs from each container so they can be merged
+	// chronologically.
+	var fetchErrors strings.Builder
+	var streams [][]logLine
+	matched := 0
 	for _, c := range containers {
 		serviceName := c.Labels["com.docker.compose.service"]
 		if serviceName == "" {
 			serviceName = c.Names
 		}
+		if len(args.Services) > 0 && !slices.Contains(args.Services, serviceName) {
+			continue
+		}
+		matched++
 
 		logArgs := []string{"logs", "--tail", fmt.Sprintf("%d", tail)}
 		if args.Since != "" {
 			logArgs = append(logArgs, "--since", args.Since)
 		}
-		if args.Timestamps {
-			logArgs = append(logArgs, "--timestamps")
+		if args.Until != "" {
+			logArgs = append(logArgs, "--until", args.Until)
 		}
-		logArgs = append(logArgs, c.ID)
+		logArgs = append(logArgs, "--timestamps", c.ID)
 
 		logOutput, err := exec.ExecCombined(ctx, logArgs...)
 		if err != nil {
-			result.WriteString(fmt.Sprintf("[%s] error fetching logs: %s\n", serviceName, err))
+			fetchErrors.WriteString(fmt.Sprintf("[%s] error fetching logs: %s\n", serviceName, err))
+			out.Errors = append(out.Errors, fmt.Sprintf("%s: %s", serviceName, err))
 			continue
 		}
+		streams = append(streams, parseTimestampedLogs(serviceName, logOutput))
+	}
 
-		for _, line := range strings.Split(logOutput, "\n") {
-			if line == "" {
-				continue
-			}
-			result.WriteString(fmt.Sprintf("[%s] %s\n", serviceName, line))
+	if matched == 0 {
+		return "", out, fmt.Errorf("no containers found for services %s in Compose project %q", strings.Join(args.Services, ", "), args.Project)
+	}
+
+	var result strings.Builder
+	result.WriteString(fetchErrors.String())
+	for _, line := range mergeLogStreams(streams) {
+		if args.Timestamps && line.stamp != "" {
+			result.WriteString(fmt.Sprintf("[%s] %s %s\n", line.service, line.stamp, line.text))
+		} else {
+			result.WriteString(fmt.Sprintf("[%s] %s\n", line.service, line.text))
 		}
+		out.Lines = append(out.Lines, composeLogLine{Service: line.service, Timestamp: line.stamp, Text: line.text})
 	}
 
-	return result.String(), nil
+	return result.String(), out, nil
 }
 
 func registerComposeLogs(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "compose_logs",
-		Description: "Get logs for all containers in a Docker Compose project, merged and prefixed with service names.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args composeLogsArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleComposeLogs(ctx, exec, args)
+		Description: "Get logs for all containers in a Docker Compose project, merged chronologically across services and prefixed with service names.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args composeLogsArgs) (*mcp.CallToolResult, composeLogsOutput, error) {
+		result, out, err := handleComposeLogs(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
@@ -18,10 +18,10 @@ func TestHandleComposeLogs_TwoServices(t *testing.T) {
 	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=myapp", psOutput, nil)
 
 	// Register logs output for each container.
-	mock.On("logs --tail 100 abc123", "web log line 1\nweb log line 2\n", nil)
-	mock.On("logs --tail 100 def456", "db log line 1\ndb log line 2\n", nil)
+	mock.On("logs --tail 100 --timestamps abc123", "2024-01-01T00:00:01Z web log line 1\n2024-01-01T00:00:03Z web log line 2\n", nil)
+	mock.On("logs --tail 100 --timestamps def456", "2024-01-01T00:00:02Z db log line 1\n2024-01-01T00:00:04Z db log line 2\n", nil)
 
-	result, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
+	result, _, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
 		Project: "myapp",
 	})
 
@@ -42,6 +42,12 @@ func TestHandleComposeLogs_TwoServices(t *testing.T) {
 	if !strings.Contains(result, "[db] db log line 2") {
 		t.Errorf("expected db log line 2, got: %s", result)
 	}
+
+	// Lines are interleaved chronologically, with timestamps hidden by default.
+	want := "[web] web log line 1\n[db] db log line 1\n[web] web log line 2\n[db] db log line 2\n"
+	if result != want {
+		t.Errorf("expected chronological merge:\n%s\ngot:\n%s", want, result)
+	}
 }
 
 func TestHandleComposeLogs_ProjectNotFound(t *testing.T) {
@@ -50,7 +56,7 @@ func TestHandleComposeLogs_ProjectNotFound(t *testing.T) {
 	// Return empty output for ps - no containers found.
 	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=nonexistent", "", nil)
 
-	_, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
+	_, _, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
 		Project: "nonexistent",
 	})
 
@@ -68,7 +74,7 @@ func TestHandleComposeLogs_DockerError(t *testing.T) {
 	// Simulate docker ps failure.
 	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=myapp", "", fmt.Errorf("docker daemon not running"))
 
-	_, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
+	_, _, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
 		Project: "myapp",
 	})
 
@@ -86,12 +92,13 @@ func TestHandleComposeLogs_WithOptions(t *testing.T) {
 	psOutput := `{"ID":"abc123","Names":"myapp-web-1","Labels":"com.docker.compose.project=myapp,com.docker.compose.service=web"}`
 	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=myapp", psOutput, nil)
 
-	mock.On("logs --tail 50 --since 1h --timestamps abc123", "2024-01-01T00:00:00Z log line\n", nil)
+	mock.On("logs --tail 50 --since 1h --until 10m --timestamps abc123", "2024-01-01T00:00:00Z log line\n", nil)
 
-	result, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
+	result, _, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
 		Project:    "myapp",
 		Tail:       50,
 		Since:      "1h",
+		Until:      "10m",
 		Timestamps: true,
 	})
 
@@ -103,3 +110,117 @@ func TestHandleComposeLogs_WithOptions(t *testing.T) {
 		t.Errorf("expected timestamped log line, got: %s", result)
 	}
 }
+
+func TestHandleComposeLogs_TypedLister(t *testing.T) {
+	mock := &typedMock{
+		Mock: docker.NewMock(),
+		containers: []docker.Container{
+			{ID: "abc123", Name: "myapp-web-1", State: "running",
+				Labels: map[string]string{"com.docker.compose.project": "myapp", "com.docker.compose.service": "web"}},
+			{ID: "zzz999", Name: "other-web-1", State: "running",
+				Labels: map[string]string{"com.docker.compose.project": "other", "com.docker.compose.service": "web"}},
+		},
+	}
+	mock.On("logs --tail 100 --timestamps abc123", "2024-01-01T00:00:00Z web log line\n", nil)
+
+	result, _, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{Project: "myapp"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(result, "[web] web log line") {
+		t.Errorf("expected prefixed web log line, got: %s", result)
+	}
+	if calls := mock.Calls(); len(calls) != 1 || calls[0][0] != "logs" {
+		t.Errorf("expected only the logs call to go through the CLI, got %v", calls)
+	}
+}
+
+func TestHandleComposeLogs_ServiceFilter(t *testing.T) {
+	mock := docker.NewMock()
+
+	psOutput := `{"ID":"abc123","Names":"myapp-web-1","Labels":"com.docker.compose.project=myapp,com.docker.compose.service=web"}
+{"ID":"def456","Names":"myapp-db-1","Labels":"com.docker.compose.project=myapp,com.docker.compose.service=db"}`
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=myapp", psOutput, nil)
+	mock.On("logs --tail 100 --timestamps def456", "2024-01-01T00:00:00Z db only\n", nil)
+
+	result, _, err := handleComposeLogs(context.Background(), mock, composeLogsArgs{
+		Project:  "myapp",
+		Services: []string{"db"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "[db] db only\n" {
+		t.Errorf("expected only db logs, got: %q", result)
+	}
+
+	_, _, err = handleComposeLogs(context.Background(), mock, composeLogsArgs{
+		Project:  "myapp",
+		Services: []string{"cache"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "cache") {
+		t.Errorf("expected error naming the unknown service, got: %v", err)
+	}
+}
+
+func TestMergeLogStreams(t *testing.T) {
+	tests := []struct {
+		name    string
+		streams map[string]string
+		order   []string
+		want    []string
+	}{
+		{
+			name: "interleaved requests",
+			streams: map[string]string{
+				"web": "2024-01-01T00:00:01.000000001Z GET /\n2024-01-01T00:00:01.000000300Z 200 OK\n",
+				"db":  "2024-01-01T00:00:01.000000100Z SELECT 1\n",
+			},
+			order: []string{"web", "db"},
+			want:  []string{"[web] GET /", "[db] SELECT 1", "[web] 200 OK"},
+		},
+		{
+			name: "identical timestamps keep stream order",
+			streams: map[string]string{
+				"a": "2024-01-01T00:00:00Z a1\n2024-01-01T00:00:00Z a2\n",
+				"b": "2024-01-01T00:00:00Z b1\n",
+			},
+			order: []string{"b", "a"},
+			want:  []string{"[b] b1", "[a] a1", "[a] a2"},
+		},
+		{
+			name: "untimestamped continuation stays with its line",
+			streams: map[string]string{
+				"api":    "2024-01-01T00:00:01Z panic: boom\n\tat main.go:10\n2024-01-01T00:00:03Z restarted\n",
+				"worker": "2024-01-01T00:00:02Z tick\n",
+			},
+			order: []string{"api", "worker"},
+			want:  []string{"[api] panic: boom", "[api] \tat main.go:10", "[worker] tick", "[api] restarted"},
+		},
+		{
+			name: "empty stream",
+			streams: map[string]string{
+				"web": "",
+				"db":  "2024-01-01T00:00:00Z ready\n",
+			},
+			order: []string{"web", "db"},
+			want:  []string{"[db] ready"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var streams [][]logLine
+			for _, service := range tt.order {
+				streams = append(streams, parseTimestampedLogs(service, tt.streams[service]))
+			}
+			var got []string
+			for _, line := range mergeLogStreams(streams) {
+				got = append(got, fmt.Sprintf("[%s] %s", line.service, line.text))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("expected\n%s\ngot\n%s", strings.Join(tt.want, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
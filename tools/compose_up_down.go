@@ -2,8 +2,11 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -11,122 +14,272 @@ import (
 )
 
 type composeUpArgs struct {
-	Project  string   `json:"project" jsonschema:"Compose project name"`
+	Project  string   `json:"project,omitempty" jsonschema:"Compose project name (optional when file is given)"`
+	File     string   `json:"file,omitempty" jsonschema:"compose file or project directory to use instead of discovering it from existing containers"`
+	Profiles []string `json:"profiles,omitempty" jsonschema:"Compose profiles to enable"`
+	EnvFiles []string `json:"env_files,omitempty" jsonschema:"env files to load, relative to the project directory unless absolute"`
 	Services []string `json:"services,omitempty" jsonschema:"specific services to start (default: all)"`
 }
 
 type composeDownArgs struct {
-	Project       string `json:"project" jsonschema:"Compose project name"`
-	RemoveVolumes bool   `json:"remove_volumes,omitempty" jsonschema:"remove named volumes declared in the volumes section (default: false)"`
+	Project       string   `json:"project,omitempty" jsonschema:"Compose project name (optional when file is given)"`
+	File          string   `json:"file,omitempty" jsonschema:"compose file or project directory to use instead of discovering it from existing containers"`
+	Profiles      []string `json:"profiles,omitempty" jsonschema:"Compose profiles to enable"`
+	EnvFiles      []string `json:"env_files,omitempty" jsonschema:"env files to load, relative to the project directory unless absolute"`
+	RemoveVolumes bool     `json:"remove_volumes,omitempty" jsonschema:"remove named volumes declared in the volumes section (default: false)"`
 }
 
-// composeProjectContainer holds the minimal fields we need from docker ps JSON output
-// when discovering a Compose project's working directory.
-type composeProjectContainer struct {
-	ID string `json:"ID"`
+type composeConfigArgs struct {
+	Project  string   `json:"project,omitempty" jsonschema:"Compose project name (optional when file is given)"`
+	File     string   `json:"file,omitempty" jsonschema:"compose file or project directory to use instead of discovering it from existing containers"`
+	Profiles []string `json:"profiles,omitempty" jsonschema:"Compose profiles to enable"`
+	EnvFiles []string `json:"env_files,omitempty" jsonschema:"env files to load, relative to the project directory unless absolute"`
+	Services []string `json:"services,omitempty" jsonschema:"only render these services (default: all)"`
 }
 
-// discoverWorkDir finds the working directory of a Compose project by
-// inspecting one of its containers.
-func discoverWorkDir(ctx context.Context, exec docker.Executor, project string) (string, error) {
+type composeOutput struct {
+	Project     string   `json:"project,omitempty"`
+	WorkDir     string   `json:"work_dir" jsonschema:"directory docker compose was run in"`
+	ConfigFiles []string `json:"config_files,omitempty" jsonschema:"compose files passed with -f"`
+	Source      string   `json:"source" jsonschema:"where the project location came from: file, containers or registry"`
+	Output      string   `json:"output" jsonschema:"combined output of docker compose"`
+}
+
+// errNoProjectContainers reports that a project has no containers left to
+// discover its location from.
+var errNoProjectContainers = errors.New("no containers found for project")
+
+// discoverProject finds the working directory and compose files of a
+// Compose project from the labels of one of its containers.
+func discoverProject(ctx context.Context, exec docker.Executor, project string) (ComposeProject, error) {
 	// List any container (including stopped) belonging to the project.
-	psOutput, err := exec.Exec(ctx, "ps", "-a", "--format", "{{json .}}", "--filter", "label=com.docker.compose.project="+project)
+	containers, err := psContainers(ctx, exec, true, "com.docker.compose.project="+project)
 	if err != nil {
-		return "", fmt.Errorf("failed to list containers for project %q: %w", project, err)
+		return ComposeProject{}, fmt.Errorf("failed to list containers for project %q: %w", project, err)
 	}
 
-	psOutput = strings.TrimSpace(psOutput)
-	if psOutput == "" {
-		return "", fmt.Errorf("no containers found for project %q: cannot determine working directory. Start the project manually first or specify the compose file path", project)
+	if len(containers) == 0 {
+		return ComposeProject{}, fmt.Errorf("%w %q", errNoProjectContainers, project)
 	}
 
-	// Parse the first container to get its ID.
-	firstLine := strings.SplitN(psOutput, "\n", 2)[0]
-	var c composeProjectContainer
-	if err := json.Unmarshal([]byte(firstLine), &c); err != nil {
-		return "", fmt.Errorf("failed to parse container JSON: %w", err)
+	c := containers[0]
+	_, exactLabels := exec.(docker.ContainerLister)
+	p := ComposeProject{WorkDir: c.Labels["com.docker.compose.project.working_dir"]}
+	if p.WorkDir == "" {
+		// The label string from docker ps cannot represent values containing
+		// commas, so fall back to inspect when the label did not survive parsing.
+		workDir, err := exec.Exec(ctx, "inspect", "--format", `{{index .Config.Labels "com.docker.compose.project.working_dir"}}`, c.ID)
+		if err != nil {
+			return ComposeProject{}, fmt.Errorf("failed to inspect container %s: %w", c.ID, err)
+		}
+		p.WorkDir = strings.TrimSpace(workDir)
+		if p.WorkDir == "" {
+			return ComposeProject{}, fmt.Errorf("container %s has no com.docker.compose.project.working_dir label", c.ID)
+		}
 	}
 
-	// Inspect to find the working directory label.
-	workDir, err := exec.Exec(ctx, "inspect", "--format", `{{index .Config.Labels "com.docker.compose.project.working_dir"}}`, c.ID)
-	if err != nil {
-		return "", fmt.Errorf("failed to inspect container %s: %w", c.ID, err)
+	// config_files is a comma-separated list, so docker ps truncates it
+	// whenever the project uses several files. Only trust it from a typed
+	// listing; otherwise ask inspect. Compose falls back to its default file
+	// names when this is unknown, so failures here are not fatal.
+	files := c.Labels["com.docker.compose.project.config_files"]
+	if !exactLabels {
+		out, err := exec.Exec(ctx, "inspect", "--format", `{{index .Config.Labels "com.docker.compose.project.config_files"}}`, c.ID)
+		if err != nil {
+			out = ""
+		}
+		files = strings.TrimSpace(out)
 	}
+	if files != "" {
+		p.ConfigFiles = strings.Split(files, ",")
+	}
+	return p, nil
+}
 
-	workDir = strings.TrimSpace(workDir)
-	if workDir == "" {
-		return "", fmt.Errorf("container %s has no com.docker.compose.project.working_dir label", c.ID)
+// composeTarget locates the Compose project to operate on. An explicit file
+// or directory wins; otherwise the location is discovered from the project's
+// containers and, if none are left, looked up in the registry. With remember
+// set, locations found either way are saved for later calls.
+func composeTarget(ctx context.Context, exec docker.Executor, projects *ProjectRegistry, project, file string, remember bool) (ComposeProject, string, error) {
+	save := func(p ComposeProject) {
+		if !remember || project == "" {
+			return
+		}
+		// The project is resolved either way; failing to persist it only
+		// costs a later lookup, so it must not fail the call.
+		if err := projects.Remember(project, p); err != nil {
+			log.Printf("project registry: %v", err)
+		}
 	}
 
-	return workDir, nil
+	if file != "" {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return ComposeProject{}, "", fmt.Errorf("invalid compose file path %q: %w", file, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return ComposeProject{}, "", fmt.Errorf("compose file %q: %w", file, err)
+		}
+		p := ComposeProject{WorkDir: abs}
+		if !info.IsDir() {
+			p = ComposeProject{WorkDir: filepath.Dir(abs), ConfigFiles: []string{abs}}
+		}
+		save(p)
+		return p, "file", nil
+	}
+
+	if project == "" {
+		return ComposeProject{}, "", fmt.Errorf("project or file is required")
+	}
+
+	p, err := discoverProject(ctx, exec, project)
+	if err == nil {
+		save(p)
+		return p, "containers", nil
+	}
+	if !errors.Is(err, errNoProjectContainers) {
+		return ComposeProject{}, "", err
+	}
+	if p, ok := projects.Lookup(project); ok {
+		return p, "registry", nil
+	}
+	return ComposeProject{}, "", fmt.Errorf("%w: cannot determine working directory. Start the project manually first or specify the compose file path", err)
 }
 
-func handleComposeUp(ctx context.Context, exec docker.Executor, args composeUpArgs) (string, error) {
-	workDir, err := discoverWorkDir(ctx, exec, args.Project)
+// composeArgs builds the global docker compose arguments for a project.
+func composeArgs(p ComposeProject, project string, profiles, envFiles []string) []string {
+	cmdArgs := []string{"compose", "--project-directory", p.WorkDir}
+	for _, f := range p.ConfigFiles {
+		cmdArgs = append(cmdArgs, "-f", f)
+	}
+	for _, profile := range profiles {
+		cmdArgs = append(cmdArgs, "--profile", profile)
+	}
+	for _, env := range envFiles {
+		if !filepath.IsAbs(env) {
+			env = filepath.Join(p.WorkDir, env)
+		}
+		cmdArgs = append(cmdArgs, "--env-file", env)
+	}
+	if project != "" {
+		cmdArgs = append(cmdArgs, "-p", project)
+	}
+	return cmdArgs
+}
+
+// projectLabel names a project in messages, falling back to its directory
+// when only a compose file was given.
+func projectLabel(project string, p ComposeProject) string {
+	if project != "" {
+		return fmt.Sprintf("%q", project)
+	}
+	return fmt.Sprintf("in %s", p.WorkDir)
+}
+
+func handleComposeUp(ctx context.Context, exec docker.Executor, projects *ProjectRegistry, args composeUpArgs) (string, composeOutput, error) {
+	out := composeOutput{Project: args.Project}
+	p, source, err := composeTarget(ctx, exec, projects, args.Project, args.File, true)
 	if err != nil {
-		return "", err
+		return "", out, err
 	}
+	out.WorkDir, out.ConfigFiles, out.Source = p.WorkDir, p.ConfigFiles, source
 
-	cmdArgs := []string{"compose", "--project-directory", workDir, "-p", args.Project, "up", "-d"}
+	cmdArgs := append(composeArgs(p, args.Project, args.Profiles, args.EnvFiles), "up", "-d")
 	cmdArgs = append(cmdArgs, args.Services...)
 
 	output, err := exec.ExecCombined(ctx, cmdArgs...)
 	if err != nil {
-		return "", fmt.Errorf("compose up failed: %w", err)
+		return "", out, fmt.Errorf("compose up failed: %w", err)
 	}
+	out.Output = output
 
-	return fmt.Sprintf("Compose project %q started (workdir: %s)\n%s", args.Project, workDir, output), nil
+	return fmt.Sprintf("Compose project %s started (workdir: %s)\n%s", projectLabel(args.Project, p), p.WorkDir, output), out, nil
 }
 
-func handleComposeDown(ctx context.Context, exec docker.Executor, args composeDownArgs) (string, error) {
-	workDir, err := discoverWorkDir(ctx, exec, args.Project)
+func handleComposeDown(ctx context.Context, exec docker.Executor, projects *ProjectRegistry, args composeDownArgs) (string, composeOutput, error) {
+	out := composeOutput{Project: args.Project}
+	p, source, err := composeTarget(ctx, exec, projects, args.Project, args.File, true)
 	if err != nil {
-		return "", err
+		return "", out, err
 	}
+	out.WorkDir, out.ConfigFiles, out.Source = p.WorkDir, p.ConfigFiles, source
 
-	cmdArgs := []string{"compose", "--project-directory", workDir, "-p", args.Project, "down"}
+	cmdArgs := append(composeArgs(p, args.Project, args.Profiles, args.EnvFiles), "down")
 	if args.RemoveVolumes {
 		cmdArgs = append(cmdArgs, "--volumes")
 	}
 
 	output, err := exec.ExecCombined(ctx, cmdArgs...)
 	if err != nil {
-		return "", fmt.Errorf("compose down failed: %w", err)
+		return "", out, fmt.Errorf("compose down failed: %w", err)
+	}
+	out.Output = output
+
+	return fmt.Sprintf("Compose project %s stopped (workdir: %s)\n%s", projectLabel(args.Project, p), p.WorkDir, output), out, nil
+}
+
+func handleComposeConfig(ctx context.Context, exec docker.Executor, projects *ProjectRegistry, args composeConfigArgs) (string, composeOutput, error) {
+	out := composeOutput{Project: args.Project}
+	// compose_config is read-only, so it does not write the registry.
+	p, source, err := composeTarget(ctx, exec, projects, args.Project, args.File, false)
+	if err != nil {
+		return "", out, err
 	}
+	out.WorkDir, out.ConfigFiles, out.Source = p.WorkDir, p.ConfigFiles, source
 
-	return fmt.Sprintf("Compose project %q stopped (workdir: %s)\n%s", args.Project, workDir, output), nil
+	cmdArgs := append(composeArgs(p, args.Project, args.Profiles, args.EnvFiles), "config")
+	cmdArgs = append(cmdArgs, args.Services...)
+
+	// Use stdout only: warnings on stderr would corrupt the rendered YAML.
+	output, err := exec.Exec(ctx, cmdArgs...)
+	if err != nil {
+		return "", out, fmt.Errorf("compose config failed: %w", err)
+	}
+	out.Output = output
+
+	return output, out, nil
 }
 
-func registerComposeUpDown(server *mcp.Server, exec docker.Executor) {
+func registerComposeUpDown(server *mcp.Server, exec docker.Executor, projects *ProjectRegistry) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "compose_up",
-		Description: "Start a Docker Compose project. Discovers the project's working directory from existing containers and runs docker compose up -d.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args composeUpArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleComposeUp(ctx, exec, args)
+		Description: "Start a Docker Compose project and run docker compose up -d. Uses the given compose file or directory, or discovers the project's location from existing containers or from projects seen earlier.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args composeUpArgs) (*mcp.CallToolResult, composeOutput, error) {
+		result, out, err := handleComposeUp(ctx, exec, projects, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "compose_down",
-		Description: "Stop a Docker Compose project. Discovers the project's working directory from existing containers and runs docker compose down.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args composeDownArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleComposeDown(ctx, exec, args)
+		Description: "Stop a Docker Compose project and run docker compose down. Uses the given compose file or directory, or discovers the project's location from existing containers or from projects seen earlier.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args composeDownArgs) (*mcp.CallToolResult, composeOutput, error) {
+		result, out, err := handleComposeDown(ctx, exec, projects, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
+
+func registerComposeConfig(server *mcp.Server, exec docker.Executor, projects *ProjectRegistry) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "compose_config",
+		Description: "Render the resolved configuration of a Docker Compose project (docker compose config), with profiles and env files applied. Works for projects that have never been started when given a compose file or directory.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args composeConfigArgs) (*mcp.CallToolResult, composeOutput, error) {
+		result, out, err := handleComposeConfig(ctx, exec, projects, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
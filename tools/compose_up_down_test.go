@@ -3,6 +3,8 @@ package tools
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -21,7 +23,7 @@ func TestHandleComposeUp_ExistingProject(t *testing.T) {
 		Project: "myproject",
 	}
 
-	result, err := handleComposeUp(context.Background(), mock, args)
+	result, _, err := handleComposeUp(context.Background(), mock, NewProjectRegistry(), args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -50,7 +52,7 @@ func TestHandleComposeUp_WithServices(t *testing.T) {
 		Services: []string{"web", "redis"},
 	}
 
-	result, err := handleComposeUp(context.Background(), mock, args)
+	result, _, err := handleComposeUp(context.Background(), mock, NewProjectRegistry(), args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -72,7 +74,7 @@ func TestHandleComposeDown_ExistingProject(t *testing.T) {
 		Project: "myproject",
 	}
 
-	result, err := handleComposeDown(context.Background(), mock, args)
+	result, _, err := handleComposeDown(context.Background(), mock, NewProjectRegistry(), args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -98,7 +100,7 @@ func TestHandleComposeDown_RemoveVolumes(t *testing.T) {
 		RemoveVolumes: true,
 	}
 
-	result, err := handleComposeDown(context.Background(), mock, args)
+	result, _, err := handleComposeDown(context.Background(), mock, NewProjectRegistry(), args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -117,7 +119,7 @@ func TestHandleComposeUp_ProjectNotFound(t *testing.T) {
 		Project: "ghost",
 	}
 
-	_, err := handleComposeUp(context.Background(), mock, args)
+	_, _, err := handleComposeUp(context.Background(), mock, NewProjectRegistry(), args)
 	if err == nil {
 		t.Fatal("expected error for non-existent project")
 	}
@@ -135,7 +137,7 @@ func TestHandleComposeDown_ProjectNotFound(t *testing.T) {
 		Project: "ghost",
 	}
 
-	_, err := handleComposeDown(context.Background(), mock, args)
+	_, _, err := handleComposeDown(context.Background(), mock, NewProjectRegistry(), args)
 	if err == nil {
 		t.Fatal("expected error for non-existent project")
 	}
@@ -153,7 +155,7 @@ func TestHandleComposeUp_DockerError(t *testing.T) {
 		Project: "myproject",
 	}
 
-	_, err := handleComposeUp(context.Background(), mock, args)
+	_, _, err := handleComposeUp(context.Background(), mock, NewProjectRegistry(), args)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -161,3 +163,174 @@ func TestHandleComposeUp_DockerError(t *testing.T) {
 		t.Errorf("expected docker daemon error, got: %v", err)
 	}
 }
+
+func TestHandleComposeUp_ExplicitFileWithProfilesAndEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "compose.prod.yml")
+	if err := os.WriteFile(file, []byte("services: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := docker.NewMock()
+	mock.On("compose --project-directory "+dir+" -f "+file+" --profile debug --env-file "+filepath.Join(dir, ".env.prod")+" --env-file /etc/shared.env -p fresh up -d", "Creating fresh-web-1 ... done\n", nil)
+
+	projects := NewProjectRegistry()
+	result, out, err := handleComposeUp(context.Background(), mock, projects, composeUpArgs{
+		Project:  "fresh",
+		File:     file,
+		Profiles: []string{"debug"},
+		EnvFiles: []string{".env.prod", "/etc/shared.env"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(result, "started") || out.Source != "file" || out.WorkDir != dir {
+		t.Errorf("unexpected result %q / %+v", result, out)
+	}
+	for _, call := range mock.Calls() {
+		if call[0] == "ps" {
+			t.Error("should not discover containers when a file is given")
+		}
+	}
+	if p, ok := projects.Lookup("fresh"); !ok || p.WorkDir != dir || len(p.ConfigFiles) != 1 || p.ConfigFiles[0] != file {
+		t.Errorf("expected explicit file to be remembered, got %+v", p)
+	}
+}
+
+func TestHandleComposeUp_ExplicitDirectoryWithoutProject(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	mock.On("compose --project-directory "+dir+" up -d", "", nil)
+
+	result, _, err := handleComposeUp(context.Background(), mock, NewProjectRegistry(), composeUpArgs{File: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "in "+dir) {
+		t.Errorf("expected directory in result, got %q", result)
+	}
+}
+
+func TestHandleComposeUp_MissingFile(t *testing.T) {
+	_, _, err := handleComposeUp(context.Background(), docker.NewMock(), NewProjectRegistry(), composeUpArgs{
+		Project: "fresh", File: filepath.Join(t.TempDir(), "nope.yml"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "nope.yml") {
+		t.Errorf("expected error naming the missing file, got %v", err)
+	}
+}
+
+func TestHandleComposeDownThenUp_UsesRegistry(t *testing.T) {
+	mock := docker.NewMock()
+	psOutput := `{"ID":"abc123","Names":"myproject-web-1","State":"running"}`
+	mock.On(`ps -a --format {{json .}} --filter label=com.docker.compose.project=myproject`, psOutput, nil)
+	mock.On(`inspect --format {{index .Config.Labels "com.docker.compose.project.working_dir"}} abc123`, "/home/user/myproject\n", nil)
+	mock.On(`inspect --format {{index .Config.Labels "com.docker.compose.project.config_files"}} abc123`, "/home/user/myproject/compose.yml,/home/user/myproject/compose.override.yml\n", nil)
+	mock.On("compose --project-directory /home/user/myproject -f /home/user/myproject/compose.yml -f /home/user/myproject/compose.override.yml -p myproject down", "", nil)
+
+	projects := NewProjectRegistry()
+	_, out, err := handleComposeDown(context.Background(), mock, projects, composeDownArgs{Project: "myproject"})
+	if err != nil {
+		t.Fatalf("compose down: %v", err)
+	}
+	if out.Source != "containers" || len(out.ConfigFiles) != 2 {
+		t.Errorf("expected discovered config files, got %+v", out)
+	}
+
+	// After compose down no containers are left to discover from.
+	mock.On(`ps -a --format {{json .}} --filter label=com.docker.compose.project=myproject`, "", nil)
+	mock.On("compose --project-directory /home/user/myproject -f /home/user/myproject/compose.yml -f /home/user/myproject/compose.override.yml -p myproject up -d", "", nil)
+
+	_, out, err = handleComposeUp(context.Background(), mock, projects, composeUpArgs{Project: "myproject"})
+	if err != nil {
+		t.Fatalf("compose up after down: %v", err)
+	}
+	if out.Source != "registry" {
+		t.Errorf("expected registry as source, got %+v", out)
+	}
+}
+
+func TestHandleComposeConfig(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	mock.On("compose --project-directory "+dir+" --profile tools -p fresh config web", "services:\n  web:\n    image: nginx\n", nil)
+
+	result, out, err := handleComposeConfig(context.Background(), mock, NewProjectRegistry(), composeConfigArgs{
+		Project: "fresh", File: dir, Profiles: []string{"tools"}, Services: []string{"web"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "image: nginx") || out.Output != result {
+		t.Errorf("expected rendered config, got %q", result)
+	}
+}
+
+func TestHandleComposeUp_RegistrySaveFails(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(t.TempDir(), "state")
+	projects, err := OpenProjectRegistry(filepath.Join(state, "projects.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A file where the registry's directory should be makes saving fail.
+	if err := os.WriteFile(state, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mock := docker.NewMock()
+	mock.On("compose --project-directory "+dir+" -p shop up -d", "", nil)
+
+	if _, _, err := handleComposeUp(context.Background(), mock, projects, composeUpArgs{Project: "shop", File: dir}); err != nil {
+		t.Fatalf("expected a registry that cannot be saved not to fail the call, got %v", err)
+	}
+	if p, ok := projects.Lookup("shop"); !ok || p.WorkDir != dir {
+		t.Errorf("expected the project to be remembered in memory, got %+v", p)
+	}
+}
+
+func TestHandleComposeConfig_DoesNotSaveRegistry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "projects.json")
+	projects, err := OpenProjectRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := docker.NewMock()
+	mock.On("compose --project-directory "+dir+" -p fresh config", "services: {}\n", nil)
+
+	if _, _, err := handleComposeConfig(context.Background(), mock, projects, composeConfigArgs{Project: "fresh", File: dir}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected compose_config not to write the registry, got %v", err)
+	}
+}
+
+func TestProjectRegistry_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "projects.json")
+
+	r, err := OpenProjectRegistry(path)
+	if err != nil {
+		t.Fatalf("open empty registry: %v", err)
+	}
+	if err := r.Remember("shop", ComposeProject{WorkDir: "/src/shop", ConfigFiles: []string{"/src/shop/compose.yml"}}); err != nil {
+		t.Fatalf("remember: %v", err)
+	}
+
+	reopened, err := OpenProjectRegistry(path)
+	if err != nil {
+		t.Fatalf("reopen registry: %v", err)
+	}
+	p, ok := reopened.Lookup("shop")
+	if !ok || p.WorkDir != "/src/shop" || len(p.ConfigFiles) != 1 {
+		t.Errorf("expected persisted project, got %+v (found %v)", p, ok)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenProjectRegistry(path); err == nil {
+		t.Error("expected error for a corrupt registry file")
+	}
+}
@@ -0,0 +1,319 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	// cpDefaultMaxBytes is how much a copy may transfer unless max_bytes
+	// says otherwise, and cpMaxBytes is the most it may ask for.
+	cpDefaultMaxBytes = 1 << 20
+	cpMaxBytes        = 64 << 20
+)
+
+// errCopyTooLarge is returned when output goes past its size limit.
+var errCopyTooLarge = errors.New("output exceeds the size limit")
+
+// limitedBuffer collects writes up to limit bytes and fails any write past
+// it, which stops the docker command producing them. It deliberately has no
+// ReadFrom or WriteString, so io.Copy and io.WriteString cannot bypass the
+// limit.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int64
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - int64(b.buf.Len()); int64(len(p)) > room {
+		n, _ := b.buf.Write(p[:room])
+		return n, errCopyTooLarge
+	}
+	return b.buf.Write(p)
+}
+
+// cpLimit resolves a max_bytes argument.
+func cpLimit(maxBytes int64) (int64, error) {
+	switch {
+	case maxBytes == 0:
+		return cpDefaultMaxBytes, nil
+	case maxBytes < 0 || maxBytes > cpMaxBytes:
+		return 0, fmt.Errorf("max_bytes must be between 1 and %d", cpMaxBytes)
+	}
+	return maxBytes, nil
+}
+
+// copyFileFromContainer reads a single regular file out of a container
+// through the tar stream of "docker cp CONTAINER:PATH -", refusing files
+// larger than limit bytes.
+func copyFileFromContainer(ctx context.Context, exec docker.Executor, container, file string, limit int64) (*tar.Header, []byte, error) {
+	var hdr *tar.Header
+	var data []byte
+	err := walkContainerArchive(ctx, exec, container, file, func(h *tar.Header, p string, depth int, body io.Reader) error {
+		if err := regularFile(h, file); err != nil {
+			return err
+		}
+		if h.Size > limit {
+			return fmt.Errorf("%s is %s, larger than the %s limit", file, binarySize(uint64(h.Size)), binarySize(uint64(limit)))
+		}
+		var err error
+		if data, err = io.ReadAll(body); err != nil {
+			return fmt.Errorf("failed to read the copied archive: %w", err)
+		}
+		hdr = h
+		return errStopWalk
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if hdr == nil {
+		return nil, nil, fmt.Errorf("%s not found in %s", file, container)
+	}
+	return hdr, data, nil
+}
+
+// isText reports whether data can be shown inline as text: valid UTF-8
+// without control characters other than whitespace and the escape that
+// starts terminal colors.
+func isText(data []byte) bool {
+	for _, b := range data {
+		if b < 0x20 && !strings.ContainsRune("\t\n\v\f\r\x1b", rune(b)) || b == 0x7f {
+			return false
+		}
+	}
+	return utf8.Valid(data)
+}
+
+// resolveHostPath resolves p, relative to the allowed directory dir, and
+// checks that the result, with any symlinks followed, stays inside dir. The
+// final element of p need not exist yet, but its parent must.
+func resolveHostPath(dir, p string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("host copies are disabled; start the server with -copy-dir to allow them")
+	}
+	if p == "" {
+		return "", fmt.Errorf("host_path is required")
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("copy directory: %w", err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("copy directory: %w", err)
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	} else if abs, err := filepath.Abs(dir); err == nil {
+		// Accept absolute paths spelled through a symlinked copy directory.
+		if rel, err := filepath.Rel(abs, p); err == nil && filepath.IsLocal(rel) {
+			p = filepath.Join(root, rel)
+		}
+	}
+	outside := fmt.Errorf("host_path %s is outside the copy directory %s", p, dir)
+	if rel, err := filepath.Rel(root, filepath.Clean(p)); err != nil || !filepath.IsLocal(rel) {
+		return "", outside
+	}
+
+	resolved, err := filepath.EvalSymlinks(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		parent, perr := filepath.EvalSymlinks(filepath.Dir(p))
+		if perr != nil {
+			return "", fmt.Errorf("host_path %s: %w", p, perr)
+		}
+		resolved, err = filepath.Join(parent, filepath.Base(p)), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("host_path %s: %w", p, err)
+	}
+	// Check again now that symlinks are followed.
+	if rel, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(rel) {
+		return "", outside
+	}
+	return resolved, nil
+}
+
+// containerFileURI identifies a copied file in an embedded resource.
+func containerFileURI(container, file string) string {
+	return (&url.URL{Scheme: "container", Host: container, Path: path.Clean("/" + file)}).String()
+}
+
+type containerCpFromArgs struct {
+	Container string `json:"container" jsonschema:"container name or ID"`
+	Path      string `json:"path" jsonschema:"path of the file inside the container"`
+	MaxBytes  int64  `json:"max_bytes,omitempty" jsonschema:"largest file to copy in bytes (default 1MiB, max 64MiB)"`
+	HostPath  string `json:"host_path,omitempty" jsonschema:"save the file here, relative to the server's copy directory, instead of returning its content"`
+	Overwrite bool   `json:"overwrite,omitempty" jsonschema:"replace host_path if it already exists"`
+}
+
+type containerCpFromOutput struct {
+	Container string `json:"container"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Mode      string `json:"mode"`
+	ModTime   string `json:"mtime"`
+	Text      bool   `json:"text" jsonschema:"whether the file is UTF-8 text; binary files are returned as an embedded base64 resource"`
+	MIMEType  string `json:"mime_type"`
+	Content   string `json:"content,omitempty" jsonschema:"the file content, for text files returned inline"`
+	SavedTo   string `json:"saved_to,omitempty" jsonschema:"host path the file was written to"`
+}
+
+func handleContainerCpFrom(ctx context.Context, exec docker.Executor, copyDir string, readOnly bool, args containerCpFromArgs) ([]mcp.Content, containerCpFromOutput, error) {
+	out := containerCpFromOutput{Container: args.Container, Path: args.Path}
+	if args.Container == "" || args.Path == "" {
+		return nil, out, fmt.Errorf("container and path are required")
+	}
+	limit, err := cpLimit(args.MaxBytes)
+	if err != nil {
+		return nil, out, err
+	}
+	var target string
+	if args.HostPath != "" {
+		// Saving writes to the host, which read-only mode must not do.
+		if readOnly {
+			return nil, out, fmt.Errorf("host_path is not allowed in read-only mode; omit it to return the content inline")
+		}
+		if target, err = resolveHostPath(copyDir, args.HostPath); err != nil {
+			return nil, out, err
+		}
+		if _, err := os.Lstat(target); err == nil && !args.Overwrite {
+			return nil, out, fmt.Errorf("%s already exists; set overwrite to replace it", target)
+		}
+	}
+
+	hdr, data, err := copyFileFromContainer(ctx, exec, args.Container, args.Path, limit)
+	if err != nil {
+		return nil, out, err
+	}
+	out.Size = hdr.Size
+	out.Mode = hdr.FileInfo().Mode().String()
+	out.ModTime = hdr.ModTime.UTC().Format(time.RFC3339)
+	out.Text = isText(data)
+	out.MIMEType = http.DetectContentType(data)
+
+	header := fmt.Sprintf("%s:%s (%s, %s, modified %s)", args.Container, args.Path, binarySize(uint64(out.Size)), out.Mode, out.ModTime)
+	if target != "" {
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return nil, out, fmt.Errorf("failed to save %s: %w", target, err)
+		}
+		out.SavedTo = target
+		return []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Copied %s to %s", header, target)}}, out, nil
+	}
+
+	if out.Text {
+		out.Content = string(data)
+		return []mcp.Content{&mcp.TextContent{Text: header + "\n\n" + out.Content}}, out, nil
+	}
+	return []mcp.Content{
+		&mcp.TextContent{Text: fmt.Sprintf("%s\nBinary file (%s) returned as an embedded resource.", header, out.MIMEType)},
+		&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+			URI:      containerFileURI(args.Container, args.Path),
+			MIMEType: out.MIMEType,
+			Blob:     data,
+		}},
+	}, out, nil
+}
+
+type containerCpToArgs struct {
+	Container string `json:"container" jsonschema:"container name or ID"`
+	HostPath  string `json:"host_path" jsonschema:"file or directory to copy, relative to the server's copy directory"`
+	Dest      string `json:"dest" jsonschema:"destination path inside the container; an existing directory receives the source inside it"`
+	MaxBytes  int64  `json:"max_bytes,omitempty" jsonschema:"largest total size to copy in bytes (default 1MiB, max 64MiB)"`
+}
+
+type containerCpToOutput struct {
+	Container string `json:"container"`
+	Source    string `json:"source"`
+	Dest      string `json:"dest"`
+	Files     int    `json:"files"`
+	Bytes     int64  `json:"bytes"`
+}
+
+func handleContainerCpTo(ctx context.Context, exec docker.Executor, copyDir string, args containerCpToArgs) (string, containerCpToOutput, error) {
+	out := containerCpToOutput{Container: args.Container, Dest: args.Dest}
+	if args.Container == "" || args.Dest == "" {
+		return "", out, fmt.Errorf("container and dest are required")
+	}
+	limit, err := cpLimit(args.MaxBytes)
+	if err != nil {
+		return "", out, err
+	}
+	source, err := resolveHostPath(copyDir, args.HostPath)
+	if err != nil {
+		return "", out, err
+	}
+	out.Source = source
+
+	// docker cp copies symlinks below the source as links rather than
+	// following them, so only the source itself needed resolving.
+	err = filepath.WalkDir(source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		out.Files++
+		out.Bytes += info.Size()
+		if out.Bytes > limit {
+			return fmt.Errorf("%s is larger than the %s limit", args.HostPath, binarySize(uint64(limit)))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", out, err
+	}
+
+	if _, err := exec.Exec(ctx, "cp", source, args.Container+":"+args.Dest); err != nil {
+		return "", out, fmt.Errorf("copy failed: %w", err)
+	}
+	return fmt.Sprintf("Copied %s (%d files, %s) to %s:%s", source, out.Files, binarySize(uint64(out.Bytes)), args.Container, args.Dest), out, nil
+}
+
+func registerContainerCpFrom(server *mcp.Server, exec docker.Executor, copyDir string, readOnly bool) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "container_cp_from",
+		Description: "Copy a file out of a container with docker cp. Text files are returned inline and binary files as an embedded base64 resource; with host_path, the file is saved under the server's copy directory instead (not in read-only mode). Works on images without a shell.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerCpFromArgs) (*mcp.CallToolResult, containerCpFromOutput, error) {
+		content, out, err := handleContainerCpFrom(ctx, exec, copyDir, readOnly, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{Content: content}, out, nil
+	})
+}
+
+func registerContainerCpTo(server *mcp.Server, exec docker.Executor, copyDir string) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "container_cp_to",
+		Description: "Copy a file or directory from the server's copy directory into a container with docker cp. Requires the server to be started with -copy-dir.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerCpToArgs) (*mcp.CallToolResult, containerCpToOutput, error) {
+		result, out, err := handleContainerCpTo(ctx, exec, copyDir, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
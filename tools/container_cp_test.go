@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// tarEntry describes one member of a tar fixture.
+type tarEntry struct {
+	name     string
+	typeflag byte
+	mode     int64
+	body     string
+	linkname string
+}
+
+// tarFixture renders entries as the tar stream docker cp writes.
+func tarFixture(t *testing.T, entries ...tarEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Mode:     e.mode,
+			Size:     int64(len(e.body)),
+			Linkname: e.linkname,
+			ModTime:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestHandleContainerCpFrom_Text(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("cp web:/etc/app.conf -", tarFixture(t, tarEntry{name: "app.conf", typeflag: tar.TypeReg, mode: 0o644, body: "port = 8080\n"}), nil)
+
+	content, out, err := handleContainerCpFrom(context.Background(), mock, "", false, containerCpFromArgs{Container: "web", Path: "/etc/app.conf"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !out.Text || out.Content != "port = 8080\n" || out.Size != 12 || out.Mode != "-rw-r--r--" || out.ModTime != "2024-01-01T00:00:00Z" {
+		t.Errorf("unexpected output %+v", out)
+	}
+	if len(content) != 1 {
+		t.Fatalf("expected a single text content, got %v", content)
+	}
+	text := content[0].(*mcp.TextContent).Text
+	if !strings.HasPrefix(text, "web:/etc/app.conf (12B, -rw-r--r--") || !strings.HasSuffix(text, "\n\nport = 8080\n") {
+		t.Errorf("unexpected text:\n%s", text)
+	}
+}
+
+func TestHandleContainerCpFrom_Binary(t *testing.T) {
+	mock := docker.NewMock()
+	core := "\x7fELF\x02\x01\x01\x00\x00\x00"
+	mock.On("cp web:/tmp/core -", tarFixture(t, tarEntry{name: "core", typeflag: tar.TypeReg, mode: 0o600, body: core}), nil)
+
+	content, out, err := handleContainerCpFrom(context.Background(), mock, "", false, containerCpFromArgs{Container: "web", Path: "/tmp/core"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Text || out.Content != "" || out.MIMEType != "application/octet-stream" {
+		t.Errorf("unexpected output %+v", out)
+	}
+	if len(content) != 2 {
+		t.Fatalf("expected text and an embedded resource, got %v", content)
+	}
+	res, ok := content[1].(*mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("expected an embedded resource, got %T", content[1])
+	}
+	if res.Resource.URI != "container://web/tmp/core" || string(res.Resource.Blob) != core || res.Resource.MIMEType != "application/octet-stream" {
+		t.Errorf("unexpected resource %+v", res.Resource)
+	}
+}
+
+func TestHandleContainerCpFrom_SaveToCopyDir(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	mock.On("cp web:/out/report.json -", tarFixture(t, tarEntry{name: "report.json", typeflag: tar.TypeReg, mode: 0o644, body: `{"ok":true}`}), nil)
+
+	args := containerCpFromArgs{Container: "web", Path: "/out/report.json", HostPath: "report.json"}
+	content, out, err := handleContainerCpFrom(context.Background(), mock, dir, false, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil || string(data) != `{"ok":true}` {
+		t.Errorf("expected the file saved, got %q %v", data, err)
+	}
+	if out.Content != "" || !strings.HasSuffix(out.SavedTo, "report.json") || !strings.Contains(content[0].(*mcp.TextContent).Text, "Copied web:/out/report.json") {
+		t.Errorf("unexpected output %+v", out)
+	}
+
+	if _, _, err := handleContainerCpFrom(context.Background(), mock, dir, false, args); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected refusal to overwrite, got %v", err)
+	}
+	args.Overwrite = true
+	if _, _, err := handleContainerCpFrom(context.Background(), mock, dir, false, args); err != nil {
+		t.Errorf("unexpected error with overwrite: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "report.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := handleContainerCpFrom(context.Background(), mock, dir, true, args); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("expected host_path to be refused in read-only mode, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "report.json")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written in read-only mode, got %v", err)
+	}
+}
+
+func TestHandleContainerCpFrom_Errors(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("cp web:/etc -", tarFixture(t, tarEntry{name: "etc", typeflag: tar.TypeDir, mode: 0o755}), nil)
+	mock.On("cp web:/app/current -", tarFixture(t, tarEntry{name: "current", typeflag: tar.TypeSymlink, linkname: "/app/v2"}), nil)
+	mock.On("cp web:/var/log/big.log -", tarFixture(t, tarEntry{name: "big.log", typeflag: tar.TypeReg, mode: 0o644, body: strings.Repeat("x", 2048)}), nil)
+	mock.On("cp web:/nope -", "", fmt.Errorf("Error: Could not find the file /nope in container web"))
+
+	tests := []struct {
+		name string
+		args containerCpFromArgs
+		want string
+	}{
+		{"missing path", containerCpFromArgs{Container: "web"}, "required"},
+		{"bad max_bytes", containerCpFromArgs{Container: "web", Path: "/x", MaxBytes: cpMaxBytes + 1}, "max_bytes"},
+		{"directory", containerCpFromArgs{Container: "web", Path: "/etc"}, "is a directory"},
+		{"symlink", containerCpFromArgs{Container: "web", Path: "/app/current"}, "symlink to /app/v2"},
+		{"too large", containerCpFromArgs{Container: "web", Path: "/var/log/big.log", MaxBytes: 1024}, "2KiB, larger than the 1KiB limit"},
+		{"docker error", containerCpFromArgs{Container: "web", Path: "/nope"}, "Could not find the file"},
+		{"host copies disabled", containerCpFromArgs{Container: "web", Path: "/etc", HostPath: "etc"}, "-copy-dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := handleContainerCpFrom(context.Background(), mock, "", false, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestResolveHostPath(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"new.txt", "sub/new.txt", filepath.Join(dir, "sub"), "."} {
+		if _, err := resolveHostPath(dir, p); err != nil {
+			t.Errorf("resolveHostPath(%q): unexpected error: %v", p, err)
+		}
+	}
+	for _, p := range []string{"../x", "sub/../../x", filepath.Join(outside, "x"), "escape/x", "escape", "missing/x", ""} {
+		if _, err := resolveHostPath(dir, p); err == nil {
+			t.Errorf("resolveHostPath(%q): expected error", p)
+		}
+	}
+	if _, err := resolveHostPath("", "x"); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("expected disabled error, got %v", err)
+	}
+}
+
+func TestHandleContainerCpTo(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "conf"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range map[string]string{"conf/a.conf": "a=1\n", "conf/b.conf": "b=2\n", "big.bin": strings.Repeat("x", 4096)} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, _ := filepath.EvalSymlinks(dir)
+	mock := docker.NewMock()
+	mock.On("cp "+filepath.Join(root, "conf")+" web:/etc/app", "", nil)
+
+	result, out, err := handleContainerCpTo(context.Background(), mock, dir, containerCpToArgs{Container: "web", HostPath: "conf", Dest: "/etc/app"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Files != 2 || out.Bytes != 8 {
+		t.Errorf("unexpected output %+v", out)
+	}
+	if !strings.Contains(result, "(2 files, 8B) to web:/etc/app") {
+		t.Errorf("unexpected result %q", result)
+	}
+
+	if _, _, err := handleContainerCpTo(context.Background(), mock, dir, containerCpToArgs{Container: "web", HostPath: "big.bin", Dest: "/tmp", MaxBytes: 1024}); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("expected size limit error, got %v", err)
+	}
+	if _, _, err := handleContainerCpTo(context.Background(), mock, dir, containerCpToArgs{Container: "web", HostPath: "../etc/passwd", Dest: "/tmp"}); err == nil || !strings.Contains(err.Error(), "outside the copy directory") {
+		t.Errorf("expected confinement error, got %v", err)
+	}
+	if _, _, err := handleContainerCpTo(context.Background(), mock, "", containerCpToArgs{Container: "web", HostPath: "conf", Dest: "/tmp"}); err == nil || !strings.Contains(err.Error(), "-copy-dir") {
+		t.Errorf("expected disabled error, got %v", err)
+	}
+	if len(mock.Calls()) != 1 {
+		t.Errorf("expected only the valid copy to reach docker, got %v", mock.Calls())
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/otsukatsuka/orbstack-mcp/docker"
@@ -19,12 +21,21 @@ type containerEventsArgs struct {
 
 // dockerEvent represents the JSON output of docker events.
 type dockerEvent struct {
-	Status   string            `json:"status"`
-	Action   string            `json:"Action"`
-	Type     string            `json:"Type"`
-	Actor    dockerEventActor  `json:"Actor"`
-	Time     int64             `json:"time"`
-	TimeNano string            `json:"timeNano"`
+	Status   string           `json:"status"`
+	Action   string           `json:"Action"`
+	Type     string           `json:"Type"`
+	Actor    dockerEventActor `json:"Actor"`
+	Time     int64            `json:"time"`
+	TimeNano int64            `json:"timeNano"`
+}
+
+// timestamp returns when the event happened, preferring the nanosecond
+// precision timeNano field.
+func (e dockerEvent) timestamp() time.Time {
+	if e.TimeNano != 0 {
+		return time.Unix(0, e.TimeNano)
+	}
+	return time.Unix(e.Time, 0)
 }
 
 type dockerEventActor struct {
@@ -32,7 +43,67 @@ type dockerEventActor struct {
 	Attributes map[string]string `json:"Attributes"`
 }
 
-func handleContainerEvents(ctx context.Context, exec docker.Executor, args containerEventsArgs) (string, error) {
+type containerEventsOutput struct {
+	Events []containerEvent `json:"events" jsonschema:"events in the order docker reported them"`
+}
+
+type containerEvent struct {
+	Time       time.Time         `json:"time"`
+	Container  string            `json:"container" jsonschema:"container name, or short ID when the name is unknown"`
+	ID         string            `json:"id"`
+	Action     string            `json:"action"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// newContainerEvent converts a docker event, naming the object by its name
+// attribute or else its short ID.
+func newContainerEvent(event dockerEvent) containerEvent {
+	name := event.Actor.Attributes["name"]
+	if name == "" {
+		name = event.Actor.ID
+		if len(name) > 12 {
+			name = name[:12]
+		}
+	}
+
+	action := event.Action
+	if action == "" {
+		action = event.Status
+	}
+
+	ev := containerEvent{Time: event.timestamp(), Container: name, ID: event.Actor.ID, Action: action}
+	for k, v := range event.Actor.Attributes {
+		if k == "name" {
+			continue
+		}
+		if ev.Attributes == nil {
+			ev.Attributes = make(map[string]string)
+		}
+		ev.Attributes[k] = v
+	}
+	return ev
+}
+
+// formatEventAttributes renders attributes as " (k=v, ...)" in key order, or
+// "" when there are none.
+func formatEventAttributes(attributes map[string]string) string {
+	if len(attributes) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, fmt.Sprintf("%s=%s", k, attributes[k]))
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(attrs, ", "))
+}
+
+func handleContainerEvents(ctx context.Context, exec docker.Executor, args containerEventsArgs) (string, containerEventsOutput, error) {
+	var out containerEventsOutput
 	since := args.Since
 	if since == "" {
 		since = "1h"
@@ -53,12 +124,12 @@ func handleContainerEvents(ctx context.Context, exec docker.Executor, args conta
 
 	output, err := exec.Exec(ctx, cmdArgs...)
 	if err != nil {
-		return "", fmt.Errorf("failed to get events: %w", err)
+		return "", out, fmt.Errorf("failed to get events: %w", err)
 	}
 
 	output = strings.TrimSpace(output)
 	if output == "" {
-		return "No events found in the specified time range.", nil
+		return "No events found in the specified time range.", out, nil
 	}
 
 	var sb strings.Builder
@@ -85,53 +156,25 @@ func handleContainerEvents(ctx context.Context, exec docker.Executor, args conta
 			continue
 		}
 
-		name := event.Actor.Attributes["name"]
-		if name == "" {
-			name = event.Actor.ID
-			if len(name) > 12 {
-				name = name[:12]
-			}
-		}
-
-		action := event.Action
-		if action == "" {
-			action = event.Status
-		}
-
-		// Build attributes summary (skip name since we already show it).
-		var attrs []string
-		for k, v := range event.Actor.Attributes {
-			if k == "name" {
-				continue
-			}
-			attrs = append(attrs, fmt.Sprintf("%s=%s", k, v))
-		}
-
-		attrStr := ""
-		if len(attrs) > 0 {
-			attrStr = fmt.Sprintf(" (%s)", strings.Join(attrs, ", "))
-		}
-
-		sb.WriteString(fmt.Sprintf("  [%d] %s: %s%s\n", event.Time, name, action, attrStr))
+		ev := newContainerEvent(event)
+		sb.WriteString(fmt.Sprintf("  [%d] %s: %s%s\n", event.Time, ev.Container, ev.Action, formatEventAttributes(ev.Attributes)))
+		out.Events = append(out.Events, ev)
 	}
 
-	return sb.String(), nil
+	return sb.String(), out, nil
 }
 
 func registerContainerEvents(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "container_events",
 		Description: "Get container event history (start/stop/die/restart/OOM etc). Always uses --until to prevent streaming forever.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerEventsArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleContainerEvents(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerEventsArgs) (*mcp.CallToolResult, containerEventsOutput, error) {
+		result, out, err := handleContainerEvents(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
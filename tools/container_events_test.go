@@ -19,7 +19,7 @@ func TestHandleContainerEvents_Basic(t *testing.T) {
 
 	args := containerEventsArgs{}
 
-	result, err := handleContainerEvents(context.Background(), mock, args)
+	result, _, err := handleContainerEvents(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -49,7 +49,7 @@ func TestHandleContainerEvents_FilterByContainer(t *testing.T) {
 		Container: "webapp",
 	}
 
-	result, err := handleContainerEvents(context.Background(), mock, args)
+	result, _, err := handleContainerEvents(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -74,7 +74,7 @@ func TestHandleContainerEvents_FilterByEventType(t *testing.T) {
 		Since:     "24h",
 	}
 
-	result, err := handleContainerEvents(context.Background(), mock, args)
+	result, _, err := handleContainerEvents(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -94,7 +94,7 @@ func TestHandleContainerEvents_NoEvents(t *testing.T) {
 
 	args := containerEventsArgs{}
 
-	result, err := handleContainerEvents(context.Background(), mock, args)
+	result, _, err := handleContainerEvents(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -111,7 +111,7 @@ func TestHandleContainerEvents_DockerError(t *testing.T) {
 
 	args := containerEventsArgs{}
 
-	_, err := handleContainerEvents(context.Background(), mock, args)
+	_, _, err := handleContainerEvents(context.Background(), mock, args)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -132,7 +132,7 @@ func TestHandleContainerEvents_CustomTimeRange(t *testing.T) {
 		Until: "2024-01-02T00:00:00Z",
 	}
 
-	result, err := handleContainerEvents(context.Background(), mock, args)
+	result, _, err := handleContainerEvents(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
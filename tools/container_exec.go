@@ -15,7 +15,14 @@ type containerExecArgs struct {
 	Workdir   string `json:"workdir,omitempty" jsonschema:"working directory inside the container"`
 }
 
-func handleContainerExec(ctx context.Context, exec docker.Executor, args containerExecArgs) (string, error) {
+type containerExecOutput struct {
+	Container string `json:"container"`
+	Command   string `json:"command"`
+	Output    string `json:"output" jsonschema:"combined stdout and stderr of the command"`
+}
+
+func handleContainerExec(ctx context.Context, exec docker.Executor, args containerExecArgs) (string, containerExecOutput, error) {
+	out := containerExecOutput{Container: args.Container, Command: args.Command}
 	dockerArgs := []string{"exec"}
 
 	if args.User != "" {
@@ -29,26 +36,24 @@ func handleContainerExec(ctx context.Context, exec docker.Executor, args contain
 
 	output, err := exec.ExecCombined(ctx, dockerArgs...)
 	if err != nil {
-		return "", fmt.Errorf("exec failed: %w", err)
+		return "", out, fmt.Errorf("exec failed: %w", err)
 	}
 
-	return output, nil
+	out.Output = output
+	return output, out, nil
 }
 
 func registerContainerExec(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "container_exec",
 		Description: "Execute a command inside a running container. The command is run via sh -c, so pipes and redirects are supported.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerExecArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleContainerExec(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerExecArgs) (*mcp.CallToolResult, containerExecOutput, error) {
+		result, out, err := handleContainerExec(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
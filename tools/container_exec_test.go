@@ -14,7 +14,7 @@ func TestHandleContainerExec_BasicCommand(t *testing.T) {
 
 	mock.On("exec mycontainer sh -c ls -la", "total 0\ndrwxr-xr-x 2 root root 40 Jan  1 00:00 .\n", nil)
 
-	result, err := handleContainerExec(context.Background(), mock, containerExecArgs{
+	result, _, err := handleContainerExec(context.Background(), mock, containerExecArgs{
 		Container: "mycontainer",
 		Command:   "ls -la",
 	})
@@ -33,7 +33,7 @@ func TestHandleContainerExec_WithUserAndWorkdir(t *testing.T) {
 
 	mock.On("exec --user www-data --workdir /var/www mycontainer sh -c cat index.html", "<html>hello</html>\n", nil)
 
-	result, err := handleContainerExec(context.Background(), mock, containerExecArgs{
+	result, _, err := handleContainerExec(context.Background(), mock, containerExecArgs{
 		Container: "mycontainer",
 		Command:   "cat index.html",
 		User:      "www-data",
@@ -54,7 +54,7 @@ func TestHandleContainerExec_CommandFails(t *testing.T) {
 
 	mock.On("exec mycontainer sh -c exit 1", "", fmt.Errorf("exit status 1"))
 
-	_, err := handleContainerExec(context.Background(), mock, containerExecArgs{
+	_, _, err := handleContainerExec(context.Background(), mock, containerExecArgs{
 		Container: "mycontainer",
 		Command:   "exit 1",
 	})
@@ -72,7 +72,7 @@ func TestHandleContainerExec_ContainerNotFound(t *testing.T) {
 
 	mock.On("exec nosuchcontainer sh -c echo hello", "", fmt.Errorf("Error: No such container: nosuchcontainer"))
 
-	_, err := handleContainerExec(context.Background(), mock, containerExecArgs{
+	_, _, err := handleContainerExec(context.Background(), mock, containerExecArgs{
 		Container: "nosuchcontainer",
 		Command:   "echo hello",
 	})
@@ -0,0 +1,673 @@
+package tools
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	// fileListLimit caps the entries container_ls returns, and
+	// findDefaultResults and findMaxResults the matches of container_find.
+	fileListLimit      = 1000
+	findDefaultResults = 200
+	findMaxResults     = 1000
+	// findOutputLimit caps how much find output is read from docker exec.
+	findOutputLimit = 4 << 20
+	// archiveSkipLimit caps how much of the docker cp archive a depth
+	// limited listing reads past entries below that depth. The archive
+	// holds the whole subtree, so without it listing / of a stopped
+	// container would read its entire filesystem.
+	archiveSkipLimit = 64 << 20
+	// readDefaultBytes is how much container_read_file returns unless asked
+	// otherwise, readMaxBytes the most it returns in one call, and
+	// readDefaultLines the lines returned from start_line by default.
+	readDefaultBytes = 64 << 10
+	readMaxBytes     = 1 << 20
+	readDefaultLines = 200
+)
+
+// findFormat makes find print each entry as NUL-terminated fields: type,
+// size, permissions in octal, mtime in epoch seconds, symlink target and
+// path. The path comes last so the fields parse even when names contain
+// tabs or newlines.
+const findFormat = `%y\0%s\0%m\0%T@\0%l\0%p\0`
+
+// errStopWalk is returned by a walkContainerArchive callback to stop reading
+// the archive early without an error.
+var errStopWalk = errors.New("stop walking the archive")
+
+// fileEntry describes one file in a container's filesystem.
+type fileEntry struct {
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+	Type    string `json:"type" jsonschema:"file, dir, symlink, hardlink or other"`
+	Size    int64  `json:"size"`
+	Mode    string `json:"mode" jsonschema:"permissions as ls -l shows them"`
+	ModTime string `json:"mtime"`
+	Target  string `json:"link_target,omitempty" jsonschema:"target of a symlink or hardlink"`
+}
+
+// walkContainerArchive streams the tar archive of "docker cp
+// CONTAINER:PATH -" and calls fn for each entry with its path inside the
+// container and its depth below PATH. fn may read the entry's content from
+// body. This works on stopped containers and images without a shell.
+// Returning errStopWalk from fn stops the copy early; any other error is
+// returned as is.
+func walkContainerArchive(ctx context.Context, exec docker.Executor, container, root string, fn func(hdr *tar.Header, p string, depth int, body io.Reader) error) error {
+	pr, pw := io.Pipe()
+	copied := make(chan error, 1)
+	go func() {
+		err := exec.ExecTo(ctx, pw, "cp", container+":"+root, "-")
+		pw.CloseWithError(err)
+		copied <- err
+	}()
+
+	walkErr := func() error {
+		tr := tar.NewReader(pr)
+		first := ""
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read the copied archive: %w", err)
+			}
+			// Names are relative to the first entry, which is root itself.
+			name := path.Clean(hdr.Name)
+			rel := ""
+			if first == "" {
+				first = name
+			} else if first == "." || first == "/" {
+				rel = strings.TrimPrefix(name, "/")
+			} else {
+				rel = strings.TrimPrefix(name, first+"/")
+			}
+			depth := 0
+			if rel != "" {
+				depth = strings.Count(rel, "/") + 1
+			}
+			if err := fn(hdr, path.Join(root, rel), depth, tr); err != nil {
+				return err
+			}
+		}
+	}()
+	// Stop docker if the walk ended before the archive did.
+	pr.CloseWithError(errStopWalk)
+	copyErr := <-copied
+
+	if copyErr != nil && !errors.Is(copyErr, errStopWalk) {
+		return fmt.Errorf("copy failed: %w", copyErr)
+	}
+	if walkErr != nil && !errors.Is(walkErr, errStopWalk) {
+		return walkErr
+	}
+	return nil
+}
+
+// archiveFileEntry describes a tar entry found at p.
+func archiveFileEntry(hdr *tar.Header, p string) fileEntry {
+	e := fileEntry{
+		Path:    p,
+		Name:    path.Base(p),
+		Type:    "other",
+		Size:    hdr.Size,
+		Mode:    hdr.FileInfo().Mode().String(),
+		ModTime: hdr.ModTime.UTC().Format(time.RFC3339),
+	}
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		e.Type = "file"
+	case tar.TypeDir:
+		e.Type = "dir"
+	case tar.TypeSymlink:
+		e.Type, e.Target = "symlink", hdr.Linkname
+	case tar.TypeLink:
+		e.Type, e.Target = "hardlink", hdr.Linkname
+	}
+	return e
+}
+
+// findFilter selects the entries a listing returns.
+type findFilter struct {
+	maxDepth int    // 0 means unlimited
+	name     string // glob matched against the base name
+	typ      string // f, d or l
+}
+
+var findTypes = map[string]string{"f": "file", "d": "dir", "l": "symlink"}
+
+func (f findFilter) match(e fileEntry) bool {
+	if f.typ != "" && e.Type != findTypes[f.typ] {
+		return false
+	}
+	if f.name != "" {
+		if ok, _ := path.Match(f.name, e.Name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAbsPath refuses paths that are not absolute. find takes a path
+// starting with a dash, like -delete, as part of its expression.
+func checkAbsPath(p string) error {
+	if !strings.HasPrefix(p, "/") {
+		return fmt.Errorf("path %q must be absolute", p)
+	}
+	return nil
+}
+
+// findArgs builds the find command line for root and filter. root must be
+// absolute; see checkAbsPath.
+func (f findFilter) findArgs(container, root string) []string {
+	args := []string{"exec", container, "find", root}
+	if f.maxDepth > 0 {
+		args = append(args, "-maxdepth", strconv.Itoa(f.maxDepth))
+	}
+	if f.name != "" {
+		args = append(args, "-name", f.name)
+	}
+	if f.typ != "" {
+		args = append(args, "-type", f.typ)
+	}
+	return append(args, "-printf", findFormat)
+}
+
+// listFiles returns up to limit entries below root that match f, including
+// root itself, and whether more were left out. It runs find inside the
+// container and, when that fails because the container is stopped or has no
+// find, falls back to walking the docker cp archive. source says which was
+// used.
+func listFiles(ctx context.Context, exec docker.Executor, container, root string, f findFilter, limit int) (entries []fileEntry, truncated bool, source string, err error) {
+	entries, truncated, err = listFilesByExec(ctx, exec, container, root, f, limit)
+	if err == nil {
+		return entries, truncated, "exec", nil
+	}
+
+	entries = nil
+	var skipped int64
+	err = walkContainerArchive(ctx, exec, container, root, func(hdr *tar.Header, p string, depth int, body io.Reader) error {
+		if f.maxDepth > 0 && depth > f.maxDepth {
+			// Entries are in walk order, so deeper ones are interleaved with
+			// those listed; count what they cost to read instead.
+			if skipped += hdr.Size + 512; skipped > archiveSkipLimit {
+				truncated = true
+				return errStopWalk
+			}
+			return nil
+		}
+		e := archiveFileEntry(hdr, p)
+		if !f.match(e) {
+			return nil
+		}
+		if len(entries) == limit {
+			truncated = true
+			return errStopWalk
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, truncated, "archive", err
+}
+
+func listFilesByExec(ctx context.Context, exec docker.Executor, container, root string, f findFilter, limit int) ([]fileEntry, bool, error) {
+	buf := &limitedBuffer{limit: findOutputLimit}
+	err := exec.ExecTo(ctx, buf, f.findArgs(container, root)...)
+	truncated := errors.Is(err, errCopyTooLarge)
+	if err != nil && !truncated {
+		return nil, false, err
+	}
+
+	// Each entry is six NUL-terminated fields; drop any cut off by the limit.
+	output := buf.buf.String()
+	output = output[:strings.LastIndexByte(output, 0)+1]
+	fields := strings.Split(output, "\x00")
+	var entries []fileEntry
+	for i := 0; i+6 <= len(fields); i += 6 {
+		if len(entries) == limit {
+			return entries, true, nil
+		}
+		e, err := findFileEntry(fields[i : i+6])
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, truncated, nil
+}
+
+// findFileEntry parses the fields printed for one entry with findFormat.
+func findFileEntry(fields []string) (fileEntry, error) {
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return fileEntry{}, fmt.Errorf("unexpected find output %q", fields)
+	}
+	perm, err := strconv.ParseUint(fields[2], 8, 32)
+	if err != nil {
+		return fileEntry{}, fmt.Errorf("unexpected find output %q", fields)
+	}
+	mtime, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return fileEntry{}, fmt.Errorf("unexpected find output %q", fields)
+	}
+
+	mode := fs.FileMode(perm & 0o777)
+	if perm&0o4000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if perm&0o2000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if perm&0o1000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	e := fileEntry{
+		Path:    fields[5],
+		Name:    path.Base(fields[5]),
+		Type:    "other",
+		Size:    size,
+		ModTime: time.Unix(int64(mtime), 0).UTC().Format(time.RFC3339),
+		Target:  fields[4],
+	}
+	switch fields[0] {
+	case "f":
+		e.Type = "file"
+	case "d":
+		e.Type = "dir"
+		mode |= fs.ModeDir
+	case "l":
+		e.Type = "symlink"
+		mode |= fs.ModeSymlink
+	case "p":
+		mode |= fs.ModeNamedPipe
+	case "s":
+		mode |= fs.ModeSocket
+	case "c":
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case "b":
+		mode |= fs.ModeDevice
+	}
+	e.Mode = mode.String()
+	return e, nil
+}
+
+// formatFileEntries renders entries like ls -l, by base name or full path.
+func formatFileEntries(entries []fileEntry, baseNames bool) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		name := e.Path
+		if baseNames {
+			name = e.Name
+		}
+		if e.Target != "" {
+			name += " -> " + e.Target
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Mode, binarySize(uint64(e.Size)), e.ModTime, name)
+	}
+	w.Flush()
+	return sb.String()
+}
+
+type containerLsArgs struct {
+	Container string `json:"container" jsonschema:"container name or ID"`
+	Path      string `json:"path,omitempty" jsonschema:"absolute path of the directory to list (default /)"`
+}
+
+type containerLsOutput struct {
+	Container string      `json:"container"`
+	Path      string      `json:"path"`
+	Source    string      `json:"source" jsonschema:"exec when read with find inside the container, archive when read from the docker cp stream"`
+	Entry     fileEntry   `json:"entry" jsonschema:"the listed path itself"`
+	Entries   []fileEntry `json:"entries"`
+	Truncated bool        `json:"truncated"`
+}
+
+func handleContainerLs(ctx context.Context, exec docker.Executor, args containerLsArgs) (string, containerLsOutput, error) {
+	out := containerLsOutput{Container: args.Container, Path: args.Path}
+	if args.Container == "" {
+		return "", out, fmt.Errorf("container is required")
+	}
+	if out.Path == "" {
+		out.Path = "/"
+	}
+	if err := checkAbsPath(out.Path); err != nil {
+		return "", out, err
+	}
+
+	// One more than the limit, as the listed path itself comes first.
+	entries, truncated, source, err := listFiles(ctx, exec, args.Container, out.Path, findFilter{maxDepth: 1}, fileListLimit+1)
+	if err != nil {
+		return "", out, err
+	}
+	if len(entries) == 0 {
+		return "", out, fmt.Errorf("%s not found in %s", out.Path, args.Container)
+	}
+	out.Source, out.Truncated = source, truncated
+	out.Entry, out.Entries = entries[0], entries[1:]
+
+	if out.Entry.Type != "dir" {
+		return fmt.Sprintf("%s:%s is not a directory\n%s", args.Container, out.Path, formatFileEntries(entries[:1], false)), out, nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s:%s (%d entries)\n", args.Container, out.Path, len(out.Entries))
+	sb.WriteString(formatFileEntries(out.Entries, true))
+	if out.Truncated {
+		sb.WriteString(truncationNote(len(out.Entries) >= fileListLimit, fmt.Sprintf("truncated at %d entries", fileListLimit)))
+	}
+	return sb.String(), out, nil
+}
+
+// truncationNote explains why a listing was cut short: at its limit, or
+// because listFiles stopped reading the archive past archiveSkipLimit.
+func truncationNote(atLimit bool, limitNote string) string {
+	if atLimit {
+		return "... " + limitNote + "\n"
+	}
+	return fmt.Sprintf("... truncated: stopped reading the docker cp archive after %s of deeper entries; list a subdirectory instead\n", binarySize(archiveSkipLimit))
+}
+
+type containerFindArgs struct {
+	Container  string `json:"container" jsonschema:"container name or ID"`
+	Path       string `json:"path,omitempty" jsonschema:"absolute path of the directory to search (default /)"`
+	Name       string `json:"name,omitempty" jsonschema:"glob matched against file names, e.g. *.log"`
+	Type       string `json:"type,omitempty" jsonschema:"only return files (f), directories (d) or symlinks (l)"`
+	MaxDepth   int    `json:"max_depth,omitempty" jsonschema:"how many levels below path to search (default unlimited)"`
+	MaxResults int    `json:"max_results,omitempty" jsonschema:"maximum matches to return (default 200, max 1000)"`
+}
+
+type containerFindOutput struct {
+	Container string      `json:"container"`
+	Path      string      `json:"path"`
+	Source    string      `json:"source" jsonschema:"exec when searched with find inside the container, archive when read from the docker cp stream"`
+	Matches   []fileEntry `json:"matches"`
+	Truncated bool        `json:"truncated"`
+}
+
+func handleContainerFind(ctx context.Context, exec docker.Executor, args containerFindArgs) (string, containerFindOutput, error) {
+	out := containerFindOutput{Container: args.Container, Path: args.Path}
+	if args.Container == "" {
+		return "", out, fmt.Errorf("container is required")
+	}
+	if out.Path == "" {
+		out.Path = "/"
+	}
+	if err := checkAbsPath(out.Path); err != nil {
+		return "", out, err
+	}
+	if args.Type != "" && findTypes[args.Type] == "" {
+		return "", out, fmt.Errorf("invalid type %q: must be f, d or l", args.Type)
+	}
+	if _, err := path.Match(args.Name, ""); err != nil {
+		return "", out, fmt.Errorf("invalid name pattern %q: %w", args.Name, err)
+	}
+	if args.MaxDepth < 0 {
+		return "", out, fmt.Errorf("max_depth must not be negative")
+	}
+	limit := args.MaxResults
+	if limit <= 0 {
+		limit = findDefaultResults
+	}
+	limit = min(limit, findMaxResults)
+
+	f := findFilter{maxDepth: args.MaxDepth, name: args.Name, typ: args.Type}
+	matches, truncated, source, err := listFiles(ctx, exec, args.Container, out.Path, f, limit)
+	if err != nil {
+		return "", out, err
+	}
+	out.Matches, out.Truncated, out.Source = matches, truncated, source
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d matches under %s:%s\n", len(matches), args.Container, out.Path)
+	sb.WriteString(formatFileEntries(matches, false))
+	if truncated {
+		sb.WriteString(truncationNote(len(matches) == limit, fmt.Sprintf("stopped at %d matches; narrow the search or raise max_results", limit)))
+	}
+	return sb.String(), out, nil
+}
+
+type containerReadFileArgs struct {
+	Container string `json:"container" jsonschema:"container name or ID"`
+	Path      string `json:"path" jsonschema:"path of the file inside the container"`
+	Offset    int64  `json:"offset,omitempty" jsonschema:"byte offset to start reading at"`
+	Length    int64  `json:"length,omitempty" jsonschema:"bytes to read (default 64KiB, max 1MiB)"`
+	StartLine int    `json:"start_line,omitempty" jsonschema:"first line to read, counting from 1; selects a line range instead of a byte range"`
+	EndLine   int    `json:"end_line,omitempty" jsonschema:"last line to read (default start_line+199)"`
+}
+
+type containerReadFileOutput struct {
+	Container string `json:"container"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Mode      string `json:"mode"`
+	ModTime   string `json:"mtime"`
+	Offset    int64  `json:"offset" jsonschema:"byte offset of the returned content"`
+	Length    int64  `json:"length" jsonschema:"bytes returned"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty" jsonschema:"last line returned"`
+	EOF       bool   `json:"eof" jsonschema:"whether the content reaches the end of the file"`
+	Text      bool   `json:"text"`
+	Content   string `json:"content,omitempty" jsonschema:"the content read, when it is text"`
+}
+
+func handleContainerReadFile(ctx context.Context, exec docker.Executor, args containerReadFileArgs) ([]mcp.Content, containerReadFileOutput, error) {
+	out := containerReadFileOutput{Container: args.Container, Path: args.Path}
+	if args.Container == "" || args.Path == "" {
+		return nil, out, fmt.Errorf("container and path are required")
+	}
+	lines := args.StartLine > 0 || args.EndLine > 0
+	switch {
+	case args.Offset < 0 || args.Length < 0 || args.Length > readMaxBytes:
+		return nil, out, fmt.Errorf("offset must not be negative and length must be between 1 and %d", readMaxBytes)
+	case lines && (args.Offset > 0 || args.Length > 0):
+		return nil, out, fmt.Errorf("use either a byte range (offset, length) or a line range (start_line, end_line), not both")
+	case args.StartLine < 0 || args.EndLine < 0 || args.EndLine > 0 && args.EndLine < args.StartLine:
+		return nil, out, fmt.Errorf("invalid line range %d-%d", args.StartLine, args.EndLine)
+	}
+
+	var data []byte
+	found := false
+	err := walkContainerArchive(ctx, exec, args.Container, args.Path, func(hdr *tar.Header, p string, depth int, body io.Reader) error {
+		if err := regularFile(hdr, args.Path); err != nil {
+			return err
+		}
+		found = true
+		out.Size = hdr.Size
+		out.Mode = hdr.FileInfo().Mode().String()
+		out.ModTime = hdr.ModTime.UTC().Format(time.RFC3339)
+
+		var err error
+		if lines {
+			data, err = readLines(body, args, &out)
+		} else {
+			data, err = readBytes(body, args, &out)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args.Path, err)
+		}
+		return errStopWalk
+	})
+	if err != nil {
+		return nil, out, err
+	}
+	if !found {
+		return nil, out, fmt.Errorf("%s not found in %s", args.Path, args.Container)
+	}
+	out.Length = int64(len(data))
+	out.Text = isText(data)
+
+	header := fmt.Sprintf("%s:%s (%s, %s, modified %s) bytes %d-%d", args.Container, args.Path, binarySize(uint64(out.Size)), out.Mode, out.ModTime, out.Offset, out.Offset+out.Length)
+	if lines {
+		header = fmt.Sprintf("%s:%s (%s, %s, modified %s) lines %d-%d", args.Container, args.Path, binarySize(uint64(out.Size)), out.Mode, out.ModTime, out.StartLine, out.EndLine)
+	}
+	if out.EOF {
+		header += ", end of file"
+	}
+	if !out.Text {
+		if lines {
+			return nil, out, fmt.Errorf("%s is not a text file; read it with a byte range", args.Path)
+		}
+		return []mcp.Content{
+			&mcp.TextContent{Text: header + "\nBinary content returned as an embedded resource."},
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+				URI:      containerFileURI(args.Container, args.Path),
+				MIMEType: "application/octet-stream",
+				Blob:     data,
+			}},
+		}, out, nil
+	}
+	out.Content = string(data)
+	return []mcp.Content{&mcp.TextContent{Text: header + "\n\n" + out.Content}}, out, nil
+}
+
+// regularFile checks that hdr, the first entry copied from file, is a
+// regular file.
+func regularFile(hdr *tar.Header, file string) error {
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		return nil
+	case tar.TypeDir:
+		return fmt.Errorf("%s is a directory; list it with container_ls", file)
+	case tar.TypeSymlink:
+		return fmt.Errorf("%s is a symlink to %s; use the target instead", file, hdr.Linkname)
+	default:
+		return fmt.Errorf("%s is not a regular file", file)
+	}
+}
+
+// readBytes reads the byte range of args from body, which holds a file of
+// out.Size bytes.
+func readBytes(body io.Reader, args containerReadFileArgs, out *containerReadFileOutput) ([]byte, error) {
+	length := args.Length
+	if length == 0 {
+		length = readDefaultBytes
+	}
+	out.Offset = min(args.Offset, out.Size)
+	if _, err := io.CopyN(io.Discard, body, out.Offset); err != nil {
+		return nil, err
+	}
+	data := make([]byte, min(length, out.Size-out.Offset))
+	if _, err := io.ReadFull(body, data); err != nil {
+		return nil, err
+	}
+	out.EOF = out.Offset+int64(len(data)) == out.Size
+	return data, nil
+}
+
+// readLines reads the line range of args from body, up to readMaxBytes.
+func readLines(body io.Reader, args containerReadFileArgs, out *containerReadFileOutput) ([]byte, error) {
+	start := max(args.StartLine, 1)
+	end := args.EndLine
+	if end == 0 {
+		end = start + readDefaultLines - 1
+	}
+	out.StartLine = start
+
+	r := bufio.NewReader(body)
+	var data []byte
+	var offset int64
+	for n := 1; n <= end; n++ {
+		// Read the line in buffer-sized pieces, so a huge line or a file
+		// without newlines is never held in memory whole: skipped lines are
+		// only counted, and a line in the range is kept only while it fits.
+		var line []byte
+		var size int64
+		fits := true
+		var err error
+		for {
+			var piece []byte
+			piece, err = r.ReadSlice('\n')
+			size += int64(len(piece))
+			if n >= start {
+				if len(data)+len(line)+len(piece) > readMaxBytes {
+					fits = false
+					break
+				}
+				line = append(line, piece...)
+			}
+			if err != bufio.ErrBufferFull {
+				break
+			}
+		}
+		if !fits {
+			break
+		}
+		if n < start {
+			offset += size
+		} else if size > 0 {
+			data = append(data, line...)
+			out.EndLine = n
+		}
+		if err == io.EOF {
+			out.EOF = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !out.EOF {
+		// The range ended exactly at the end of the file if nothing follows.
+		if _, err := r.Peek(1); err == io.EOF {
+			out.EOF = true
+		}
+	}
+	if out.EndLine == 0 {
+		out.EndLine = start - 1
+	}
+	out.Offset = offset
+	return data, nil
+}
+
+func registerContainerFiles(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "container_ls",
+		Description: "List a directory inside a container with name, type, size, mode, mtime and symlink target for each entry. Uses find inside the container, or reads the docker cp archive for stopped containers and images without a shell.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerLsArgs) (*mcp.CallToolResult, containerLsOutput, error) {
+		result, out, err := handleContainerLs(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "container_find",
+		Description: "Search a container's filesystem by name glob, type and depth, returning structured entries. Uses find inside the container, or reads the docker cp archive for stopped containers and images without a shell.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerFindArgs) (*mcp.CallToolResult, containerFindOutput, error) {
+		result, out, err := handleContainerFind(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "container_read_file",
+		Description: "Read part of a file inside a container by byte range (offset, length) or line range (start_line, end_line), for large logs and configs. Reads the docker cp stream, so it works on stopped containers and images without a shell. Binary content is returned as an embedded base64 resource.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerReadFileArgs) (*mcp.CallToolResult, containerReadFileOutput, error) {
+		content, out, err := handleContainerReadFile(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{Content: content}, out, nil
+	})
+}
@@ -0,0 +1,352 @@
+package tools
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// findKey is the mock key of the find command listFiles runs.
+func findKey(container, root string, f findFilter) string {
+	return strings.Join(f.findArgs(container, root), " ")
+}
+
+// findRecord renders one entry as find prints it with findFormat.
+func findRecord(typ, size, perm, mtime, target, p string) string {
+	return strings.Join([]string{typ, size, perm, mtime, target, p}, "\x00") + "\x00"
+}
+
+// etcArchive is what docker cp streams for /etc.
+func etcArchive(t *testing.T) string {
+	return tarFixture(t,
+		tarEntry{name: "etc/", typeflag: tar.TypeDir, mode: 0o755},
+		tarEntry{name: "etc/hosts", typeflag: tar.TypeReg, mode: 0o644, body: "127.0.0.1 localhost\n"},
+		tarEntry{name: "etc/ssl/", typeflag: tar.TypeDir, mode: 0o755},
+		tarEntry{name: "etc/ssl/cert.pem", typeflag: tar.TypeReg, mode: 0o644, body: "-----BEGIN CERTIFICATE-----\n"},
+		tarEntry{name: "etc/localtime", typeflag: tar.TypeSymlink, linkname: "/usr/share/zoneinfo/UTC"},
+	)
+}
+
+func TestHandleContainerLs_Exec(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(findKey("web", "/etc", findFilter{maxDepth: 1}), findRecord("d", "4096", "755", "1704067200.5", "", "/etc")+
+		findRecord("f", "20", "644", "1704067200.0", "", "/etc/hosts")+
+		findRecord("l", "23", "777", "1704067200.0", "/usr/share/zoneinfo/UTC", "/etc/localtime")+
+		findRecord("f", "0", "4755", "1704067200.0", "", "/etc/su\nid"), nil)
+
+	result, out, err := handleContainerLs(context.Background(), mock, containerLsArgs{Container: "web", Path: "/etc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Source != "exec" || out.Entry.Type != "dir" || out.Entry.Mode != "drwxr-xr-x" || len(out.Entries) != 3 {
+		t.Fatalf("unexpected output %+v", out)
+	}
+	hosts, link, suid := out.Entries[0], out.Entries[1], out.Entries[2]
+	if hosts.Name != "hosts" || hosts.Size != 20 || hosts.Mode != "-rw-r--r--" || hosts.ModTime != "2024-01-01T00:00:00Z" {
+		t.Errorf("unexpected file entry %+v", hosts)
+	}
+	if link.Type != "symlink" || link.Target != "/usr/share/zoneinfo/UTC" || link.Mode != "Lrwxrwxrwx" {
+		t.Errorf("unexpected symlink entry %+v", link)
+	}
+	if suid.Name != "su\nid" || suid.Mode != "urwxr-xr-x" {
+		t.Errorf("unexpected setuid entry %+v", suid)
+	}
+	for _, want := range []string{"web:/etc (3 entries)", "-rw-r--r--  20B", "localtime -> /usr/share/zoneinfo/UTC"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleContainerLs_FallsBackToArchive(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(findKey("distroless", "/etc", findFilter{maxDepth: 1}), "", fmt.Errorf(`OCI runtime exec failed: exec: "find": executable file not found in $PATH`))
+	mock.On("cp distroless:/etc -", etcArchive(t), nil)
+
+	_, out, err := handleContainerLs(context.Background(), mock, containerLsArgs{Container: "distroless", Path: "/etc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Source != "archive" || out.Entry.Path != "/etc" {
+		t.Errorf("unexpected output %+v", out)
+	}
+	var names []string
+	for _, e := range out.Entries {
+		names = append(names, e.Path+":"+e.Type)
+	}
+	if got := strings.Join(names, ","); got != "/etc/hosts:file,/etc/ssl:dir,/etc/localtime:symlink" {
+		t.Errorf("expected only the first level, got %s", got)
+	}
+	if out.Entries[2].Target != "/usr/share/zoneinfo/UTC" {
+		t.Errorf("expected the symlink target, got %+v", out.Entries[2])
+	}
+}
+
+func TestHandleContainerLs_ArchiveSkipLimit(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(findKey("distroless", "/", findFilter{maxDepth: 1}), "", fmt.Errorf("no find"))
+	mock.On("cp distroless:/ -", tarFixture(t,
+		tarEntry{name: "./", typeflag: tar.TypeDir, mode: 0o755},
+		tarEntry{name: "./bin/", typeflag: tar.TypeDir, mode: 0o755},
+		tarEntry{name: "./bin/app", typeflag: tar.TypeReg, mode: 0o755, body: strings.Repeat("x", archiveSkipLimit)},
+		tarEntry{name: "./etc/", typeflag: tar.TypeDir, mode: 0o755},
+	), nil)
+
+	result, out, err := handleContainerLs(context.Background(), mock, containerLsArgs{Container: "distroless"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !out.Truncated || len(out.Entries) != 1 || out.Entries[0].Path != "/bin" {
+		t.Errorf("expected the listing to stop after the deep file, got %+v", out)
+	}
+	if !strings.Contains(result, "stopped reading the docker cp archive after 64MiB") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+}
+
+func TestHandleContainerLs_Errors(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(findKey("web", "/nope", findFilter{maxDepth: 1}), "", fmt.Errorf("find: '/nope': No such file or directory"))
+	mock.On("cp web:/nope -", "", fmt.Errorf("Error: Could not find the file /nope in container web"))
+	mock.On(findKey("web", "/etc/hosts", findFilter{maxDepth: 1}), findRecord("f", "20", "644", "1704067200", "", "/etc/hosts"), nil)
+
+	if _, _, err := handleContainerLs(context.Background(), mock, containerLsArgs{}); err == nil {
+		t.Error("expected error for missing container")
+	}
+	if _, _, err := handleContainerLs(context.Background(), mock, containerLsArgs{Container: "web", Path: "-delete"}); err == nil || !strings.Contains(err.Error(), "must be absolute") {
+		t.Errorf("expected a dash-prefixed path to be refused, got %v", err)
+	}
+	if len(mock.Calls()) != 0 {
+		t.Errorf("expected no docker calls, got %v", mock.Calls())
+	}
+	if _, _, err := handleContainerLs(context.Background(), mock, containerLsArgs{Container: "web", Path: "/nope"}); err == nil || !strings.Contains(err.Error(), "Could not find the file") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	result, out, err := handleContainerLs(context.Background(), mock, containerLsArgs{Container: "web", Path: "/etc/hosts"})
+	if err != nil || out.Entry.Type != "file" || len(out.Entries) != 0 || !strings.Contains(result, "is not a directory") {
+		t.Errorf("expected the file itself, got %q %+v %v", result, out, err)
+	}
+}
+
+func TestHandleContainerFind(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(findKey("web", "/etc", findFilter{name: "*.pem", typ: "f"}), findRecord("f", "28", "644", "1704067200", "", "/etc/ssl/cert.pem"), nil)
+	mock.On(findKey("distroless", "/etc", findFilter{name: "*.pem", typ: "f"}), "", fmt.Errorf("container distroless is not running"))
+	mock.On("cp distroless:/etc -", etcArchive(t), nil)
+
+	for _, container := range []string{"web", "distroless"} {
+		result, out, err := handleContainerFind(context.Background(), mock, containerFindArgs{Container: container, Path: "/etc", Name: "*.pem", Type: "f"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", container, err)
+		}
+		if len(out.Matches) != 1 || out.Matches[0].Path != "/etc/ssl/cert.pem" || out.Matches[0].Size != 28 || out.Truncated {
+			t.Errorf("%s: unexpected matches %+v", container, out)
+		}
+		if !strings.Contains(result, "1 matches under "+container+":/etc") {
+			t.Errorf("%s: unexpected result:\n%s", container, result)
+		}
+	}
+}
+
+func TestHandleContainerFind_ArchiveLimits(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(findKey("distroless", "/etc", findFilter{maxDepth: 1}), "", fmt.Errorf("no find"))
+	mock.On(findKey("distroless", "/etc", findFilter{}), "", fmt.Errorf("no find"))
+	mock.On("cp distroless:/etc -", etcArchive(t), nil)
+
+	_, out, err := handleContainerFind(context.Background(), mock, containerFindArgs{Container: "distroless", Path: "/etc", MaxDepth: 1})
+	if err != nil || len(out.Matches) != 4 {
+		t.Errorf("expected /etc and its 3 children, got %+v %v", out.Matches, err)
+	}
+	result, out, err := handleContainerFind(context.Background(), mock, containerFindArgs{Container: "distroless", Path: "/etc", MaxResults: 2})
+	if err != nil || len(out.Matches) != 2 || !out.Truncated || !strings.Contains(result, "stopped at 2 matches") {
+		t.Errorf("expected truncation at 2 matches, got %+v %v", out, err)
+	}
+}
+
+func TestHandleContainerFind_Errors(t *testing.T) {
+	for _, args := range []containerFindArgs{
+		{},
+		{Container: "web", Type: "x"},
+		{Container: "web", Name: "[bad"},
+		{Container: "web", MaxDepth: -1},
+		{Container: "web", Path: "-delete"},
+		{Container: "web", Path: "etc"},
+	} {
+		mock := docker.NewMock()
+		if _, _, err := handleContainerFind(context.Background(), mock, args); err == nil {
+			t.Errorf("expected error for %+v", args)
+		}
+		if len(mock.Calls()) != 0 {
+			t.Errorf("expected no docker calls for %+v, got %v", args, mock.Calls())
+		}
+	}
+}
+
+func TestListFilesByExec_OutputLimit(t *testing.T) {
+	mock := docker.NewMock()
+	record := findRecord("f", "1", "644", "1704067200", "", "/data/"+strings.Repeat("x", 1000))
+	n := findOutputLimit/len(record) + 10
+	mock.On(findKey("web", "/data", findFilter{}), strings.Repeat(record, n), nil)
+
+	entries, truncated, err := listFilesByExec(context.Background(), mock, "web", "/data", findFilter{}, n)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !truncated || len(entries) == 0 || len(entries) >= n {
+		t.Errorf("expected output cut at the limit, got %d entries, truncated %v", len(entries), truncated)
+	}
+}
+
+func TestHandleContainerReadFile_ByteRange(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("cp web:/var/log/app.log -", tarFixture(t, tarEntry{name: "app.log", typeflag: tar.TypeReg, mode: 0o644, body: "0123456789abcdef"}), nil)
+
+	tests := []struct {
+		name    string
+		args    containerReadFileArgs
+		content string
+		eof     bool
+	}{
+		{"default", containerReadFileArgs{}, "0123456789abcdef", true},
+		{"middle", containerReadFileArgs{Offset: 4, Length: 6}, "456789", false},
+		{"tail", containerReadFileArgs{Offset: 10, Length: 100}, "abcdef", true},
+		{"past end", containerReadFileArgs{Offset: 100}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args.Container, tt.args.Path = "web", "/var/log/app.log"
+			content, out, err := handleContainerReadFile(context.Background(), mock, tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.Content != tt.content || out.EOF != tt.eof || out.Size != 16 || out.Length != int64(len(tt.content)) {
+				t.Errorf("unexpected output %+v", out)
+			}
+			if text := content[0].(*mcp.TextContent).Text; !strings.HasSuffix(text, "\n\n"+tt.content) {
+				t.Errorf("unexpected text %q", text)
+			}
+		})
+	}
+}
+
+func TestHandleContainerReadFile_LineRange(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("cp web:/app/config.yaml -", tarFixture(t, tarEntry{name: "config.yaml", typeflag: tar.TypeReg, mode: 0o644, body: "a\nbb\nccc\ndddd\n"}), nil)
+
+	tests := []struct {
+		name       string
+		start, end int
+		content    string
+		offset     int64
+		endLine    int
+		eof        bool
+	}{
+		{"middle", 2, 3, "bb\nccc\n", 2, 3, false},
+		{"to end", 3, 0, "ccc\ndddd\n", 5, 4, true},
+		{"exactly last", 4, 4, "dddd\n", 9, 4, true},
+		{"past end", 10, 12, "", 14, 9, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out, err := handleContainerReadFile(context.Background(), mock, containerReadFileArgs{Container: "web", Path: "/app/config.yaml", StartLine: tt.start, EndLine: tt.end})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.Content != tt.content || out.Offset != tt.offset || out.StartLine != tt.start || out.EndLine != tt.endLine || out.EOF != tt.eof {
+				t.Errorf("unexpected output %+v", out)
+			}
+		})
+	}
+}
+
+func TestReadLines_LongLines(t *testing.T) {
+	huge := 3 * readMaxBytes
+	body := func() io.Reader {
+		return io.MultiReader(io.LimitReader(zeroReader{}, int64(huge)), strings.NewReader("\nshort\n"))
+	}
+
+	var out containerReadFileOutput
+	data, err := readLines(body(), containerReadFileArgs{StartLine: 2}, &out)
+	if err != nil || string(data) != "short\n" || out.Offset != int64(huge)+1 || out.EndLine != 2 || !out.EOF {
+		t.Errorf("expected the line after the skipped one, got %q %+v %v", data, out, err)
+	}
+
+	out = containerReadFileOutput{}
+	r := &countingReader{r: body()}
+	data, err = readLines(r, containerReadFileArgs{StartLine: 1}, &out)
+	if err != nil || len(data) != 0 || out.EndLine != 0 || out.EOF {
+		t.Errorf("expected a line over readMaxBytes to be left out, got %d bytes %+v %v", len(data), out, err)
+	}
+	if r.n > readMaxBytes+64<<10 {
+		t.Errorf("expected reading to stop near readMaxBytes, read %d bytes", r.n)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestHandleContainerReadFile_Binary(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("cp web:/app/data.bin -", tarFixture(t, tarEntry{name: "data.bin", typeflag: tar.TypeReg, mode: 0o644, body: "\x00\x01\x02\x03"}), nil)
+
+	content, out, err := handleContainerReadFile(context.Background(), mock, containerReadFileArgs{Container: "web", Path: "/app/data.bin", Offset: 1, Length: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Text || out.Content != "" || len(content) != 2 {
+		t.Fatalf("unexpected output %+v %v", out, content)
+	}
+	if res := content[1].(*mcp.EmbeddedResource); string(res.Resource.Blob) != "\x01\x02" {
+		t.Errorf("unexpected blob %q", res.Resource.Blob)
+	}
+	if _, _, err := handleContainerReadFile(context.Background(), mock, containerReadFileArgs{Container: "web", Path: "/app/data.bin", StartLine: 1}); err == nil || !strings.Contains(err.Error(), "byte range") {
+		t.Errorf("expected a line range on a binary file to be refused, got %v", err)
+	}
+}
+
+func TestHandleContainerReadFile_Errors(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("cp web:/etc -", etcArchive(t), nil)
+
+	tests := []struct {
+		name string
+		args containerReadFileArgs
+		want string
+	}{
+		{"missing path", containerReadFileArgs{Container: "web"}, "required"},
+		{"both ranges", containerReadFileArgs{Container: "web", Path: "/x", Offset: 1, StartLine: 1}, "not both"},
+		{"bad length", containerReadFileArgs{Container: "web", Path: "/x", Length: readMaxBytes + 1}, "length"},
+		{"bad lines", containerReadFileArgs{Container: "web", Path: "/x", StartLine: 5, EndLine: 2}, "invalid line range"},
+		{"directory", containerReadFileArgs{Container: "web", Path: "/etc"}, "container_ls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := handleContainerReadFile(context.Background(), mock, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/otsukatsuka/orbstack-mcp/docker"
@@ -34,21 +35,54 @@ type healthConfig struct {
 	Retries  int      `json:"Retries"`
 }
 
-func handleContainerHealth(ctx context.Context, exec docker.Executor, args containerHealthArgs) (string, error) {
+type containerHealthOutput struct {
+	Container       string              `json:"container"`
+	Configured      bool                `json:"configured" jsonschema:"whether the container has a healthcheck"`
+	Status          string              `json:"status,omitempty" jsonschema:"starting, healthy or unhealthy"`
+	FailingStreak   int                 `json:"failing_streak"`
+	Test            []string            `json:"test,omitempty"`
+	IntervalSeconds float64             `json:"interval_seconds,omitempty"`
+	TimeoutSeconds  float64             `json:"timeout_seconds,omitempty"`
+	Retries         int                 `json:"retries,omitempty"`
+	Log             []healthCheckResult `json:"log" jsonschema:"recent health check results, oldest first"`
+}
+
+type healthCheckResult struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	ExitCode int       `json:"exit_code"`
+	Output   string    `json:"output"`
+}
+
+// newHealthCheckResult parses the timestamps of a raw health log entry.
+// Timestamps that fail to parse are left as the zero time.
+func newHealthCheckResult(entry healthLog) healthCheckResult {
+	start, _ := time.Parse(time.RFC3339Nano, entry.Start)
+	end, _ := time.Parse(time.RFC3339Nano, entry.End)
+	return healthCheckResult{
+		Start:    start,
+		End:      end,
+		ExitCode: entry.ExitCode,
+		Output:   strings.TrimSpace(entry.Output),
+	}
+}
+
+func handleContainerHealth(ctx context.Context, exec docker.Executor, args containerHealthArgs) (string, containerHealthOutput, error) {
+	out := containerHealthOutput{Container: args.Container}
 	if args.Container == "" {
-		return "", fmt.Errorf("container name or ID is required")
+		return "", out, fmt.Errorf("container name or ID is required")
 	}
 
 	// Get health state
 	healthOut, err := exec.Exec(ctx, "inspect", "--format", "{{json .State.Health}}", args.Container)
 	if err != nil {
-		return "", fmt.Errorf("failed to get health state for container %q: %w", args.Container, err)
+		return "", out, fmt.Errorf("failed to get health state for container %q: %w", args.Container, err)
 	}
 
 	// Get health config
 	configOut, err := exec.Exec(ctx, "inspect", "--format", "{{json .Config.Healthcheck}}", args.Container)
 	if err != nil {
-		return "", fmt.Errorf("failed to get health config for container %q: %w", args.Container, err)
+		return "", out, fmt.Errorf("failed to get health config for container %q: %w", args.Container, err)
 	}
 
 	healthOut = strings.TrimSpace(healthOut)
@@ -57,7 +91,7 @@ func handleContainerHealth(ctx context.Context, exec docker.Executor, args conta
 	// Check if no healthcheck is configured
 	if (healthOut == "" || healthOut == "null" || healthOut == "<nil>" || healthOut == "<no value>") &&
 		(configOut == "" || configOut == "null" || configOut == "<nil>" || configOut == "<no value>") {
-		return "No healthcheck configured for this container.", nil
+		return "No healthcheck configured for this container.", out, nil
 	}
 
 	var b strings.Builder
@@ -66,6 +100,11 @@ func handleContainerHealth(ctx context.Context, exec docker.Executor, args conta
 	if configOut != "" && configOut != "null" && configOut != "<nil>" && configOut != "<no value>" {
 		var cfg healthConfig
 		if err := json.Unmarshal([]byte(configOut), &cfg); err == nil {
+			out.Configured = true
+			out.Test = cfg.Test
+			out.IntervalSeconds = time.Duration(cfg.Interval).Seconds()
+			out.TimeoutSeconds = time.Duration(cfg.Timeout).Seconds()
+			out.Retries = cfg.Retries
 			b.WriteString("Health Check Configuration:\n")
 			if len(cfg.Test) > 0 {
 				b.WriteString(fmt.Sprintf("  Test:     %s\n", strings.Join(cfg.Test, " ")))
@@ -87,6 +126,12 @@ func handleContainerHealth(ctx context.Context, exec docker.Executor, args conta
 	if healthOut != "" && healthOut != "null" && healthOut != "<nil>" && healthOut != "<no value>" {
 		var state healthState
 		if err := json.Unmarshal([]byte(healthOut), &state); err == nil {
+			out.Configured = true
+			out.Status = state.Status
+			out.FailingStreak = state.FailingStreak
+			for _, entry := range state.Log {
+				out.Log = append(out.Log, newHealthCheckResult(entry))
+			}
 			b.WriteString(fmt.Sprintf("Current Status: %s\n", state.Status))
 			b.WriteString(fmt.Sprintf("Failing Streak: %d\n", state.FailingStreak))
 
@@ -106,10 +151,10 @@ func handleContainerHealth(ctx context.Context, exec docker.Executor, args conta
 
 	result := b.String()
 	if result == "" {
-		return "No healthcheck configured for this container.", nil
+		return "No healthcheck configured for this container.", out, nil
 	}
 
-	return result, nil
+	return result, out, nil
 }
 
 // formatNanoseconds converts nanoseconds to a human-readable duration string.
@@ -130,16 +175,13 @@ func registerContainerHealth(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "container_health",
 		Description: "Get health check configuration and status for a container, including recent check results and failing streak.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerHealthArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleContainerHealth(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerHealthArgs) (*mcp.CallToolResult, containerHealthOutput, error) {
+		result, out, err := handleContainerHealth(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/otsukatsuka/orbstack-mcp/docker"
 )
@@ -19,7 +20,7 @@ func TestHandleContainerHealth_Healthy(t *testing.T) {
 	mock.On("inspect --format {{json .Config.Healthcheck}} myapp", configJSON, nil)
 
 	args := containerHealthArgs{Container: "myapp"}
-	result, err := handleContainerHealth(context.Background(), mock, args)
+	result, out, err := handleContainerHealth(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -51,6 +52,17 @@ func TestHandleContainerHealth_Healthy(t *testing.T) {
 	if !strings.Contains(result, "Exit Code: 0") {
 		t.Errorf("expected 'Exit Code: 0', got:\n%s", result)
 	}
+
+	if !out.Configured || out.Status != "healthy" || out.IntervalSeconds != 30 || out.Retries != 3 {
+		t.Errorf("unexpected structured output: %+v", out)
+	}
+	if len(out.Log) != 2 {
+		t.Fatalf("expected 2 structured log entries, got %d", len(out.Log))
+	}
+	wantStart := time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC)
+	if !out.Log[1].Start.Equal(wantStart) || out.Log[1].End.Sub(out.Log[1].Start) != time.Second {
+		t.Errorf("expected parsed check times starting at %v, got %+v", wantStart, out.Log[1])
+	}
 }
 
 func TestHandleContainerHealth_Unhealthy(t *testing.T) {
@@ -63,7 +75,7 @@ func TestHandleContainerHealth_Unhealthy(t *testing.T) {
 	mock.On("inspect --format {{json .Config.Healthcheck}} sickapp", configJSON, nil)
 
 	args := containerHealthArgs{Container: "sickapp"}
-	result, err := handleContainerHealth(context.Background(), mock, args)
+	result, _, err := handleContainerHealth(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -89,7 +101,7 @@ func TestHandleContainerHealth_NoHealthcheck(t *testing.T) {
 	mock.On("inspect --format {{json .Config.Healthcheck}} nocheck", "null", nil)
 
 	args := containerHealthArgs{Container: "nocheck"}
-	result, err := handleContainerHealth(context.Background(), mock, args)
+	result, _, err := handleContainerHealth(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -105,7 +117,7 @@ func TestHandleContainerHealth_ContainerNotFound(t *testing.T) {
 	mock.On("inspect --format {{json .State.Health}} nosuchcontainer", "", fmt.Errorf("Error: No such container: nosuchcontainer"))
 
 	args := containerHealthArgs{Container: "nosuchcontainer"}
-	_, err := handleContainerHealth(context.Background(), mock, args)
+	_, _, err := handleContainerHealth(context.Background(), mock, args)
 	if err == nil {
 		t.Fatal("expected an error, got nil")
 	}
@@ -119,7 +131,7 @@ func TestHandleContainerHealth_EmptyContainer(t *testing.T) {
 	mock := docker.NewMock()
 
 	args := containerHealthArgs{Container: ""}
-	_, err := handleContainerHealth(context.Background(), mock, args)
+	_, _, err := handleContainerHealth(context.Background(), mock, args)
 	if err == nil {
 		t.Fatal("expected an error for empty container, got nil")
 	}
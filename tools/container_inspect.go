@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -15,46 +16,96 @@ type containerInspectArgs struct {
 	Section   string `json:"section" jsonschema:"section to display: env ports volumes network all (default: all)"`
 }
 
-func handleContainerInspect(ctx context.Context, exec docker.Executor, args containerInspectArgs) (string, error) {
+type containerInspectOutput struct {
+	Container string           `json:"container"`
+	Section   string           `json:"section"`
+	Env       []string         `json:"env,omitempty" jsonschema:"environment variables as KEY=value (section env)"`
+	Ports     *inspectPorts    `json:"ports,omitempty" jsonschema:"port bindings and published ports (section ports)"`
+	Mounts    []inspectMount   `json:"mounts,omitempty" jsonschema:"mounts (section volumes)"`
+	Networks  []inspectNetwork `json:"networks,omitempty" jsonschema:"attached networks (section network)"`
+	Inspect   map[string]any   `json:"inspect,omitempty" jsonschema:"full docker inspect object (section all)"`
+	Exit      *exitExplanation `json:"exit,omitempty" jsonschema:"why the container stopped, when it is not running (section all)"`
+}
+
+type inspectPorts struct {
+	Bindings []inspectPort `json:"bindings" jsonschema:"configured HostConfig.PortBindings"`
+	Exposed  []inspectPort `json:"exposed" jsonschema:"NetworkSettings.Ports; host fields are empty for unmapped ports"`
+}
+
+type inspectPort struct {
+	ContainerPort string `json:"container_port" jsonschema:"port and protocol, e.g. 80/tcp"`
+	HostIP        string `json:"host_ip,omitempty"`
+	HostPort      string `json:"host_port,omitempty"`
+}
+
+type inspectMount struct {
+	Type        string `json:"type"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	RW          bool   `json:"rw"`
+}
+
+type inspectNetwork struct {
+	Name       string `json:"name"`
+	IPAddress  string `json:"ip_address,omitempty"`
+	Gateway    string `json:"gateway,omitempty"`
+	MacAddress string `json:"mac_address,omitempty"`
+}
+
+func handleContainerInspect(ctx context.Context, exec docker.Executor, args containerInspectArgs) (string, containerInspectOutput, error) {
+	result := containerInspectOutput{Container: args.Container}
 	if args.Container == "" {
-		return "", fmt.Errorf("container name or ID is required")
+		return "", result, fmt.Errorf("container name or ID is required")
 	}
 
 	section := args.Section
 	if section == "" {
 		section = "all"
 	}
+	result.Section = section
 
 	out, err := exec.Exec(ctx, "inspect", args.Container)
 	if err != nil {
-		return "", fmt.Errorf("failed to inspect container %q: %w", args.Container, err)
+		return "", result, fmt.Errorf("failed to inspect container %q: %w", args.Container, err)
 	}
 
 	// docker inspect returns a JSON array
 	var inspectData []map[string]any
 	if err := json.Unmarshal([]byte(out), &inspectData); err != nil {
-		return "", fmt.Errorf("failed to parse inspect JSON: %w", err)
+		return "", result, fmt.Errorf("failed to parse inspect JSON: %w", err)
 	}
 
 	if len(inspectData) == 0 {
-		return "", fmt.Errorf("no inspect data returned for container %s", args.Container)
+		return "", result, fmt.Errorf("no inspect data returned for container %s", args.Container)
 	}
 
 	data := inspectData[0]
 
 	switch section {
 	case "env":
-		return formatEnvSection(data)
+		result.Env = inspectEnv(data)
+		return formatEnvSection(result.Env), result, nil
 	case "ports":
-		return formatPortsSection(data)
+		result.Ports = inspectPortMappings(data)
+		return formatPortsSection(result.Ports), result, nil
 	case "volumes":
-		return formatVolumesSection(data)
+		result.Mounts = inspectMounts(data)
+		return formatVolumesSection(result.Mounts), result, nil
 	case "network":
-		return formatNetworkSection(data)
+		result.Networks = inspectNetworks(data)
+		return formatNetworkSection(result.Networks), result, nil
 	case "all":
-		return formatAllSection(out)
+		result.Inspect = data
+		text := formatAllSection(out)
+		var details []containerDetails
+		if json.Unmarshal([]byte(out), &details) == nil && len(details) > 0 {
+			if result.Exit = explainExit(&details[0]); result.Exit != nil {
+				text = formatExitExplanation(args.Container, result.Exit) + "\n" + text
+			}
+		}
+		return text, result, nil
 	default:
-		return "", fmt.Errorf("unknown section %q: must be one of env, ports, volumes, network, all", section)
+		return "", result, fmt.Errorf("unknown section %q: must be one of env, ports, volumes, network, all", section)
 	}
 }
 
@@ -74,183 +125,218 @@ func getNestedMap(data map[string]any, keys ...string) (map[string]any, bool) {
 	return current, true
 }
 
-func formatEnvSection(data map[string]any) (string, error) {
+// inspectEnv returns Config.Env.
+func inspectEnv(data map[string]any) []string {
 	config, ok := getNestedMap(data, "Config")
 	if !ok {
-		return "No Config section found.", nil
-	}
-
-	envRaw, ok := config["Env"]
-	if !ok {
-		return "No environment variables configured.", nil
-	}
-
-	envSlice, ok := envRaw.([]any)
-	if !ok {
-		return "No environment variables configured.", nil
+		return nil
 	}
+	envSlice, _ := config["Env"].([]any)
 
-	var b strings.Builder
-	b.WriteString("Environment Variables:\n")
+	var env []string
 	for _, e := range envSlice {
 		if s, ok := e.(string); ok {
-			b.WriteString(fmt.Sprintf("  %s\n", s))
+			env = append(env, s)
 		}
 	}
-	return b.String(), nil
+	return env
 }
 
-func formatPortsSection(data map[string]any) (string, error) {
-	var b strings.Builder
-	b.WriteString("Port Bindings:\n")
+// portMappings flattens an inspect port map ("80/tcp" -> [{HostIp, HostPort}])
+// sorted by container port. Ports without any host mapping are kept with
+// empty host fields.
+func portMappings(raw map[string]any) []inspectPort {
+	containerPorts := make([]string, 0, len(raw))
+	for port := range raw {
+		containerPorts = append(containerPorts, port)
+	}
+	sort.Strings(containerPorts)
 
-	// HostConfig.PortBindings
-	hostConfig, ok := getNestedMap(data, "HostConfig")
-	if ok {
-		if pbRaw, ok := hostConfig["PortBindings"]; ok {
-			if pb, ok := pbRaw.(map[string]any); ok && len(pb) > 0 {
-				for port, bindings := range pb {
-					if bindSlice, ok := bindings.([]any); ok {
-						for _, bind := range bindSlice {
-							if bindMap, ok := bind.(map[string]any); ok {
-								hostIP, _ := bindMap["HostIp"].(string)
-								hostPort, _ := bindMap["HostPort"].(string)
-								if hostIP == "" {
-									hostIP = "0.0.0.0"
-								}
-								b.WriteString(fmt.Sprintf("  %s -> %s:%s\n", port, hostIP, hostPort))
-							}
-						}
-					}
-				}
-			} else {
-				b.WriteString("  No port bindings configured.\n")
+	ports := []inspectPort{}
+	for _, port := range containerPorts {
+		bindings, ok := raw[port].([]any)
+		if !ok || len(bindings) == 0 {
+			ports = append(ports, inspectPort{ContainerPort: port})
+			continue
+		}
+		for _, bind := range bindings {
+			bindMap, ok := bind.(map[string]any)
+			if !ok {
+				continue
 			}
-		} else {
-			b.WriteString("  No port bindings configured.\n")
+			hostIP, _ := bindMap["HostIp"].(string)
+			hostPort, _ := bindMap["HostPort"].(string)
+			if hostIP == "" {
+				hostIP = "0.0.0.0"
+			}
+			ports = append(ports, inspectPort{ContainerPort: port, HostIP: hostIP, HostPort: hostPort})
 		}
 	}
+	return ports
+}
 
-	// NetworkSettings.Ports
-	netSettings, ok := getNestedMap(data, "NetworkSettings")
-	if ok {
-		if portsRaw, ok := netSettings["Ports"]; ok {
-			if ports, ok := portsRaw.(map[string]any); ok && len(ports) > 0 {
-				b.WriteString("\nExposed Ports:\n")
-				for port, mappings := range ports {
-					if mappings == nil {
-						b.WriteString(fmt.Sprintf("  %s -> (not mapped)\n", port))
-					} else if mapSlice, ok := mappings.([]any); ok {
-						for _, m := range mapSlice {
-							if mMap, ok := m.(map[string]any); ok {
-								hostIP, _ := mMap["HostIp"].(string)
-								hostPort, _ := mMap["HostPort"].(string)
-								if hostIP == "" {
-									hostIP = "0.0.0.0"
-								}
-								b.WriteString(fmt.Sprintf("  %s -> %s:%s\n", port, hostIP, hostPort))
-							}
-						}
-					}
-				}
-			}
+// inspectPortMappings returns HostConfig.PortBindings and NetworkSettings.Ports.
+func inspectPortMappings(data map[string]any) *inspectPorts {
+	ports := &inspectPorts{Bindings: []inspectPort{}, Exposed: []inspectPort{}}
+	if hostConfig, ok := getNestedMap(data, "HostConfig"); ok {
+		if pb, ok := hostConfig["PortBindings"].(map[string]any); ok {
+			ports.Bindings = portMappings(pb)
 		}
 	}
+	if netSettings, ok := getNestedMap(data, "NetworkSettings"); ok {
+		if exposed, ok := netSettings["Ports"].(map[string]any); ok {
+			ports.Exposed = portMappings(exposed)
+		}
+	}
+	return ports
+}
 
-	return b.String(), nil
+// inspectMounts returns the container's Mounts.
+func inspectMounts(data map[string]any) []inspectMount {
+	mountsSlice, _ := data["Mounts"].([]any)
+
+	var mounts []inspectMount
+	for _, m := range mountsSlice {
+		if mount, ok := m.(map[string]any); ok {
+			var im inspectMount
+			im.Type, _ = mount["Type"].(string)
+			im.Source, _ = mount["Source"].(string)
+			im.Destination, _ = mount["Destination"].(string)
+			im.RW, _ = mount["RW"].(bool)
+			mounts = append(mounts, im)
+		}
+	}
+	return mounts
 }
 
-func formatVolumesSection(data map[string]any) (string, error) {
-	mountsRaw, ok := data["Mounts"]
+// inspectNetworks returns NetworkSettings.Networks sorted by name.
+func inspectNetworks(data map[string]any) []inspectNetwork {
+	netSettings, ok := getNestedMap(data, "NetworkSettings")
 	if !ok {
-		return "No volumes mounted.", nil
+		return nil
 	}
+	networksRaw, _ := netSettings["Networks"].(map[string]any)
 
-	mountsSlice, ok := mountsRaw.([]any)
-	if !ok || len(mountsSlice) == 0 {
-		return "No volumes mounted.", nil
+	var networks []inspectNetwork
+	for name, netRaw := range networksRaw {
+		n := inspectNetwork{Name: name}
+		if net, ok := netRaw.(map[string]any); ok {
+			n.IPAddress, _ = net["IPAddress"].(string)
+			n.Gateway, _ = net["Gateway"].(string)
+			n.MacAddress, _ = net["MacAddress"].(string)
+		}
+		networks = append(networks, n)
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+	return networks
+}
+
+func formatEnvSection(env []string) string {
+	if len(env) == 0 {
+		return "No environment variables configured."
 	}
 
 	var b strings.Builder
-	b.WriteString("Mounts:\n")
-	for _, m := range mountsSlice {
-		if mount, ok := m.(map[string]any); ok {
-			mountType, _ := mount["Type"].(string)
-			source, _ := mount["Source"].(string)
-			destination, _ := mount["Destination"].(string)
-			rw, _ := mount["RW"].(bool)
-			mode := "rw"
-			if !rw {
-				mode = "ro"
+	b.WriteString("Environment Variables:\n")
+	for _, e := range env {
+		b.WriteString(fmt.Sprintf("  %s\n", e))
+	}
+	return b.String()
+}
+
+func formatPortsSection(ports *inspectPorts) string {
+	var b strings.Builder
+	b.WriteString("Port Bindings:\n")
+
+	bound := 0
+	for _, p := range ports.Bindings {
+		if p.HostPort == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %s -> %s:%s\n", p.ContainerPort, p.HostIP, p.HostPort))
+		bound++
+	}
+	if bound == 0 {
+		b.WriteString("  No port bindings configured.\n")
+	}
+
+	if len(ports.Exposed) > 0 {
+		b.WriteString("\nExposed Ports:\n")
+		for _, p := range ports.Exposed {
+			if p.HostIP == "" && p.HostPort == "" {
+				b.WriteString(fmt.Sprintf("  %s -> (not mapped)\n", p.ContainerPort))
+			} else {
+				b.WriteString(fmt.Sprintf("  %s -> %s:%s\n", p.ContainerPort, p.HostIP, p.HostPort))
 			}
-			b.WriteString(fmt.Sprintf("  [%s] %s -> %s (%s)\n", mountType, source, destination, mode))
 		}
 	}
-	return b.String(), nil
+
+	return b.String()
 }
 
-func formatNetworkSection(data map[string]any) (string, error) {
-	netSettings, ok := getNestedMap(data, "NetworkSettings")
-	if !ok {
-		return "No network settings found.", nil
+func formatVolumesSection(mounts []inspectMount) string {
+	if len(mounts) == 0 {
+		return "No volumes mounted."
 	}
 
-	networksRaw, ok := netSettings["Networks"]
-	if !ok {
-		return "No networks configured.", nil
+	var b strings.Builder
+	b.WriteString("Mounts:\n")
+	for _, m := range mounts {
+		mode := "rw"
+		if !m.RW {
+			mode = "ro"
+		}
+		b.WriteString(fmt.Sprintf("  [%s] %s -> %s (%s)\n", m.Type, m.Source, m.Destination, mode))
 	}
+	return b.String()
+}
 
-	networks, ok := networksRaw.(map[string]any)
-	if !ok || len(networks) == 0 {
-		return "No networks configured.", nil
+func formatNetworkSection(networks []inspectNetwork) string {
+	if len(networks) == 0 {
+		return "No networks configured."
 	}
 
 	var b strings.Builder
 	b.WriteString("Networks:\n")
-	for name, netRaw := range networks {
-		b.WriteString(fmt.Sprintf("  %s:\n", name))
-		if net, ok := netRaw.(map[string]any); ok {
-			if ip, ok := net["IPAddress"].(string); ok && ip != "" {
-				b.WriteString(fmt.Sprintf("    IP Address: %s\n", ip))
-			}
-			if gw, ok := net["Gateway"].(string); ok && gw != "" {
-				b.WriteString(fmt.Sprintf("    Gateway:    %s\n", gw))
-			}
-			if mac, ok := net["MacAddress"].(string); ok && mac != "" {
-				b.WriteString(fmt.Sprintf("    MAC:        %s\n", mac))
-			}
+	for _, n := range networks {
+		b.WriteString(fmt.Sprintf("  %s:\n", n.Name))
+		if n.IPAddress != "" {
+			b.WriteString(fmt.Sprintf("    IP Address: %s\n", n.IPAddress))
+		}
+		if n.Gateway != "" {
+			b.WriteString(fmt.Sprintf("    Gateway:    %s\n", n.Gateway))
+		}
+		if n.MacAddress != "" {
+			b.WriteString(fmt.Sprintf("    MAC:        %s\n", n.MacAddress))
 		}
 	}
-	return b.String(), nil
+	return b.String()
 }
 
-func formatAllSection(rawJSON string) (string, error) {
+func formatAllSection(rawJSON string) string {
 	// Pretty-print the full inspect output
 	var data any
 	if err := json.Unmarshal([]byte(rawJSON), &data); err != nil {
-		return rawJSON, nil
+		return rawJSON
 	}
 	pretty, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		return rawJSON, nil
+		return rawJSON
 	}
-	return string(pretty), nil
+	return string(pretty)
 }
 
 func registerContainerInspect(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "container_inspect",
 		Description: "Get detailed container information. Optionally filter by section: env, ports, volumes, network, or all (default).",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerInspectArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleContainerInspect(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerInspectArgs) (*mcp.CallToolResult, containerInspectOutput, error) {
+		result, out, err := handleContainerInspect(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
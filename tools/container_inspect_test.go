@@ -66,7 +66,7 @@ func TestHandleContainerInspect_AllSection(t *testing.T) {
 	mock.On("inspect mycontainer", inspectJSON, nil)
 
 	args := containerInspectArgs{Container: "mycontainer", Section: "all"}
-	result, err := handleContainerInspect(context.Background(), mock, args)
+	result, _, err := handleContainerInspect(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -85,7 +85,7 @@ func TestHandleContainerInspect_EnvSection(t *testing.T) {
 	mock.On("inspect mycontainer", inspectJSON, nil)
 
 	args := containerInspectArgs{Container: "mycontainer", Section: "env"}
-	result, err := handleContainerInspect(context.Background(), mock, args)
+	result, _, err := handleContainerInspect(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -106,7 +106,7 @@ func TestHandleContainerInspect_PortsSection(t *testing.T) {
 	mock.On("inspect mycontainer", inspectJSON, nil)
 
 	args := containerInspectArgs{Container: "mycontainer", Section: "ports"}
-	result, err := handleContainerInspect(context.Background(), mock, args)
+	result, _, err := handleContainerInspect(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -127,7 +127,7 @@ func TestHandleContainerInspect_VolumesSection(t *testing.T) {
 	mock.On("inspect mycontainer", inspectJSON, nil)
 
 	args := containerInspectArgs{Container: "mycontainer", Section: "volumes"}
-	result, err := handleContainerInspect(context.Background(), mock, args)
+	result, _, err := handleContainerInspect(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -155,7 +155,7 @@ func TestHandleContainerInspect_DefaultSection(t *testing.T) {
 
 	// Empty section should default to "all"
 	args := containerInspectArgs{Container: "mycontainer", Section: ""}
-	result, err := handleContainerInspect(context.Background(), mock, args)
+	result, _, err := handleContainerInspect(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -170,7 +170,7 @@ func TestHandleContainerInspect_ContainerNotFound(t *testing.T) {
 	mock.On("inspect nosuchcontainer", "", fmt.Errorf("Error: No such container: nosuchcontainer"))
 
 	args := containerInspectArgs{Container: "nosuchcontainer", Section: "all"}
-	_, err := handleContainerInspect(context.Background(), mock, args)
+	_, _, err := handleContainerInspect(context.Background(), mock, args)
 	if err == nil {
 		t.Fatal("expected an error, got nil")
 	}
@@ -184,7 +184,7 @@ func TestHandleContainerInspect_EmptyContainer(t *testing.T) {
 	mock := docker.NewMock()
 
 	args := containerInspectArgs{Container: "", Section: "all"}
-	_, err := handleContainerInspect(context.Background(), mock, args)
+	_, _, err := handleContainerInspect(context.Background(), mock, args)
 	if err == nil {
 		t.Fatal("expected an error for empty container name, got nil")
 	}
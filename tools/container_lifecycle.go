@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// targetSelector picks the containers a lifecycle tool acts on: either a
+// single container, or every container of a Compose project, optionally
+// narrowed to one service.
+type targetSelector struct {
+	Container string
+	Project   string
+	Service   string
+}
+
+func (s targetSelector) String() string {
+	switch {
+	case s.Container != "":
+		return s.Container
+	case s.Service != "":
+		return fmt.Sprintf("service %s of project %s", s.Service, s.Project)
+	default:
+		return "project " + s.Project
+	}
+}
+
+// resolveTargets expands a selector into containers using the same Compose
+// labels discoverProject relies on. A plain container selector is passed
+// through as-is so docker can report unknown names itself.
+func resolveTargets(ctx context.Context, exec docker.Executor, sel targetSelector) ([]containerInfo, error) {
+	switch {
+	case sel.Container != "" && (sel.Project != "" || sel.Service != ""):
+		return nil, fmt.Errorf("specify either container or project (with optional service), not both")
+	case sel.Container != "":
+		return []containerInfo{{ID: sel.Container, Names: sel.Container}}, nil
+	case sel.Project == "" && sel.Service != "":
+		return nil, fmt.Errorf("service requires project")
+	case sel.Project == "":
+		return nil, fmt.Errorf("container or project is required")
+	}
+
+	labels := []string{"com.docker.compose.project=" + sel.Project}
+	if sel.Service != "" {
+		labels = append(labels, "com.docker.compose.service="+sel.Service)
+	}
+	containers, err := psContainers(ctx, exec, true, labels...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no containers found for %s", sel)
+	}
+	return containers, nil
+}
+
+// lifecycleAction describes one docker lifecycle command.
+type lifecycleAction struct {
+	// name is the tool-facing verb, e.g. "stop".
+	name string
+	// done is the past tense used in reports, e.g. "stopped".
+	done string
+	// args builds the docker arguments for one container.
+	args func(container string) []string
+	// states, when set, lists the container states the action applies to
+	// when expanding a project selector. Other containers are skipped.
+	states []string
+}
+
+type lifecycleOutput struct {
+	Action  string            `json:"action"`
+	Results []lifecycleResult `json:"results" jsonschema:"outcome for each selected container"`
+}
+
+type lifecycleResult struct {
+	Container string `json:"container"`
+	ID        string `json:"id,omitempty"`
+	Service   string `json:"service,omitempty"`
+	Status    string `json:"status" jsonschema:"ok, skipped or failed"`
+	Message   string `json:"message,omitempty" jsonschema:"error, or reason the container was skipped"`
+}
+
+// runLifecycle applies action to every container the selector resolves to,
+// one at a time, and reports each outcome. Per-container failures are part
+// of the report; only a selector that cannot be resolved is an error.
+func runLifecycle(ctx context.Context, exec docker.Executor, sel targetSelector, action lifecycleAction) (string, lifecycleOutput, error) {
+	out := lifecycleOutput{Action: action.name}
+	targets, err := resolveTargets(ctx, exec, sel)
+	if err != nil {
+		return "", out, err
+	}
+
+	succeeded := 0
+	for _, c := range targets {
+		r := lifecycleResult{Container: c.Names, Service: c.Labels["com.docker.compose.service"]}
+		if c.ID != c.Names {
+			r.ID = c.ID
+		}
+
+		switch {
+		case sel.Container == "" && len(action.states) > 0 && !slices.Contains(action.states, c.State):
+			r.Status = "skipped"
+			r.Message = fmt.Sprintf("container is %s", c.State)
+		default:
+			if _, err := exec.ExecCombined(ctx, action.args(c.ID)...); err != nil {
+				r.Status = "failed"
+				r.Message = err.Error()
+			} else {
+				r.Status = "ok"
+				succeeded++
+			}
+		}
+		out.Results = append(out.Results, r)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s %d of %d containers (%s):\n", capitalize(action.done), succeeded, len(targets), sel))
+	for _, r := range out.Results {
+		switch r.Status {
+		case "ok":
+			b.WriteString(fmt.Sprintf("  %-30s %s\n", r.Container, action.done))
+		case "skipped":
+			b.WriteString(fmt.Sprintf("  %-30s skipped (%s)\n", r.Container, r.Message))
+		default:
+			b.WriteString(fmt.Sprintf("  %-30s FAILED: %s\n", r.Container, r.Message))
+		}
+	}
+	return b.String(), out, nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+type containerTargetArgs struct {
+	Container string `json:"container,omitempty" jsonschema:"container name or ID"`
+	Project   string `json:"project,omitempty" jsonschema:"Compose project name (instead of container)"`
+	Service   string `json:"service,omitempty" jsonschema:"Compose service name within project (default: all services)"`
+}
+
+type containerStopArgs struct {
+	Container string `json:"container,omitempty" jsonschema:"container name or ID"`
+	Project   string `json:"project,omitempty" jsonschema:"Compose project name (instead of container)"`
+	Service   string `json:"service,omitempty" jsonschema:"Compose service name within project (default: all services)"`
+	Timeout   int    `json:"timeout,omitempty" jsonschema:"seconds to wait for the container to stop before killing it (default: 10)"`
+}
+
+type containerKillArgs struct {
+	Container string `json:"container,omitempty" jsonschema:"container name or ID"`
+	Project   string `json:"project,omitempty" jsonschema:"Compose project name (instead of container)"`
+	Service   string `json:"service,omitempty" jsonschema:"Compose service name within project (default: all services)"`
+	Signal    string `json:"signal,omitempty" jsonschema:"signal to send, e.g. SIGTERM or HUP (default: SIGKILL)"`
+}
+
+type containerRemoveArgs struct {
+	Container string `json:"container,omitempty" jsonschema:"container name or ID"`
+	Project   string `json:"project,omitempty" jsonschema:"Compose project name (instead of container)"`
+	Service   string `json:"service,omitempty" jsonschema:"Compose service name within project (default: all services)"`
+	Force     bool   `json:"force,omitempty" jsonschema:"remove running containers by killing them first"`
+	Volumes   bool   `json:"volumes,omitempty" jsonschema:"also remove anonymous volumes attached to the containers"`
+}
+
+func handleContainerStart(ctx context.Context, exec docker.Executor, args containerTargetArgs) (string, lifecycleOutput, error) {
+	return runLifecycle(ctx, exec, targetSelector{args.Container, args.Project, args.Service}, lifecycleAction{
+		name:   "start",
+		done:   "started",
+		args:   func(id string) []string { return []string{"start", id} },
+		states: []string{"created", "exited"},
+	})
+}
+
+func handleContainerStop(ctx context.Context, exec docker.Executor, args containerStopArgs) (string, lifecycleOutput, error) {
+	timeout := args.Timeout
+	if timeout <= 0 {
+		timeout = 10
+	}
+	return runLifecycle(ctx, exec, targetSelector{args.Container, args.Project, args.Service}, lifecycleAction{
+		name:   "stop",
+		done:   "stopped",
+		args:   func(id string) []string { return []string{"stop", "--time", strconv.Itoa(timeout), id} },
+		states: []string{"running", "restarting", "paused"},
+	})
+}
+
+func handleContainerKill(ctx context.Context, exec docker.Executor, args containerKillArgs) (string, lifecycleOutput, error) {
+	signal := args.Signal
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	return runLifecycle(ctx, exec, targetSelector{args.Container, args.Project, args.Service}, lifecycleAction{
+		name:   "kill",
+		done:   "killed",
+		args:   func(id string) []string { return []string{"kill", "--signal", signal, id} },
+		states: []string{"running", "restarting"},
+	})
+}
+
+func handleContainerPause(ctx context.Context, exec docker.Executor, args containerTargetArgs) (string, lifecycleOutput, error) {
+	return runLifecycle(ctx, exec, targetSelector{args.Container, args.Project, args.Service}, lifecycleAction{
+		name:   "pause",
+		done:   "paused",
+		args:   func(id string) []string { return []string{"pause", id} },
+		states: []string{"running"},
+	})
+}
+
+func handleContainerUnpause(ctx context.Context, exec docker.Executor, args containerTargetArgs) (string, lifecycleOutput, error) {
+	return runLifecycle(ctx, exec, targetSelector{args.Container, args.Project, args.Service}, lifecycleAction{
+		name:   "unpause",
+		done:   "unpaused",
+		args:   func(id string) []string { return []string{"unpause", id} },
+		states: []string{"paused"},
+	})
+}
+
+func handleContainerRemove(ctx context.Context, exec docker.Executor, args containerRemoveArgs) (string, lifecycleOutput, error) {
+	return runLifecycle(ctx, exec, targetSelector{args.Container, args.Project, args.Service}, lifecycleAction{
+		name: "remove",
+		done: "removed",
+		args: func(id string) []string {
+			cmdArgs := []string{"rm"}
+			if args.Force {
+				cmdArgs = append(cmdArgs, "--force")
+			}
+			if args.Volumes {
+				cmdArgs = append(cmdArgs, "--volumes")
+			}
+			return append(cmdArgs, id)
+		},
+	})
+}
+
+// addLifecycleTool registers a lifecycle tool whose handler returns a
+// per-container report.
+func addLifecycleTool[In any](server *mcp.Server, exec docker.Executor, tool *mcp.Tool, handle func(context.Context, docker.Executor, In) (string, lifecycleOutput, error)) {
+	mcp.AddTool(server, tool, func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, lifecycleOutput, error) {
+		result, out, err := handle(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
+
+func registerContainerLifecycle(server *mcp.Server, exec docker.Executor) {
+	const selectorHelp = " Target a single container, or a Compose project (optionally one service) to act on all of its containers."
+
+	addLifecycleTool(server, exec, &mcp.Tool{
+		Name:        "container_start",
+		Description: "Start stopped containers." + selectorHelp,
+	}, handleContainerStart)
+	addLifecycleTool(server, exec, &mcp.Tool{
+		Name:        "container_stop",
+		Description: "Stop running containers gracefully, killing them after a timeout." + selectorHelp,
+	}, handleContainerStop)
+	addLifecycleTool(server, exec, &mcp.Tool{
+		Name:        "container_kill",
+		Description: "Send a signal (default SIGKILL) to running containers." + selectorHelp,
+	}, handleContainerKill)
+	addLifecycleTool(server, exec, &mcp.Tool{
+		Name:        "container_pause",
+		Description: "Pause all processes in running containers." + selectorHelp,
+	}, handleContainerPause)
+	addLifecycleTool(server, exec, &mcp.Tool{
+		Name:        "container_unpause",
+		Description: "Resume paused containers." + selectorHelp,
+	}, handleContainerUnpause)
+	addLifecycleTool(server, exec, &mcp.Tool{
+		Name:        "container_remove",
+		Description: "Remove containers, optionally forcing removal of running ones and deleting their anonymous volumes." + selectorHelp,
+	}, handleContainerRemove)
+}
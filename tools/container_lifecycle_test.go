@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const lifecyclePs = `{"ID":"aaa111","Names":"shop-web-1","State":"running","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}
+{"ID":"bbb222","Names":"shop-web-2","State":"exited","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}`
+
+func TestHandleContainerStop_SingleContainer(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("stop --time 30 mycontainer", "mycontainer\n", nil)
+
+	result, out, err := handleContainerStop(context.Background(), mock, containerStopArgs{Container: "mycontainer", Timeout: 30})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(result, "Stopped 1 of 1 containers") {
+		t.Errorf("expected summary line, got:\n%s", result)
+	}
+	if len(out.Results) != 1 || out.Results[0].Container != "mycontainer" || out.Results[0].Status != "ok" {
+		t.Errorf("unexpected results: %+v", out.Results)
+	}
+	if len(mock.Calls()) != 1 {
+		t.Errorf("expected only the stop call, got %v", mock.Calls())
+	}
+}
+
+func TestHandleContainerStop_ProjectServiceSkipsStopped(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop --filter label=com.docker.compose.service=web", lifecyclePs, nil)
+	mock.On("stop --time 10 aaa111", "aaa111\n", nil)
+
+	result, out, err := handleContainerStop(context.Background(), mock, containerStopArgs{Project: "shop", Service: "web"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(out.Results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", out.Results)
+	}
+	if r := out.Results[0]; r.Container != "shop-web-1" || r.ID != "aaa111" || r.Service != "web" || r.Status != "ok" {
+		t.Errorf("unexpected result for running replica: %+v", r)
+	}
+	if r := out.Results[1]; r.Status != "skipped" || r.Message != "container is exited" {
+		t.Errorf("expected exited replica to be skipped, got %+v", r)
+	}
+	if !strings.Contains(result, "service web of project shop") {
+		t.Errorf("expected selector in summary, got:\n%s", result)
+	}
+	for _, call := range mock.Calls() {
+		if strings.Join(call, " ") == "stop --time 10 bbb222" {
+			t.Error("should not stop an exited container")
+		}
+	}
+}
+
+func TestHandleContainerStart_Project(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop", lifecyclePs, nil)
+	mock.On("start bbb222", "bbb222\n", nil)
+
+	_, out, err := handleContainerStart(context.Background(), mock, containerTargetArgs{Project: "shop"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Results[0].Status != "skipped" || out.Results[1].Status != "ok" {
+		t.Errorf("expected only the exited replica to be started, got %+v", out.Results)
+	}
+}
+
+func TestHandleContainerKill_Signal(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("kill --signal SIGHUP web", "web\n", nil)
+
+	_, out, err := handleContainerKill(context.Background(), mock, containerKillArgs{Container: "web", Signal: "SIGHUP"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Action != "kill" || out.Results[0].Status != "ok" {
+		t.Errorf("unexpected output: %+v", out)
+	}
+}
+
+func TestHandleContainerRemove_Flags(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("rm --force --volumes web", "web\n", nil)
+
+	_, out, err := handleContainerRemove(context.Background(), mock, containerRemoveArgs{Container: "web", Force: true, Volumes: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Results[0].Status != "ok" {
+		t.Errorf("unexpected output: %+v", out)
+	}
+}
+
+func TestHandleContainerPause_ReportsFailures(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("pause web", "", fmt.Errorf("Error response from daemon: container web is not running"))
+
+	result, out, err := handleContainerPause(context.Background(), mock, containerTargetArgs{Container: "web"})
+	if err != nil {
+		t.Fatalf("per-container failures should be reported, not returned: %v", err)
+	}
+	if r := out.Results[0]; r.Status != "failed" || !strings.Contains(r.Message, "not running") {
+		t.Errorf("expected failed result, got %+v", r)
+	}
+	if !strings.Contains(result, "Paused 0 of 1 containers") || !strings.Contains(result, "FAILED") {
+		t.Errorf("expected failure in report, got:\n%s", result)
+	}
+}
+
+func TestResolveTargets_Errors(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=ghost", "", nil)
+
+	tests := []struct {
+		name string
+		sel  targetSelector
+		want string
+	}{
+		{"empty", targetSelector{}, "container or project is required"},
+		{"both", targetSelector{Container: "web", Project: "shop"}, "not both"},
+		{"service without project", targetSelector{Service: "web"}, "service requires project"},
+		{"no match", targetSelector{Project: "ghost"}, "no containers found for project ghost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveTargets(context.Background(), mock, tt.sel)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
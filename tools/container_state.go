@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// healthPollInterval is how often readiness is re-checked while waiting for
+// a container to become healthy.
+var healthPollInterval = time.Second
+
+// containerState is the .State section of docker inspect.
+type containerState struct {
+	Status     string       `json:"Status"`
+	Running    bool         `json:"Running"`
+	Restarting bool         `json:"Restarting"`
+	ExitCode   int          `json:"ExitCode"`
+	FinishedAt string       `json:"FinishedAt"`
+	Health     *healthState `json:"Health"`
+}
+
+// inspectState reads the current .State of a container.
+func inspectState(ctx context.Context, exec docker.Executor, container string) (*containerState, error) {
+	out, err := exec.Exec(ctx, "inspect", "--format", "{{json .State}}", container)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %q: %w", container, err)
+	}
+	var state containerState
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state of container %q: %w", container, err)
+	}
+	return &state, nil
+}
+
+// readiness classifies a state. A container with a healthcheck is ready once
+// healthy; one without is ready once running. A container that exited with
+// code 0 ran to completion, like a migration job, and counts as ready.
+// Unhealthy, otherwise exited and dead containers have failed.
+func (s *containerState) readiness() (ready, failed bool) {
+	if s.completed() {
+		return true, false
+	}
+	if s.Health != nil {
+		switch s.Health.Status {
+		case "healthy":
+			return true, false
+		case "unhealthy":
+			return false, true
+		}
+		return false, s.Status == "exited" || s.Status == "dead"
+	}
+	switch s.Status {
+	case "running":
+		return true, false
+	case "exited", "dead":
+		return false, true
+	}
+	return false, false
+}
+
+// completed reports whether the container exited successfully.
+func (s *containerState) completed() bool {
+	return s.Status == "exited" && s.ExitCode == 0
+}
+
+// finishedBefore reports whether the container last stopped before t. It is
+// false for containers that never stopped.
+func (s *containerState) finishedBefore(t time.Time) bool {
+	finished, err := time.Parse(time.RFC3339Nano, s.FinishedAt)
+	return err == nil && finished.Year() > 1 && finished.Before(t)
+}
+
+// describe summarizes the state as "running", "running (starting)" etc.
+func (s *containerState) describe() string {
+	if s.Health != nil && s.Health.Status != "" {
+		return fmt.Sprintf("%s (%s)", s.Status, s.Health.Status)
+	}
+	if s.Status == "exited" {
+		return fmt.Sprintf("exited (%d)", s.ExitCode)
+	}
+	return s.Status
+}
+
+// waitReady polls a container until it is ready, has failed, or timeout
+// elapses. The last observed state is returned in every case where one was
+// read.
+func waitReady(ctx context.Context, exec docker.Executor, container string, timeout time.Duration) (*containerState, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		state, err := inspectState(ctx, exec, container)
+		if err != nil {
+			return nil, err
+		}
+		ready, failed := state.readiness()
+		if ready {
+			return state, nil
+		}
+		if failed {
+			return state, fmt.Errorf("container %s is %s", container, state.describe())
+		}
+		if !time.Now().Before(deadline) {
+			return state, fmt.Errorf("timed out after %s waiting for container %s (%s)", timeout, container, state.describe())
+		}
+
+		select {
+		case <-ctx.Done():
+			return state, ctx.Err()
+		case <-time.After(healthPollInterval):
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+func TestContainerState_Readiness(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       containerState
+		ready, fail bool
+	}{
+		{"running without healthcheck", containerState{Status: "running"}, true, false},
+		{"created", containerState{Status: "created"}, false, false},
+		{"exited", containerState{Status: "exited", ExitCode: 1}, false, true},
+		{"completed", containerState{Status: "exited", ExitCode: 0}, true, false},
+		{"completed with healthcheck", containerState{Status: "exited", Health: &healthState{Status: "unhealthy"}}, true, false},
+		{"starting", containerState{Status: "running", Health: &healthState{Status: "starting"}}, false, false},
+		{"healthy", containerState{Status: "running", Health: &healthState{Status: "healthy"}}, true, false},
+		{"unhealthy", containerState{Status: "running", Health: &healthState{Status: "unhealthy"}}, false, true},
+		{"exited with healthcheck", containerState{Status: "exited", ExitCode: 137, Health: &healthState{Status: "starting"}}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, fail := tt.state.readiness()
+			if ready != tt.ready || fail != tt.fail {
+				t.Errorf("readiness() = %v, %v; want %v, %v", ready, fail, tt.ready, tt.fail)
+			}
+		})
+	}
+}
+
+func TestWaitReady_Timeout(t *testing.T) {
+	fastHealthPolling(t)
+	mock := docker.NewMock()
+	mock.On("inspect --format {{json .State}} web", `{"Status":"running","Health":{"Status":"starting"}}`, nil)
+
+	state, err := waitReady(context.Background(), mock, "web", 20*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if state == nil || state.describe() != "running (starting)" {
+		t.Errorf("expected last observed state, got %+v", state)
+	}
+}
+
+func TestWaitReady_Exited(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("inspect --format {{json .State}} web", `{"Status":"exited","ExitCode":137}`, nil)
+
+	_, err := waitReady(context.Background(), mock, "web", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "exited (137)") {
+		t.Errorf("expected exited error, got %v", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -11,7 +13,11 @@ import (
 )
 
 type containerStatsArgs struct {
-	Container string `json:"container" jsonschema:"container name or ID (if empty returns all containers)"`
+	Container string `json:"container,omitempty" jsonschema:"container name or ID (if empty returns all containers)"`
+}
+
+type containerStatsOutput struct {
+	Containers []docker.Stats `json:"containers" jsonschema:"one sample per container, with sizes in bytes and usage in percent"`
 }
 
 type statsEntry struct {
@@ -26,41 +32,201 @@ type statsEntry struct {
 	PIDs      string `json:"PIDs"`
 }
 
-func handleContainerStats(ctx context.Context, exec docker.Executor, args containerStatsArgs) (string, error) {
-	cmdArgs := []string{"stats", "--no-stream", "--format", "{{json .}}"}
-	if args.Container != "" {
-		cmdArgs = append(cmdArgs, args.Container)
+// newStatsEntry formats a typed stats sample the same way docker stats does.
+func newStatsEntry(s docker.Stats) statsEntry {
+	return statsEntry{
+		Container: s.ID,
+		Name:      s.Name,
+		ID:        s.ID,
+		CPUPerc:   fmt.Sprintf("%.2f%%", s.CPUPercent),
+		MemUsage:  binarySize(s.MemUsage) + " / " + binarySize(s.MemLimit),
+		MemPerc:   fmt.Sprintf("%.2f%%", s.MemPercent),
+		NetIO:     decimalSize(s.NetRx) + " / " + decimalSize(s.NetTx),
+		BlockIO:   decimalSize(s.BlockRead) + " / " + decimalSize(s.BlockWrite),
+		PIDs:      fmt.Sprintf("%d", s.PIDs),
+	}
+}
+
+// parse converts the human-readable docker stats fields into numbers.
+func (e statsEntry) parse() (docker.Stats, error) {
+	s := docker.Stats{ID: e.ID, Name: e.Name}
+	if s.Name == "" {
+		s.Name = e.Container
 	}
 
-	out, err := exec.Exec(ctx, cmdArgs...)
+	var err error
+	if s.CPUPercent, err = parsePercent(e.CPUPerc); err != nil {
+		return s, fmt.Errorf("CPUPerc: %w", err)
+	}
+	if s.MemPercent, err = parsePercent(e.MemPerc); err != nil {
+		return s, fmt.Errorf("MemPerc: %w", err)
+	}
+	if s.MemUsage, s.MemLimit, err = parseSizePair(e.MemUsage); err != nil {
+		return s, fmt.Errorf("MemUsage: %w", err)
+	}
+	if s.NetRx, s.NetTx, err = parseSizePair(e.NetIO); err != nil {
+		return s, fmt.Errorf("NetIO: %w", err)
+	}
+	if s.BlockRead, s.BlockWrite, err = parseSizePair(e.BlockIO); err != nil {
+		return s, fmt.Errorf("BlockIO: %w", err)
+	}
+	if e.PIDs != "" && e.PIDs != "--" {
+		if s.PIDs, err = strconv.ParseUint(e.PIDs, 10, 64); err != nil {
+			return s, fmt.Errorf("PIDs: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// sizeUnits maps the unit suffixes docker prints to their size in bytes.
+var sizeUnits = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// parseSize parses a human-readable size such as "12.5MiB" or "1.2kB".
+// Docker prints "--" when a value is unavailable, which parses as zero.
+func parseSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "--" {
+		return 0, nil
+	}
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	value, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit in %q", s)
+	}
+	return uint64(math.Round(value * unit)), nil
+}
+
+// parseSizePair parses "used / total" style pairs such as "50MiB / 1GiB".
+func parseSizePair(s string) (uint64, uint64, error) {
+	first, second, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid size pair %q", s)
+	}
+	a, err := parseSize(first)
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := parseSize(second)
 	if err != nil {
-		return "", fmt.Errorf("failed to get container stats: %w", err)
+		return 0, 0, err
 	}
+	return a, b, nil
+}
 
-	out = strings.TrimSpace(out)
-	if out == "" {
-		return "No running containers found.", nil
+// parsePercent parses a percentage such as "12.50%".
+func parsePercent(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "--" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q", s)
 	}
+	return v, nil
+}
+
+// binarySize formats bytes with binary units (KiB, MiB, ...), as docker stats
+// does for memory.
+func binarySize(n uint64) string {
+	return humanSize(float64(n), 1024, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}, 4)
+}
+
+// decimalSize formats bytes with decimal units (kB, MB, ...), as docker stats
+// does for network and block I/O.
+func decimalSize(n uint64) string {
+	return humanSize(float64(n), 1000, []string{"B", "kB", "MB", "GB", "TB", "PB"}, 3)
+}
 
-	lines := strings.Split(out, "\n")
+func humanSize(size, base float64, units []string, precision int) string {
+	i := 0
+	for size >= base && i < len(units)-1 {
+		size /= base
+		i++
+	}
+	return fmt.Sprintf("%.*g%s", precision, size, units[i])
+}
+
+// readStats takes one stats sample of the given container, or of every
+// running container when container is empty. It returns both the numeric
+// samples and the docker stats-style entries used for display.
+func readStats(ctx context.Context, exec docker.Executor, container string) ([]docker.Stats, []statsEntry, error) {
+	if reader, ok := exec.(docker.StatsReader); ok {
+		stats, err := reader.ContainerStats(ctx, container)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries := make([]statsEntry, 0, len(stats))
+		for _, s := range stats {
+			entries = append(entries, newStatsEntry(s))
+		}
+		return stats, entries, nil
+	}
+
+	cmdArgs := []string{"stats", "--no-stream", "--format", "{{json .}}"}
+	if container != "" {
+		cmdArgs = append(cmdArgs, container)
+	}
+
+	out, err := exec.Exec(ctx, cmdArgs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var stats []docker.Stats
 	var entries []statsEntry
-	for _, line := range lines {
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
 		var e statsEntry
 		if err := json.Unmarshal([]byte(line), &e); err != nil {
-			return "", fmt.Errorf("failed to parse stats JSON: %w", err)
+			return nil, nil, fmt.Errorf("failed to parse stats JSON: %w", err)
+		}
+		s, err := e.parse()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse stats for %s: %w", e.Name, err)
 		}
 		entries = append(entries, e)
+		stats = append(stats, s)
+	}
+	return stats, entries, nil
+}
+
+func handleContainerStats(ctx context.Context, exec docker.Executor, args containerStatsArgs) (string, containerStatsOutput, error) {
+	stats, entries, err := readStats(ctx, exec, args.Container)
+	if err != nil {
+		return "", containerStatsOutput{}, fmt.Errorf("failed to get container stats: %w", err)
 	}
 
+	out := containerStatsOutput{Containers: stats}
 	if len(entries) == 0 {
-		return "No running containers found.", nil
+		return "No running containers found.", out, nil
 	}
 
-	return formatStatsTable(entries), nil
+	return formatStatsTable(entries), out, nil
 }
 
 func formatStatsTable(entries []statsEntry) string {
@@ -90,16 +256,13 @@ func registerContainerStats(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "container_stats",
 		Description: "Get resource usage statistics for containers (CPU, memory, network, block I/O, PIDs). Optionally specify a container name/ID or leave empty for all running containers.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerStatsArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleContainerStats(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args containerStatsArgs) (*mcp.CallToolResult, containerStatsOutput, error) {
+		result, out, err := handleContainerStats(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
@@ -14,7 +14,7 @@ func TestHandleContainerStats_SingleContainer(t *testing.T) {
 	mock.On("stats --no-stream --format {{json .}} nginx", `{"Container":"abc123","Name":"nginx","ID":"abc123def456","CPUPerc":"0.50%","MemUsage":"50MiB / 1GiB","MemPerc":"5.00%","NetIO":"1.2kB / 3.4kB","BlockIO":"10MB / 20MB","PIDs":"5"}`, nil)
 
 	args := containerStatsArgs{Container: "nginx"}
-	result, err := handleContainerStats(context.Background(), mock, args)
+	result, _, err := handleContainerStats(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -40,7 +40,7 @@ func TestHandleContainerStats_AllContainers(t *testing.T) {
 	mock.On("stats --no-stream --format {{json .}}", multiOutput, nil)
 
 	args := containerStatsArgs{Container: ""}
-	result, err := handleContainerStats(context.Background(), mock, args)
+	result, _, err := handleContainerStats(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -61,7 +61,7 @@ func TestHandleContainerStats_NoRunningContainers(t *testing.T) {
 	mock.On("stats --no-stream --format {{json .}}", "", nil)
 
 	args := containerStatsArgs{Container: ""}
-	result, err := handleContainerStats(context.Background(), mock, args)
+	result, _, err := handleContainerStats(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -76,7 +76,7 @@ func TestHandleContainerStats_DockerError(t *testing.T) {
 	mock.On("stats --no-stream --format {{json .}} badcontainer", "", fmt.Errorf("Error: No such container: badcontainer"))
 
 	args := containerStatsArgs{Container: "badcontainer"}
-	_, err := handleContainerStats(context.Background(), mock, args)
+	_, _, err := handleContainerStats(context.Background(), mock, args)
 	if err == nil {
 		t.Fatal("expected an error, got nil")
 	}
@@ -85,3 +85,96 @@ func TestHandleContainerStats_DockerError(t *testing.T) {
 		t.Errorf("expected error to mention container name, got: %v", err)
 	}
 }
+
+func TestHandleContainerStats_TypedReader(t *testing.T) {
+	mock := &typedMock{
+		Mock: docker.NewMock(),
+		stats: []docker.Stats{{
+			ID: "abc123", Name: "nginx", CPUPercent: 0.5,
+			MemUsage: 50 * 1024 * 1024, MemLimit: 1024 * 1024 * 1024, MemPercent: 4.88,
+			NetRx: 1200, NetTx: 3400, BlockRead: 10_000_000, BlockWrite: 20_000_000, PIDs: 5,
+		}},
+	}
+
+	result, _, err := handleContainerStats(context.Background(), mock, containerStatsArgs{Container: "nginx"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"nginx", "0.50%", "50MiB / 1GiB", "4.88%", "1.2kB / 3.4kB", "10MB / 20MB"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected result to contain %q, got:\n%s", want, result)
+		}
+	}
+	if len(mock.Calls()) != 0 {
+		t.Errorf("expected no CLI calls when the executor reads stats natively, got %v", mock.Calls())
+	}
+}
+
+func TestHandleContainerStats_StructuredOutput(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("stats --no-stream --format {{json .}} nginx", `{"Container":"abc123","Name":"nginx","ID":"abc123def456","CPUPerc":"0.50%","MemUsage":"50MiB / 1GiB","MemPerc":"5.00%","NetIO":"1.2kB / 3.4kB","BlockIO":"10MB / 0B","PIDs":"5"}`, nil)
+
+	_, out, err := handleContainerStats(context.Background(), mock, containerStatsArgs{Container: "nginx"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := docker.Stats{
+		ID: "abc123def456", Name: "nginx", CPUPercent: 0.5,
+		MemUsage: 50 << 20, MemLimit: 1 << 30, MemPercent: 5,
+		NetRx: 1200, NetTx: 3400, BlockRead: 10_000_000, BlockWrite: 0, PIDs: 5,
+	}
+	if len(out.Containers) != 1 || out.Containers[0] != want {
+		t.Errorf("expected %+v, got %+v", want, out.Containers)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint64
+		wantErr bool
+	}{
+		{"0B", 0, false},
+		{"512B", 512, false},
+		{"1.2kB", 1200, false},
+		{"3MB", 3_000_000, false},
+		{"12.5MiB", 13_107_200, false},
+		{"1GiB", 1 << 30, false},
+		{"2TB", 2_000_000_000_000, false},
+		{" 4KiB ", 4096, false},
+		{"--", 0, false},
+		{"", 0, false},
+		{"12XB", 0, true},
+		{"MiB", 0, true},
+		{"1.2.3kB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSizePairAndPercent(t *testing.T) {
+	used, total, err := parseSizePair("100MiB / 1GiB")
+	if err != nil || used != 100<<20 || total != 1<<30 {
+		t.Errorf("parseSizePair = %d, %d, %v", used, total, err)
+	}
+	if _, _, err := parseSizePair("100MiB"); err == nil {
+		t.Error("expected error for a size without a pair")
+	}
+
+	if p, err := parsePercent("12.50%"); err != nil || p != 12.5 {
+		t.Errorf("parsePercent = %v, %v", p, err)
+	}
+	if _, err := parsePercent("abc%"); err == nil {
+		t.Error("expected error for an invalid percentage")
+	}
+}
@@ -0,0 +1,324 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	defaultCrashLogLines   = 10
+	maxCrashLogLines       = 100
+	defaultCrashMinDeaths  = 3
+	defaultCrashLogsDeaths = 3
+)
+
+// oomWindow is how long before a die event an oom event may be and still
+// count as the cause of that death.
+const oomWindow = 5 * time.Second
+
+type detectCrashLoopsArgs struct {
+	Container     string `json:"container,omitempty" jsonschema:"only analyse this container (name or ID)"`
+	Project       string `json:"project,omitempty" jsonschema:"only analyse containers of this Compose project"`
+	Since         string `json:"since,omitempty" jsonschema:"start of the window: relative (e.g. 30m) or a timestamp (default: 1h)"`
+	MinDeaths     int    `json:"min_deaths,omitempty" jsonschema:"deaths within the window that make a crash loop (default: 3)"`
+	LogLines      int    `json:"log_lines,omitempty" jsonschema:"log lines to show before each death (default: 10, max: 100)"`
+	LogDeaths     int    `json:"log_deaths,omitempty" jsonschema:"how many of the most recent deaths per container to fetch logs for (default: 3)"`
+	AllContainers bool   `json:"all_containers,omitempty" jsonschema:"also report containers that did not die in the window"`
+}
+
+type crashLoopsOutput struct {
+	WindowStart   time.Time            `json:"window_start"`
+	WindowSeconds float64              `json:"window_seconds"`
+	Containers    []crashLoopContainer `json:"containers" jsonschema:"containers ordered by number of deaths, most first"`
+}
+
+type crashLoopContainer struct {
+	Container      string           `json:"container"`
+	ID             string           `json:"id"`
+	Verdict        string           `json:"verdict" jsonschema:"crash_loop, unstable, died or stable"`
+	Deaths         int              `json:"deaths"`
+	Starts         int              `json:"starts"`
+	Restarts       int              `json:"restarts" jsonschema:"explicit restart events, e.g. from docker restart"`
+	OOMKills       int              `json:"oom_kills"`
+	DeathsPerHour  float64          `json:"deaths_per_hour"`
+	ExitCodes      []exitCodeCount  `json:"exit_codes"`
+	SecondsBetween *metricSummary   `json:"seconds_between_deaths,omitempty" jsonschema:"time between consecutive deaths"`
+	UptimeSeconds  *metricSummary   `json:"uptime_seconds,omitempty" jsonschema:"time from each start to the following death"`
+	DeathEvents    []containerDeath `json:"death_events"`
+}
+
+type exitCodeCount struct {
+	ExitCode int `json:"exit_code"`
+	Count    int `json:"count"`
+}
+
+type containerDeath struct {
+	Time          time.Time `json:"time"`
+	ExitCode      int       `json:"exit_code"`
+	OOMKilled     bool      `json:"oom_killed"`
+	UptimeSeconds float64   `json:"uptime_seconds,omitempty" jsonschema:"seconds since the preceding start, when it is in the window"`
+	LastLogs      []string  `json:"last_logs,omitempty" jsonschema:"log lines written just before the death"`
+	LogsError     string    `json:"logs_error,omitempty"`
+}
+
+// crashHistory accumulates the events of one container.
+type crashHistory struct {
+	name, id  string
+	starts    []time.Time
+	restarts  int
+	lastStart time.Time
+	lastOOM   time.Time
+	deaths    []containerDeath
+}
+
+func (h *crashHistory) add(ev containerEvent) {
+	switch ev.Action {
+	case "start":
+		h.starts = append(h.starts, ev.Time)
+		h.lastStart = ev.Time
+	case "restart":
+		h.restarts++
+	case "oom":
+		h.lastOOM = ev.Time
+	case "die":
+		d := containerDeath{Time: ev.Time}
+		d.ExitCode, _ = strconv.Atoi(ev.Attributes["exitCode"])
+		if !h.lastOOM.IsZero() && ev.Time.Sub(h.lastOOM) <= oomWindow {
+			d.OOMKilled = true
+			h.lastOOM = time.Time{}
+		}
+		if !h.lastStart.IsZero() {
+			d.UptimeSeconds = ev.Time.Sub(h.lastStart).Seconds()
+			h.lastStart = time.Time{}
+		}
+		h.deaths = append(h.deaths, d)
+	}
+}
+
+func (h *crashHistory) summarize(window time.Duration, minDeaths int) crashLoopContainer {
+	c := crashLoopContainer{
+		Container:   h.name,
+		ID:          h.id,
+		Deaths:      len(h.deaths),
+		Starts:      len(h.starts),
+		Restarts:    h.restarts,
+		DeathEvents: h.deaths,
+	}
+	if window > 0 {
+		c.DeathsPerHour = float64(c.Deaths) / window.Hours()
+	}
+
+	codes := make(map[int]int)
+	var gaps, uptimes []float64
+	for i, d := range h.deaths {
+		codes[d.ExitCode]++
+		if d.OOMKilled {
+			c.OOMKills++
+		}
+		if i > 0 {
+			gaps = append(gaps, d.Time.Sub(h.deaths[i-1].Time).Seconds())
+		}
+		if d.UptimeSeconds > 0 {
+			uptimes = append(uptimes, d.UptimeSeconds)
+		}
+	}
+	for code, n := range codes {
+		c.ExitCodes = append(c.ExitCodes, exitCodeCount{ExitCode: code, Count: n})
+	}
+	sort.Slice(c.ExitCodes, func(i, j int) bool {
+		if c.ExitCodes[i].Count != c.ExitCodes[j].Count {
+			return c.ExitCodes[i].Count > c.ExitCodes[j].Count
+		}
+		return c.ExitCodes[i].ExitCode < c.ExitCodes[j].ExitCode
+	})
+	if len(gaps) > 0 {
+		s := summarize(gaps)
+		c.SecondsBetween = &s
+	}
+	if len(uptimes) > 0 {
+		s := summarize(uptimes)
+		c.UptimeSeconds = &s
+	}
+
+	switch {
+	case c.Deaths >= minDeaths:
+		c.Verdict = "crash_loop"
+	case c.Deaths > 1:
+		c.Verdict = "unstable"
+	case c.Deaths == 1:
+		c.Verdict = "died"
+	default:
+		c.Verdict = "stable"
+	}
+	return c
+}
+
+func handleDetectCrashLoops(ctx context.Context, exec docker.Executor, clk clock, args detectCrashLoopsArgs) (string, crashLoopsOutput, error) {
+	var out crashLoopsOutput
+	if args.Container != "" && args.Project != "" {
+		return "", out, fmt.Errorf("specify either container or project, not both")
+	}
+	since := args.Since
+	if since == "" {
+		since = "1h"
+	}
+	now := clk.Now()
+	start, err := parseTimeArg(since, now)
+	if err != nil {
+		return "", out, err
+	}
+	out.WindowStart = start
+	window := now.Sub(start)
+	out.WindowSeconds = window.Seconds()
+
+	minDeaths := args.MinDeaths
+	if minDeaths <= 0 {
+		minDeaths = defaultCrashMinDeaths
+	}
+	logLines := args.LogLines
+	if logLines <= 0 {
+		logLines = defaultCrashLogLines
+	}
+	if logLines > maxCrashLogLines {
+		logLines = maxCrashLogLines
+	}
+	logDeaths := args.LogDeaths
+	if logDeaths <= 0 {
+		logDeaths = defaultCrashLogsDeaths
+	}
+
+	cmdArgs := []string{"events", "--filter", "type=container"}
+	if args.Container != "" {
+		cmdArgs = append(cmdArgs, "--filter", "container="+args.Container)
+	}
+	if args.Project != "" {
+		cmdArgs = append(cmdArgs, "--filter", "label=com.docker.compose.project="+args.Project)
+	}
+	for _, action := range []string{"start", "die", "oom", "restart"} {
+		cmdArgs = append(cmdArgs, "--filter", "event="+action)
+	}
+	cmdArgs = append(cmdArgs, "--since", start.Format(time.RFC3339Nano), "--until", now.Format(time.RFC3339Nano), "--format", "{{json .}}")
+
+	output, err := exec.Exec(ctx, cmdArgs...)
+	if err != nil {
+		return "", out, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	histories := make(map[string]*crashHistory)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var event dockerEvent
+		if json.Unmarshal([]byte(line), &event) != nil {
+			continue
+		}
+		ev := newContainerEvent(event)
+		h, ok := histories[ev.ID]
+		if !ok {
+			h = &crashHistory{name: ev.Container, id: ev.ID}
+			histories[ev.ID] = h
+		}
+		h.add(ev)
+	}
+
+	for _, h := range histories {
+		c := h.summarize(window, minDeaths)
+		if c.Deaths == 0 && !args.AllContainers {
+			continue
+		}
+		// Fetch the logs leading up to the most recent deaths only, to bound
+		// the number of docker logs calls.
+		for i := max(0, len(c.DeathEvents)-logDeaths); i < len(c.DeathEvents); i++ {
+			d := &c.DeathEvents[i]
+			_, logs, err := handleGetLogs(ctx, exec, getLogsArgs{
+				Container: h.id,
+				Tail:      logLines,
+				Until:     d.Time.Format(time.RFC3339Nano),
+			})
+			if err != nil {
+				d.LogsError = err.Error()
+				continue
+			}
+			d.LastLogs = logs.Lines
+		}
+		out.Containers = append(out.Containers, c)
+	}
+	sort.Slice(out.Containers, func(i, j int) bool {
+		if out.Containers[i].Deaths != out.Containers[j].Deaths {
+			return out.Containers[i].Deaths > out.Containers[j].Deaths
+		}
+		return out.Containers[i].Container < out.Containers[j].Container
+	})
+
+	if len(out.Containers) == 0 {
+		return fmt.Sprintf("No container deaths since %s.", start.Format(time.RFC3339)), out, nil
+	}
+	return formatCrashLoops(out), out, nil
+}
+
+func formatCrashLoops(out crashLoopsOutput) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Container deaths since %s (%s window)\n", out.WindowStart.Format(time.RFC3339), time.Duration(out.WindowSeconds*float64(time.Second)).Round(time.Second)))
+	for _, c := range out.Containers {
+		b.WriteString(fmt.Sprintf("\n%s: %s, %d deaths, %d starts, %.1f deaths/hour", c.Container, strings.ToUpper(strings.ReplaceAll(c.Verdict, "_", " ")), c.Deaths, c.Starts, c.DeathsPerHour))
+		if c.OOMKills > 0 {
+			b.WriteString(fmt.Sprintf(", %d OOM kills", c.OOMKills))
+		}
+		b.WriteString("\n")
+		if len(c.ExitCodes) > 0 {
+			var codes []string
+			for _, e := range c.ExitCodes {
+				codes = append(codes, fmt.Sprintf("%d (x%d)", e.ExitCode, e.Count))
+			}
+			b.WriteString(fmt.Sprintf("  Exit codes:     %s\n", strings.Join(codes, ", ")))
+		}
+		if c.SecondsBetween != nil {
+			b.WriteString(fmt.Sprintf("  Between deaths: min %s, avg %s, max %s\n", formatSeconds(c.SecondsBetween.Min), formatSeconds(c.SecondsBetween.Avg), formatSeconds(c.SecondsBetween.Max)))
+		}
+		if c.UptimeSeconds != nil {
+			b.WriteString(fmt.Sprintf("  Uptime:         min %s, avg %s, max %s\n", formatSeconds(c.UptimeSeconds.Min), formatSeconds(c.UptimeSeconds.Avg), formatSeconds(c.UptimeSeconds.Max)))
+		}
+		for _, d := range c.DeathEvents {
+			if d.LastLogs == nil && d.LogsError == "" {
+				continue
+			}
+			oom := ""
+			if d.OOMKilled {
+				oom = ", OOM killed"
+			}
+			b.WriteString(fmt.Sprintf("  Died %s (exit %d%s):\n", d.Time.Format(time.RFC3339), d.ExitCode, oom))
+			if d.LogsError != "" {
+				b.WriteString(fmt.Sprintf("    (logs unavailable: %s)\n", d.LogsError))
+			}
+			for _, line := range d.LastLogs {
+				b.WriteString("    | " + line + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func formatSeconds(s float64) string {
+	return time.Duration(s * float64(time.Second)).Round(time.Second).String()
+}
+
+func registerDetectCrashLoops(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "detect_crash_loops",
+		Description: "Find crash-looping and flapping containers from die/start/oom/restart events over a window. Per container reports deaths per hour, exit codes, OOM kills, time between deaths and uptime before each death, plus the last log lines written before the most recent deaths.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args detectCrashLoopsArgs) (*mcp.CallToolResult, crashLoopsOutput, error) {
+		result, out, err := handleDetectCrashLoops(ctx, exec, realClock{}, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
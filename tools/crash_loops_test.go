@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const crashEventsCmd = "events --filter type=container --filter event=start --filter event=die --filter event=oom --filter event=restart --since 2023-12-31T23:00:00Z --until 2024-01-01T00:00:00Z --format {{json .}}"
+
+// crashEvent renders a docker event the given number of seconds before the
+// fake clock's start time.
+func crashEvent(action, id, name string, secondsAgo int64, exitCode string) string {
+	attrs := fmt.Sprintf(`"name":%q`, name)
+	if exitCode != "" {
+		attrs += fmt.Sprintf(`,"exitCode":%q`, exitCode)
+	}
+	t := int64(1704067200) - secondsAgo
+	return fmt.Sprintf(`{"status":%[1]q,"Action":%[1]q,"Type":"container","Actor":{"ID":%[2]q,"Attributes":{%[3]s}},"time":%[4]d,"timeNano":%[5]d}`, action, id, attrs, t, t*1e9)
+}
+
+func TestHandleDetectCrashLoops(t *testing.T) {
+	events := strings.Join([]string{
+		crashEvent("start", "aaa", "api", 3000, ""),
+		crashEvent("die", "aaa", "api", 2940, "1"),
+		crashEvent("start", "aaa", "api", 2930, ""),
+		crashEvent("oom", "aaa", "api", 2001, ""),
+		crashEvent("die", "aaa", "api", 2000, "137"),
+		crashEvent("start", "aaa", "api", 1990, ""),
+		crashEvent("die", "aaa", "api", 1000, "1"),
+		crashEvent("start", "bbb", "db", 500, ""),
+		crashEvent("die", "ccc", "worker", 100, "0"),
+	}, "\n")
+
+	mock := docker.NewMock()
+	mock.On(crashEventsCmd, events, nil)
+	mock.On("logs --tail 2 --until 2023-12-31T23:43:20Z aaa", "connecting to db\npanic: connection refused\n", nil)
+	mock.On("logs --tail 2 --until 2023-12-31T23:26:40Z aaa", "allocating buffers\n", nil)
+	mock.On("logs --tail 2 --until 2023-12-31T23:58:20Z ccc", "", fmt.Errorf("Error: No such container: ccc"))
+
+	result, out, err := handleDetectCrashLoops(context.Background(), mock, newFakeClock(), detectCrashLoopsArgs{LogLines: 2, LogDeaths: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(out.Containers) != 2 {
+		t.Fatalf("expected api and worker (db never died), got %+v", out.Containers)
+	}
+	api := out.Containers[0]
+	if api.Container != "api" || api.Verdict != "crash_loop" || api.Deaths != 3 || api.Starts != 3 || api.OOMKills != 1 {
+		t.Errorf("unexpected api summary: %+v", api)
+	}
+	if api.DeathsPerHour != 3 {
+		t.Errorf("expected 3 deaths per hour, got %v", api.DeathsPerHour)
+	}
+	if len(api.ExitCodes) != 2 || api.ExitCodes[0] != (exitCodeCount{ExitCode: 1, Count: 2}) {
+		t.Errorf("unexpected exit codes %+v", api.ExitCodes)
+	}
+	if api.SecondsBetween == nil || api.SecondsBetween.Min != 940 || api.SecondsBetween.Max != 1000 {
+		t.Errorf("unexpected time between deaths %+v", api.SecondsBetween)
+	}
+	if api.UptimeSeconds == nil || api.UptimeSeconds.Min != 60 || api.UptimeSeconds.Max != 990 {
+		t.Errorf("unexpected uptimes %+v", api.UptimeSeconds)
+	}
+	if api.DeathEvents[0].LastLogs != nil {
+		t.Error("expected logs only for the most recent deaths")
+	}
+	if !api.DeathEvents[1].OOMKilled || len(api.DeathEvents[2].LastLogs) != 2 {
+		t.Errorf("unexpected deaths %+v", api.DeathEvents)
+	}
+
+	worker := out.Containers[1]
+	if worker.Verdict != "died" || !strings.Contains(worker.DeathEvents[0].LogsError, "No such container") {
+		t.Errorf("unexpected worker summary: %+v", worker)
+	}
+
+	for _, want := range []string{"api: CRASH LOOP, 3 deaths", "1 OOM kills", "Exit codes:     1 (x2), 137 (x1)", "| panic: connection refused", "logs unavailable"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleDetectCrashLoops_NoDeaths(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On(strings.Replace(crashEventsCmd, "--filter type=container", "--filter type=container --filter label=com.docker.compose.project=shop", 1), crashEvent("start", "bbb", "db", 10, ""), nil)
+
+	result, out, err := handleDetectCrashLoops(context.Background(), mock, newFakeClock(), detectCrashLoopsArgs{Project: "shop"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Containers) != 0 || !strings.Contains(result, "No container deaths") {
+		t.Errorf("unexpected result %q / %+v", result, out)
+	}
+
+	_, out, err = handleDetectCrashLoops(context.Background(), mock, newFakeClock(), detectCrashLoopsArgs{Project: "shop", AllContainers: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Containers) != 1 || out.Containers[0].Verdict != "stable" {
+		t.Errorf("expected db reported as stable, got %+v", out.Containers)
+	}
+}
+
+func TestHandleDetectCrashLoops_Errors(t *testing.T) {
+	mock := docker.NewMock()
+	if _, _, err := handleDetectCrashLoops(context.Background(), mock, newFakeClock(), detectCrashLoopsArgs{Container: "a", Project: "b"}); err == nil {
+		t.Error("expected error for container and project together")
+	}
+	if _, _, err := handleDetectCrashLoops(context.Background(), mock, newFakeClock(), detectCrashLoopsArgs{Since: "last week"}); err == nil {
+		t.Error("expected error for an invalid since")
+	}
+	mock.On(crashEventsCmd, "", fmt.Errorf("daemon connection refused"))
+	if _, _, err := handleDetectCrashLoops(context.Background(), mock, newFakeClock(), detectCrashLoopsArgs{}); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected docker error, got %v", err)
+	}
+}
@@ -0,0 +1,531 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	diagnoseLogTail      = 200
+	diagnoseErrorLines   = 20
+	diagnoseEventsWindow = "1h"
+)
+
+// errorLinePattern matches log lines that look like errors. diagnose_container
+// and project_status both count lines with it, so they agree on the same logs.
+var errorLinePattern = regexp.MustCompile(`(?i)\b(error|err|fatal|panic|exception|traceback|critical|crit|emerg|emergency|failed|failure|refused|denied|segmentation fault|out of memory|oom|killed)\b`)
+
+// Cause severities, most urgent first.
+const (
+	severityHigh   = "high"
+	severityMedium = "medium"
+	severityLow    = "low"
+	severityInfo   = "info"
+)
+
+var severityRank = map[string]int{severityHigh: 0, severityMedium: 1, severityLow: 2, severityInfo: 3}
+
+type diagnoseContainerArgs struct {
+	Container string `json:"container" jsonschema:"container name or ID to diagnose"`
+}
+
+type diagnoseOutput struct {
+	Container     string           `json:"container"`
+	ID            string           `json:"id"`
+	Image         string           `json:"image"`
+	State         string           `json:"state"`
+	ExitCode      int              `json:"exit_code"`
+	Exit          *exitExplanation `json:"exit,omitempty" jsonschema:"explanation of the last exit, when not running"`
+	OOMKilled     bool             `json:"oom_killed"`
+	Error         string           `json:"error,omitempty" jsonschema:"error docker recorded when starting the container"`
+	StartedAt     string           `json:"started_at,omitempty"`
+	FinishedAt    string           `json:"finished_at,omitempty"`
+	RestartCount  int              `json:"restart_count"`
+	RestartPolicy string           `json:"restart_policy"`
+	Health        *diagnoseHealth  `json:"health,omitempty"`
+	Ports         []inspectPort    `json:"ports" jsonschema:"published ports"`
+	MemLimitBytes int64            `json:"mem_limit_bytes,omitempty" jsonschema:"memory limit from HostConfig, 0 when unlimited"`
+	Stats         *docker.Stats    `json:"stats,omitempty" jsonschema:"resource usage snapshot, when running"`
+	Events        []containerEvent `json:"events" jsonschema:"lifecycle events in the last hour"`
+	ErrorLines    []string         `json:"error_lines" jsonschema:"most recent log lines that look like errors"`
+	ErrorCount    int              `json:"error_count" jsonschema:"error-looking lines among the scanned log lines"`
+	LogLines      int              `json:"log_lines_scanned"`
+	Causes        []likelyCause    `json:"likely_causes" jsonschema:"likely causes, most urgent first"`
+	Warnings      []string         `json:"warnings,omitempty" jsonschema:"data sources that could not be read"`
+}
+
+type diagnoseHealth struct {
+	Status        string `json:"status"`
+	FailingStreak int    `json:"failing_streak"`
+	LastOutput    string `json:"last_output,omitempty"`
+	LastExitCode  int    `json:"last_exit_code"`
+}
+
+type likelyCause struct {
+	Severity   string `json:"severity" jsonschema:"high, medium, low or info"`
+	Cause      string `json:"cause"`
+	Evidence   string `json:"evidence"`
+	Suggestion string `json:"suggestion"`
+}
+
+// diagnoseData is everything gathered about a container.
+type diagnoseData struct {
+	details  containerDetails
+	ports    []inspectPort
+	stats    *docker.Stats
+	events   []containerEvent
+	logs     []string
+	warnings []string
+}
+
+// gatherDiagnoseData reads inspect, events, logs and stats concurrently.
+// Only a failing inspect is an error; other sources become warnings.
+func gatherDiagnoseData(ctx context.Context, exec docker.Executor, container string) (*diagnoseData, error) {
+	var (
+		d          diagnoseData
+		inspectErr error
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+	)
+	warn := func(format string, args ...any) {
+		mu.Lock()
+		d.warnings = append(d.warnings, fmt.Sprintf(format, args...))
+		mu.Unlock()
+	}
+
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		out, err := exec.Exec(ctx, "inspect", container)
+		if err != nil {
+			inspectErr = fmt.Errorf("failed to inspect container %q: %w", container, err)
+			return
+		}
+		var typed []containerDetails
+		var raw []map[string]any
+		if err := json.Unmarshal([]byte(out), &typed); err != nil || len(typed) == 0 {
+			inspectErr = fmt.Errorf("failed to parse inspect output for container %q", container)
+			return
+		}
+		d.details = typed[0]
+		if json.Unmarshal([]byte(out), &raw) == nil && len(raw) > 0 {
+			d.ports = inspectPortMappings(raw[0]).Exposed
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		_, out, err := handleContainerEvents(ctx, exec, containerEventsArgs{Container: container, Since: diagnoseEventsWindow})
+		if err != nil {
+			warn("events: %v", err)
+			return
+		}
+		d.events = out.Events
+	}()
+	go func() {
+		defer wg.Done()
+		_, out, err := handleGetLogs(ctx, exec, getLogsArgs{Container: container, Tail: diagnoseLogTail})
+		if err != nil {
+			warn("logs: %v", err)
+			return
+		}
+		d.logs = out.Lines
+	}()
+	go func() {
+		defer wg.Done()
+		stats, _, err := readStats(ctx, exec, container)
+		if err != nil {
+			warn("stats: %v", err)
+			return
+		}
+		if len(stats) > 0 {
+			d.stats = &stats[0]
+		}
+	}()
+	wg.Wait()
+
+	if inspectErr != nil {
+		return nil, inspectErr
+	}
+	sort.Strings(d.warnings)
+	return &d, nil
+}
+
+// errorLines returns the number of error-looking lines and the last few.
+func errorLines(lines []string, keep int) (int, []string) {
+	var matched []string
+	for _, line := range lines {
+		if errorLinePattern.MatchString(line) {
+			matched = append(matched, line)
+		}
+	}
+	count := len(matched)
+	if len(matched) > keep {
+		matched = matched[len(matched)-keep:]
+	}
+	return count, matched
+}
+
+// countActions counts events by action.
+func countActions(events []containerEvent) map[string]int {
+	counts := make(map[string]int)
+	for _, ev := range events {
+		action := ev.Action
+		// health_status events carry the status in the action, e.g.
+		// "health_status: unhealthy".
+		if strings.HasPrefix(action, "health_status") {
+			action = "health_status"
+		}
+		counts[action]++
+	}
+	return counts
+}
+
+// buildDiagnosis assembles the report from gathered data.
+func buildDiagnosis(container string, d *diagnoseData) diagnoseOutput {
+	det := d.details
+	out := diagnoseOutput{
+		Container:     strings.TrimPrefix(det.Name, "/"),
+		ID:            det.ID,
+		Image:         det.Config.Image,
+		State:         det.State.Status,
+		ExitCode:      det.State.ExitCode,
+		OOMKilled:     det.State.OOMKilled,
+		Error:         det.State.Error,
+		StartedAt:     det.State.StartedAt,
+		RestartCount:  det.RestartCount,
+		RestartPolicy: det.restartPolicy(),
+		Ports:         d.ports,
+		MemLimitBytes: det.HostConfig.Memory,
+		Events:        d.events,
+		LogLines:      len(d.logs),
+		Warnings:      d.warnings,
+	}
+	if out.Container == "" {
+		out.Container = container
+	}
+	if out.Exit = explainExit(&det); out.Exit != nil {
+		out.FinishedAt = out.Exit.FinishedAt
+	}
+	if det.State.Running {
+		out.Stats = d.stats
+	}
+	if h := det.State.Health; h != nil {
+		out.Health = &diagnoseHealth{Status: h.Status, FailingStreak: h.FailingStreak}
+		if n := len(h.Log); n > 0 {
+			out.Health.LastOutput = strings.TrimSpace(h.Log[n-1].Output)
+			out.Health.LastExitCode = h.Log[n-1].ExitCode
+		}
+	}
+	if out.Ports == nil {
+		out.Ports = []inspectPort{}
+	}
+	out.ErrorCount, out.ErrorLines = errorLines(d.logs, diagnoseErrorLines)
+	out.Causes = likelyCauses(out)
+	return out
+}
+
+// likelyCauses runs every heuristic over the report and orders the findings
+// by severity.
+func likelyCauses(out diagnoseOutput) []likelyCause {
+	var causes []likelyCause
+	for _, h := range diagnoseHeuristics {
+		causes = append(causes, h(out)...)
+	}
+	if len(causes) == 0 {
+		causes = append(causes, likelyCause{
+			Severity:   severityInfo,
+			Cause:      "no problems detected",
+			Evidence:   fmt.Sprintf("container is %s with no errors in the last %d log lines", describeDiagnosedState(out), out.LogLines),
+			Suggestion: "if the application still misbehaves, check its dependencies with diagnose_container or project_status",
+		})
+	}
+	sort.SliceStable(causes, func(i, j int) bool { return severityRank[causes[i].Severity] < severityRank[causes[j].Severity] })
+	return causes
+}
+
+func describeDiagnosedState(out diagnoseOutput) string {
+	if out.Health != nil && out.Health.Status != "" {
+		return fmt.Sprintf("%s (%s)", out.State, out.Health.Status)
+	}
+	return out.State
+}
+
+func lastErrorLine(out diagnoseOutput) string {
+	if len(out.ErrorLines) == 0 {
+		return ""
+	}
+	return out.ErrorLines[len(out.ErrorLines)-1]
+}
+
+// diagnoseHeuristics each inspect one aspect of a report. Causes of equal
+// severity keep this order, so broader explanations come first.
+var diagnoseHeuristics = []func(diagnoseOutput) []likelyCause{
+	oomHeuristic,
+	restartLoopHeuristic,
+	startErrorHeuristic,
+	exitCodeHeuristic,
+	healthHeuristic,
+	resourcePressureHeuristic,
+}
+
+func oomHeuristic(out diagnoseOutput) []likelyCause {
+	ooms := countActions(out.Events)["oom"]
+	if !out.OOMKilled && ooms == 0 {
+		return nil
+	}
+	evidence := "State.OOMKilled is true"
+	if !out.OOMKilled {
+		evidence = fmt.Sprintf("%d oom events in the last hour", ooms)
+	}
+	limit := "no memory limit is set, so the host or VM ran out of memory"
+	if out.MemLimitBytes > 0 {
+		limit = fmt.Sprintf("memory limit is %s", binarySize(uint64(out.MemLimitBytes)))
+	}
+	return []likelyCause{{
+		Severity:   severityHigh,
+		Cause:      "killed by the out-of-memory killer",
+		Evidence:   evidence + "; " + limit,
+		Suggestion: "raise the memory limit or find the leak with stats_sample / stats_history",
+	}}
+}
+
+func exitCodeHeuristic(out diagnoseOutput) []likelyCause {
+	// Start errors are covered by startErrorHeuristic.
+	if out.Exit == nil || out.Exit.Error != "" {
+		return nil
+	}
+	code := out.ExitCode
+	evidence := out.Exit.describe()
+	switch {
+	case code == 0:
+		if out.RestartPolicy != "always" && out.RestartPolicy != "unless-stopped" {
+			return []likelyCause{{
+				Severity:   severityLow,
+				Cause:      "the main process finished",
+				Evidence:   evidence,
+				Suggestion: "if it should keep running, check that the command does not exit or daemonize",
+			}}
+		}
+		return nil
+	case code == 137 && out.OOMKilled:
+		// Covered by the OOM heuristic.
+		return nil
+	case code == 137:
+		return []likelyCause{{
+			Severity:   severityMedium,
+			Cause:      "killed with SIGKILL",
+			Evidence:   evidence + "; not flagged as OOM",
+			Suggestion: out.Exit.LikelyCause + "; check container_events for who killed it",
+		}}
+	case code == 143 || code == 130:
+		return []likelyCause{{
+			Severity:   severityLow,
+			Cause:      "stopped by a signal",
+			Evidence:   evidence,
+			Suggestion: "usually an intentional docker stop; check container_events for who stopped it",
+		}}
+	case code == 126 || code == 127:
+		return []likelyCause{{
+			Severity:   severityHigh,
+			Cause:      "entrypoint or command is broken",
+			Evidence:   evidence,
+			Suggestion: "check the image's ENTRYPOINT/CMD and that the binary exists and is executable",
+		}}
+	case code == 139 || code == 134:
+		return []likelyCause{{
+			Severity:   severityHigh,
+			Cause:      "the process crashed",
+			Evidence:   evidence,
+			Suggestion: "look for native crashes in the logs; check library/architecture mismatches in the image",
+		}}
+	default:
+		c := likelyCause{
+			Severity:   severityHigh,
+			Cause:      "the application exited with an error",
+			Evidence:   evidence,
+			Suggestion: "read the error lines below; check configuration and dependencies it connects to",
+		}
+		if line := lastErrorLine(out); line != "" {
+			c.Evidence += "; last error: " + line
+		}
+		return []likelyCause{c}
+	}
+}
+
+func startErrorHeuristic(out diagnoseOutput) []likelyCause {
+	if out.Error == "" {
+		return nil
+	}
+	// startErrorCause returns the message itself when it does not recognize it.
+	cause := startErrorCause(out.Error)
+	if cause == out.Error {
+		cause = "docker could not start the container"
+	}
+	return []likelyCause{{
+		Severity:   severityHigh,
+		Cause:      cause,
+		Evidence:   out.Error,
+		Suggestion: "fix the reported problem, then start the container again",
+	}}
+}
+
+func restartLoopHeuristic(out diagnoseOutput) []likelyCause {
+	dies := countActions(out.Events)["die"]
+	if dies < 3 && !(out.State == "restarting" && out.RestartCount > 0) {
+		return nil
+	}
+	evidence := fmt.Sprintf("restart count %d, %d die events in the last hour", out.RestartCount, dies)
+	if line := lastErrorLine(out); line != "" {
+		evidence += "; last error: " + line
+	}
+	return []likelyCause{{
+		Severity:   severityHigh,
+		Cause:      "crash loop: the container keeps dying and being restarted",
+		Evidence:   evidence,
+		Suggestion: "use detect_crash_loops for exit codes and logs before each death",
+	}}
+}
+
+func healthHeuristic(out diagnoseOutput) []likelyCause {
+	if out.Health == nil || out.Health.Status != "unhealthy" {
+		return nil
+	}
+	evidence := fmt.Sprintf("%d consecutive failed health checks", out.Health.FailingStreak)
+	if out.Health.LastOutput != "" {
+		evidence += fmt.Sprintf("; last check exited %d: %s", out.Health.LastExitCode, out.Health.LastOutput)
+	}
+	severity := severityMedium
+	if out.State == "running" && out.ErrorCount > 0 {
+		severity = severityHigh
+	}
+	return []likelyCause{{
+		Severity:   severity,
+		Cause:      "health check is failing",
+		Evidence:   evidence,
+		Suggestion: "run the health check command with container_exec to see why it fails",
+	}}
+}
+
+func resourcePressureHeuristic(out diagnoseOutput) []likelyCause {
+	s := out.Stats
+	if s == nil {
+		return nil
+	}
+	var causes []likelyCause
+	if s.MemLimit > 0 && s.MemPercent >= 90 {
+		causes = append(causes, likelyCause{
+			Severity:   severityHigh,
+			Cause:      "memory is close to the limit",
+			Evidence:   fmt.Sprintf("using %s of %s (%.1f%%)", binarySize(s.MemUsage), binarySize(s.MemLimit), s.MemPercent),
+			Suggestion: "raise the memory limit or check for a leak with stats_sample",
+		})
+	}
+	if s.CPUPercent >= 90 {
+		causes = append(causes, likelyCause{
+			Severity:   severityMedium,
+			Cause:      "CPU is saturated",
+			Evidence:   fmt.Sprintf("CPU at %.1f%%", s.CPUPercent),
+			Suggestion: "check for busy loops or raise the CPU limit",
+		})
+	}
+	return causes
+}
+
+func handleDiagnoseContainer(ctx context.Context, exec docker.Executor, args diagnoseContainerArgs) (string, diagnoseOutput, error) {
+	out := diagnoseOutput{Container: args.Container}
+	if args.Container == "" {
+		return "", out, fmt.Errorf("container name or ID is required")
+	}
+	data, err := gatherDiagnoseData(ctx, exec, args.Container)
+	if err != nil {
+		return "", out, err
+	}
+	out = buildDiagnosis(args.Container, data)
+	return formatDiagnosis(out), out, nil
+}
+
+func formatDiagnosis(out diagnoseOutput) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("=== Diagnosis: %s ===\n", out.Container))
+	b.WriteString(fmt.Sprintf("Image:    %s\n", out.Image))
+	state := describeDiagnosedState(out)
+	if out.Exit != nil {
+		state += ", " + out.Exit.describe()
+	}
+	b.WriteString(fmt.Sprintf("State:    %s\n", state))
+	if out.OOMKilled {
+		b.WriteString("OOM:      killed by the OOM killer\n")
+	}
+	if out.Error != "" {
+		b.WriteString(fmt.Sprintf("Error:    %s\n", out.Error))
+	}
+	b.WriteString(fmt.Sprintf("Restarts: %d (policy: %s)\n", out.RestartCount, out.RestartPolicy))
+	if out.Exit != nil {
+		b.WriteString(fmt.Sprintf("Outlook:  %s\n", out.Exit.RestartNote))
+	}
+	if out.Health != nil {
+		b.WriteString(fmt.Sprintf("Health:   %s, failing streak %d\n", out.Health.Status, out.Health.FailingStreak))
+	}
+	if s := out.Stats; s != nil {
+		b.WriteString(fmt.Sprintf("Usage:    CPU %.1f%%, memory %s / %s (%.1f%%), %d PIDs\n", s.CPUPercent, binarySize(s.MemUsage), binarySize(s.MemLimit), s.MemPercent, s.PIDs))
+	}
+	var ports []string
+	for _, p := range out.Ports {
+		if p.HostPort != "" {
+			ports = append(ports, fmt.Sprintf("%s:%s->%s", p.HostIP, p.HostPort, p.ContainerPort))
+		}
+	}
+	if len(ports) > 0 {
+		b.WriteString(fmt.Sprintf("Ports:    %s\n", strings.Join(ports, ", ")))
+	}
+
+	b.WriteString("\nLikely causes:\n")
+	for i, c := range out.Causes {
+		b.WriteString(fmt.Sprintf("  %d. [%s] %s\n     Evidence: %s\n     Next: %s\n", i+1, c.Severity, c.Cause, c.Evidence, c.Suggestion))
+	}
+
+	if counts := countActions(out.Events); len(out.Events) > 0 {
+		actions := make([]string, 0, len(counts))
+		for action, n := range counts {
+			actions = append(actions, fmt.Sprintf("%s x%d", action, n))
+		}
+		sort.Strings(actions)
+		b.WriteString(fmt.Sprintf("\nEvents (last hour): %s\n", strings.Join(actions, ", ")))
+	}
+	if len(out.ErrorLines) > 0 {
+		b.WriteString(fmt.Sprintf("\nError lines (%d of the last %d log lines):\n", out.ErrorCount, out.LogLines))
+		for _, line := range out.ErrorLines {
+			b.WriteString("  | " + line + "\n")
+		}
+	}
+	if len(out.Warnings) > 0 {
+		b.WriteString("\nIncomplete data:\n")
+		for _, w := range out.Warnings {
+			b.WriteString("  " + w + "\n")
+		}
+	}
+	return b.String()
+}
+
+func registerDiagnoseContainer(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "diagnose_container",
+		Description: "One-shot diagnosis of a container: reads inspect, health, recent events, logs and stats in parallel and correlates them into a report with state, exit code meaning, OOM kill, restart count, health streak, error log lines, resource pressure, port bindings and a prioritized list of likely causes.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args diagnoseContainerArgs) (*mcp.CallToolResult, diagnoseOutput, error) {
+		result, out, err := handleDiagnoseContainer(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
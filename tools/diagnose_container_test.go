@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	inspectRunning = `[{"Id":"abc123","Name":"/api","RestartCount":0,
+		"State":{"Status":"running","Running":true,"ExitCode":0,"StartedAt":"2024-01-01T00:00:00Z"},
+		"HostConfig":{"Memory":0,"RestartPolicy":{"Name":"no"}},
+		"Config":{"Image":"api:latest","ExposedPorts":{"8080/tcp":{}}},
+		"NetworkSettings":{"Ports":{"8080/tcp":[{"HostIp":"0.0.0.0","HostPort":"8080"}]}}}]`
+	inspectOOM = `[{"Id":"abc123","Name":"/api","RestartCount":2,
+		"State":{"Status":"exited","ExitCode":137,"OOMKilled":true,"FinishedAt":"2024-01-01T00:10:00Z"},
+		"HostConfig":{"Memory":268435456,"RestartPolicy":{"Name":"no"}},
+		"Config":{"Image":"api:latest"}}]`
+	inspectSIGKILL = `[{"Id":"abc123","Name":"/api",
+		"State":{"Status":"exited","ExitCode":137},
+		"HostConfig":{"RestartPolicy":{"Name":"no"}},"Config":{"Image":"api:latest"}}]`
+	inspectAppError = `[{"Id":"abc123","Name":"/api",
+		"State":{"Status":"exited","ExitCode":1},
+		"HostConfig":{"RestartPolicy":{"Name":"no"}},"Config":{"Image":"api:latest"}}]`
+	inspectBadCommand = `[{"Id":"abc123","Name":"/api",
+		"State":{"Status":"exited","ExitCode":127},
+		"HostConfig":{"RestartPolicy":{"Name":"no"}},"Config":{"Image":"api:latest"}}]`
+	inspectPortInUse = `[{"Id":"abc123","Name":"/api",
+		"State":{"Status":"created","ExitCode":128,"Error":"driver failed programming external connectivity on endpoint api: Bind for 0.0.0.0:8080 failed: port is already allocated"},
+		"HostConfig":{"RestartPolicy":{"Name":"no"}},"Config":{"Image":"api:latest"}}]`
+	inspectRestarting = `[{"Id":"abc123","Name":"/api","RestartCount":5,
+		"State":{"Status":"restarting","Restarting":true,"ExitCode":1},
+		"HostConfig":{"RestartPolicy":{"Name":"always"}},"Config":{"Image":"api:latest"}}]`
+	inspectUnhealthy = `[{"Id":"abc123","Name":"/api",
+		"State":{"Status":"running","Running":true,"Health":{"Status":"unhealthy","FailingStreak":4,
+			"Log":[{"ExitCode":1,"Output":"curl: (7) Failed to connect to localhost port 8080\n"}]}},
+		"HostConfig":{"RestartPolicy":{"Name":"no"}},"Config":{"Image":"api:latest"}}]`
+)
+
+const diagnoseEventsCmd = "events --filter type=container --filter container=api --since 1h --until 0s --format {{json .}}"
+
+func diagnoseMock(inspect, events, logs, stats string) *docker.Mock {
+	mock := docker.NewMock()
+	mock.On("inspect api", inspect, nil)
+	mock.On(diagnoseEventsCmd, events, nil)
+	mock.On("logs --tail 200 api", logs, nil)
+	mock.On("stats --no-stream --format {{json .}} api", stats, nil)
+	return mock
+}
+
+func diagnoseEvent(action string) string {
+	return fmt.Sprintf(`{"status":%[1]q,"Action":%[1]q,"Type":"container","Actor":{"ID":"abc123","Attributes":{"name":"api"}},"time":1704067200}`, action)
+}
+
+func TestHandleDiagnoseContainer_Heuristics(t *testing.T) {
+	idleStats := `{"Name":"api","ID":"abc123","CPUPerc":"1.00%","MemUsage":"50MiB / 1GiB","MemPerc":"4.88%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"3"}`
+	tests := []struct {
+		name      string
+		inspect   string
+		events    string
+		logs      string
+		stats     string
+		wantCause string
+		severity  string
+	}{
+		{"healthy", inspectRunning, "", "listening on :8080\n", idleStats, "no problems detected", severityInfo},
+		{"oom", inspectOOM, diagnoseEvent("oom"), "allocating\n", "", "out-of-memory", severityHigh},
+		{"sigkill", inspectSIGKILL, "", "", "", "killed with SIGKILL", severityMedium},
+		{"app error", inspectAppError, "", "starting\nError: config.yaml not found\n", "", "application exited with an error", severityHigh},
+		{"bad command", inspectBadCommand, "", "exec: \"serve\": executable file not found\n", "", "entrypoint or command is broken", severityHigh},
+		{"port in use", inspectPortInUse, "", "", "", "a published port is already in use", severityHigh},
+		{"restart loop", inspectRestarting, strings.Join([]string{diagnoseEvent("die"), diagnoseEvent("start"), diagnoseEvent("die")}, "\n"), "panic: boom\n", "", "crash loop", severityHigh},
+		{"unhealthy", inspectUnhealthy, diagnoseEvent("health_status: unhealthy"), "", idleStats, "health check is failing", severityMedium},
+		{"memory pressure", inspectRunning, "", "", `{"Name":"api","ID":"abc123","CPUPerc":"3.00%","MemUsage":"970MiB / 1GiB","MemPerc":"94.73%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"3"}`, "memory is close to the limit", severityHigh},
+		{"cpu saturated", inspectRunning, "", "", `{"Name":"api","ID":"abc123","CPUPerc":"180.00%","MemUsage":"50MiB / 1GiB","MemPerc":"4.88%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"3"}`, "CPU is saturated", severityMedium},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := diagnoseMock(tt.inspect, tt.events, tt.logs, tt.stats)
+			_, out, err := handleDiagnoseContainer(context.Background(), mock, diagnoseContainerArgs{Container: "api"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(out.Causes) == 0 {
+				t.Fatal("expected at least one cause")
+			}
+			top := out.Causes[0]
+			if !strings.Contains(top.Cause, tt.wantCause) || top.Severity != tt.severity {
+				t.Errorf("expected top cause %q (%s), got %+v", tt.wantCause, tt.severity, out.Causes)
+			}
+		})
+	}
+}
+
+func TestHandleDiagnoseContainer_Report(t *testing.T) {
+	mock := diagnoseMock(inspectOOM, diagnoseEvent("oom")+"\n"+diagnoseEvent("die"), "starting\nERROR cache warmup failed\nfatal: out of memory\n", "")
+
+	result, out, err := handleDiagnoseContainer(context.Background(), mock, diagnoseContainerArgs{Container: "api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Container != "api" || out.ExitCode != 137 || !out.OOMKilled || out.RestartCount != 2 || out.MemLimitBytes != 268435456 {
+		t.Errorf("unexpected report %+v", out)
+	}
+	if out.Exit == nil || out.Exit.Signal != "SIGKILL" || out.FinishedAt == "" {
+		t.Errorf("expected exit details for a stopped container, got %+v", out)
+	}
+	if out.ErrorCount != 2 || len(out.ErrorLines) != 2 || out.LogLines != 3 {
+		t.Errorf("unexpected error lines %d %v of %d", out.ErrorCount, out.ErrorLines, out.LogLines)
+	}
+	if !strings.Contains(out.Causes[0].Evidence, "256MiB") {
+		t.Errorf("expected the memory limit in the evidence, got %q", out.Causes[0].Evidence)
+	}
+	for _, want := range []string{"=== Diagnosis: api ===", "exit 137 (SIGKILL)", "OOM:", "Events (last hour): die x1, oom x1", "| fatal: out of memory"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleDiagnoseContainer_PortsAndPartialFailure(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("inspect api", inspectRunning, nil)
+	mock.On(diagnoseEventsCmd, "", fmt.Errorf("events unavailable"))
+	mock.On("logs --tail 200 api", "", fmt.Errorf("logs unavailable"))
+	mock.On("stats --no-stream --format {{json .}} api", "", fmt.Errorf("stats unavailable"))
+
+	result, out, err := handleDiagnoseContainer(context.Background(), mock, diagnoseContainerArgs{Container: "api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Warnings) != 3 {
+		t.Errorf("expected 3 warnings, got %v", out.Warnings)
+	}
+	if len(out.Ports) != 1 || out.Ports[0].HostPort != "8080" {
+		t.Errorf("unexpected ports %+v", out.Ports)
+	}
+	if !strings.Contains(result, "Ports:    0.0.0.0:8080->8080/tcp") || !strings.Contains(result, "Incomplete data:") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+}
+
+func TestHandleDiagnoseContainer_Errors(t *testing.T) {
+	if _, _, err := handleDiagnoseContainer(context.Background(), docker.NewMock(), diagnoseContainerArgs{}); err == nil {
+		t.Error("expected error for missing container")
+	}
+
+	mock := diagnoseMock("", "", "", "")
+	mock.On("inspect api", "", fmt.Errorf("Error: No such object: api"))
+	if _, _, err := handleDiagnoseContainer(context.Background(), mock, diagnoseContainerArgs{Container: "api"}); err == nil || !strings.Contains(err.Error(), "No such object") {
+		t.Errorf("expected inspect error, got %v", err)
+	}
+}
@@ -0,0 +1,383 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// containerDetails is the subset of docker inspect used to explain and
+// diagnose container state.
+type containerDetails struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	Image        string `json:"Image"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		Status     string       `json:"Status"`
+		Running    bool         `json:"Running"`
+		Paused     bool         `json:"Paused"`
+		Restarting bool         `json:"Restarting"`
+		OOMKilled  bool         `json:"OOMKilled"`
+		Dead       bool         `json:"Dead"`
+		ExitCode   int          `json:"ExitCode"`
+		Error      string       `json:"Error"`
+		StartedAt  string       `json:"StartedAt"`
+		FinishedAt string       `json:"FinishedAt"`
+		Health     *healthState `json:"Health"`
+	} `json:"State"`
+	HostConfig struct {
+		Memory        int64 `json:"Memory"`
+		RestartPolicy struct {
+			Name              string `json:"Name"`
+			MaximumRetryCount int    `json:"MaximumRetryCount"`
+		} `json:"RestartPolicy"`
+	} `json:"HostConfig"`
+	Config struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+}
+
+// restartPolicy renders the restart policy the way docker run accepts it,
+// e.g. "on-failure:5".
+func (d *containerDetails) restartPolicy() string {
+	p := d.HostConfig.RestartPolicy
+	switch {
+	case p.Name == "":
+		return "no"
+	case p.Name == "on-failure" && p.MaximumRetryCount > 0:
+		return fmt.Sprintf("on-failure:%d", p.MaximumRetryCount)
+	default:
+		return p.Name
+	}
+}
+
+// inspectDetails runs docker inspect for one or more containers and decodes
+// the result.
+func inspectDetails(ctx context.Context, exec docker.Executor, containers ...string) ([]containerDetails, error) {
+	out, err := exec.Exec(ctx, append([]string{"inspect"}, containers...)...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDetails(out)
+}
+
+// decodeDetails decodes the JSON array docker inspect prints.
+func decodeDetails(out string) ([]containerDetails, error) {
+	var details []containerDetails
+	if err := json.Unmarshal([]byte(out), &details); err != nil {
+		return nil, fmt.Errorf("failed to parse inspect JSON: %w", err)
+	}
+	return details, nil
+}
+
+// isNoSuchObject reports whether a docker inspect failed because some of
+// the objects it names no longer exist. docker still prints the others.
+func isNoSuchObject(err error) bool {
+	return strings.Contains(err.Error(), "No such object")
+}
+
+// allContainerDetails inspects every container, running or stopped. It
+// lists containers once and inspects them in a single call with
+// inspectExisting.
+func allContainerDetails(ctx context.Context, exec docker.Executor) ([]containerDetails, error) {
+	containers, err := psContainers(ctx, exec, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	if len(containers) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(containers))
+	for i, c := range containers {
+		ids[i] = c.ID
+	}
+	details, err := inspectExisting(ctx, exec, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect containers: %w", err)
+	}
+	return details, nil
+}
+
+// inspectExisting is inspectDetails for containers listed earlier, some of
+// which may have been removed since; those are skipped.
+func inspectExisting(ctx context.Context, exec docker.Executor, containers ...string) ([]containerDetails, error) {
+	out, err := exec.Exec(ctx, append([]string{"inspect"}, containers...)...)
+	if err != nil && !isNoSuchObject(err) {
+		return nil, err
+	}
+	if err != nil && strings.TrimSpace(out) == "" {
+		// Every container went away.
+		return nil, nil
+	}
+	return decodeDetails(out)
+}
+
+// exitExplanation is a structured reading of why a container stopped and
+// what happens next.
+type exitExplanation struct {
+	ExitCode      int      `json:"exit_code"`
+	Signal        string   `json:"signal,omitempty" jsonschema:"signal that ended the process, e.g. SIGKILL, for exit codes 128+n"`
+	OOMKilled     bool     `json:"oom_killed"`
+	Meaning       string   `json:"meaning" jsonschema:"what the exit code means"`
+	LikelyCause   string   `json:"likely_cause"`
+	Error         string   `json:"error,omitempty" jsonschema:"error docker recorded when starting the container"`
+	FinishedAt    string   `json:"finished_at,omitempty"`
+	MemLimitBytes int64    `json:"mem_limit_bytes,omitempty" jsonschema:"memory limit from HostConfig, 0 when unlimited"`
+	RestartPolicy string   `json:"restart_policy"`
+	WillRestart   bool     `json:"will_restart" jsonschema:"whether docker will start the container again by itself"`
+	RestartNote   string   `json:"restart_note" jsonschema:"how the restart policy applies to this exit"`
+	NextTools     []string `json:"next_tools" jsonschema:"tools that help investigate further"`
+}
+
+// signalNames maps the signals containers commonly die from.
+var signalNames = map[int]string{
+	1: "SIGHUP", 2: "SIGINT", 3: "SIGQUIT", 4: "SIGILL", 5: "SIGTRAP", 6: "SIGABRT",
+	7: "SIGBUS", 8: "SIGFPE", 9: "SIGKILL", 10: "SIGUSR1", 11: "SIGSEGV", 12: "SIGUSR2",
+	13: "SIGPIPE", 14: "SIGALRM", 15: "SIGTERM",
+}
+
+// exitSignal returns the signal name for an exit code of 128+n, or "".
+func exitSignal(code int) string {
+	if code <= 128 || code > 128+64 {
+		return ""
+	}
+	if name, ok := signalNames[code-128]; ok {
+		return name
+	}
+	return fmt.Sprintf("signal %d", code-128)
+}
+
+// explainExit explains the last exit of a stopped, restarting or
+// never-started container. It returns nil for containers that are running
+// normally or were created without error.
+func explainExit(d *containerDetails) *exitExplanation {
+	st := d.State
+	switch {
+	case st.Running && !st.Restarting:
+		return nil
+	case st.Status == "created" && st.Error == "":
+		return nil
+	}
+
+	code := st.ExitCode
+	e := &exitExplanation{
+		ExitCode:      code,
+		Signal:        exitSignal(code),
+		OOMKilled:     st.OOMKilled,
+		Error:         st.Error,
+		MemLimitBytes: d.HostConfig.Memory,
+		RestartPolicy: d.restartPolicy(),
+	}
+	if !strings.HasPrefix(st.FinishedAt, "0001-") {
+		e.FinishedAt = st.FinishedAt
+	}
+
+	switch {
+	case st.OOMKilled:
+		e.Meaning = "killed by the kernel out-of-memory killer"
+		if e.MemLimitBytes > 0 {
+			e.LikelyCause = fmt.Sprintf("the process used more than its %s memory limit", binarySize(uint64(e.MemLimitBytes)))
+		} else {
+			e.LikelyCause = "no memory limit is set, so the host or VM ran out of memory"
+		}
+		e.NextTools = []string{"stats_history", "stats_sample", "get_logs"}
+	case st.Error != "":
+		e.Meaning = "docker could not start the container"
+		e.LikelyCause = startErrorCause(st.Error)
+		e.NextTools = []string{"container_inspect", "list_containers"}
+	default:
+		e.Meaning, e.LikelyCause, e.NextTools = exitCodeExplanation(code)
+	}
+	if d.RestartCount > 1 || st.Restarting {
+		e.NextTools = append(e.NextTools, "detect_crash_loops")
+	}
+
+	e.WillRestart, e.RestartNote = restartOutlook(d)
+	return e
+}
+
+// exitCodeExplanation returns the meaning, likely cause and next tools for
+// a plain exit code.
+func exitCodeExplanation(code int) (meaning, cause string, next []string) {
+	switch code {
+	case 0:
+		return "exited normally", "the main process finished; if it should keep running, check that the command does not exit or daemonize", []string{"get_logs"}
+	case 1:
+		return "general application error", "the application reported a failure, usually a configuration error or an unreachable dependency", []string{"get_logs", "search_logs"}
+	case 2:
+		return "misuse of a shell builtin or invalid arguments", "the command line or entrypoint arguments are wrong", []string{"container_inspect", "get_logs"}
+	case 125:
+		return "docker failed to run the container", "the docker run options are invalid", []string{"container_inspect"}
+	case 126:
+		return "command cannot be executed", "the entrypoint exists but is not executable or permission was denied", []string{"container_inspect", "get_logs"}
+	case 127:
+		return "command not found", "the entrypoint or command does not exist in the image, or PATH is wrong", []string{"container_inspect", "get_logs"}
+	case 130:
+		return "interrupted (SIGINT)", "the process was interrupted, e.g. Ctrl-C on an attached container", []string{"container_events"}
+	case 134:
+		return "aborted (SIGABRT)", "the process called abort(), often a failed assertion or a native runtime panic", []string{"get_logs"}
+	case 137:
+		return "killed (SIGKILL)", "docker kill, or docker stop after the process ignored SIGTERM for the stop timeout; not flagged as OOM", []string{"container_events", "get_logs"}
+	case 139:
+		return "segmentation fault (SIGSEGV)", "a native crash in the process or a library, e.g. an architecture or libc mismatch in the image", []string{"get_logs", "detect_crash_loops"}
+	case 143:
+		return "terminated (SIGTERM)", "a normal docker stop or compose down", []string{"container_events"}
+	case 255:
+		return "exit status out of range", "the process exited with -1 or an unhandled error from its runtime", []string{"get_logs"}
+	}
+	if sig := exitSignal(code); sig != "" {
+		return "killed by " + sig, "the process received a fatal signal", []string{"container_events", "get_logs"}
+	}
+	return "application-defined exit code", "the application exited with an error; its logs should say why", []string{"get_logs", "search_logs"}
+}
+
+// startErrorCause recognizes common docker start errors.
+func startErrorCause(msg string) string {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "port is already allocated") || strings.Contains(lower, "address already in use"):
+		return "a published port is already in use by another container or process"
+	case strings.Contains(lower, "no such file or directory") && strings.Contains(lower, "mount"):
+		return "a bind mount source does not exist on the host"
+	case strings.Contains(lower, "executable file not found"):
+		return "the entrypoint or command does not exist in the image"
+	case strings.Contains(lower, "permission denied"):
+		return "permission denied starting the process or mounting a path"
+	default:
+		return msg
+	}
+}
+
+// restartOutlook reports whether docker will bring the container back by
+// itself under its restart policy.
+func restartOutlook(d *containerDetails) (bool, string) {
+	st := d.State
+	policy := d.HostConfig.RestartPolicy
+	if st.Restarting {
+		return true, fmt.Sprintf("docker is restarting it now (policy %s, %d restarts so far)", d.restartPolicy(), d.RestartCount)
+	}
+	switch policy.Name {
+	case "always":
+		return false, "restart policy always, but it is stopped, so it was stopped manually; it starts again when the docker daemon restarts"
+	case "unless-stopped":
+		return false, "restart policy unless-stopped, but it was stopped manually, so it stays down"
+	case "on-failure":
+		switch {
+		case st.ExitCode == 0:
+			return false, "on-failure only restarts on a non-zero exit code"
+		case policy.MaximumRetryCount > 0 && d.RestartCount >= policy.MaximumRetryCount:
+			return false, fmt.Sprintf("docker gave up after %d restarts (on-failure:%d)", d.RestartCount, policy.MaximumRetryCount)
+		default:
+			return false, "restart policy on-failure, but it is stopped, so it was stopped manually"
+		}
+	default:
+		return false, "restart policy is no; start it again with container_start or compose_up"
+	}
+}
+
+// describe renders the explanation as one line, e.g.
+// "exit 137 (SIGKILL): killed by the kernel out-of-memory killer".
+func (e *exitExplanation) describe() string {
+	s := fmt.Sprintf("exit %d", e.ExitCode)
+	if e.Signal != "" {
+		s += " (" + e.Signal + ")"
+	}
+	return s + ": " + e.Meaning
+}
+
+func formatExitExplanation(container string, e *exitExplanation) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("=== Exit explanation: %s ===\n", container))
+	b.WriteString(fmt.Sprintf("Exit:         %s\n", e.describe()))
+	if e.FinishedAt != "" {
+		b.WriteString(fmt.Sprintf("Finished at:  %s\n", e.FinishedAt))
+	}
+	if e.Error != "" {
+		b.WriteString(fmt.Sprintf("Error:        %s\n", e.Error))
+	}
+	b.WriteString(fmt.Sprintf("Likely cause: %s\n", e.LikelyCause))
+	restart := "no"
+	if e.WillRestart {
+		restart = "yes"
+	}
+	b.WriteString(fmt.Sprintf("Restarts:     %s, %s\n", restart, e.RestartNote))
+	b.WriteString(fmt.Sprintf("Next:         %s\n", strings.Join(e.NextTools, ", ")))
+	return b.String()
+}
+
+// exitedStatusPattern extracts the exit code from a docker ps status such as
+// "Exited (137) 5 minutes ago".
+var exitedStatusPattern = regexp.MustCompile(`^Exited \((-?\d+)\)`)
+
+// needsExitExplanation reports whether a listed container stopped
+// abnormally, judged from its ps state and status alone.
+func needsExitExplanation(c containerInfo) bool {
+	if c.State == "restarting" || c.State == "dead" {
+		return true
+	}
+	m := exitedStatusPattern.FindStringSubmatch(c.Status)
+	if m == nil {
+		return false
+	}
+	code, _ := strconv.Atoi(m[1])
+	return code != 0
+}
+
+type explainExitArgs struct {
+	Container string `json:"container" jsonschema:"container name or ID"`
+}
+
+type explainExitOutput struct {
+	Container string           `json:"container"`
+	State     string           `json:"state"`
+	Exit      *exitExplanation `json:"exit,omitempty" jsonschema:"explanation of the last exit; absent while the container runs normally"`
+}
+
+func handleExplainExit(ctx context.Context, exec docker.Executor, args explainExitArgs) (string, explainExitOutput, error) {
+	out := explainExitOutput{Container: args.Container}
+	if args.Container == "" {
+		return "", out, fmt.Errorf("container name or ID is required")
+	}
+	details, err := inspectDetails(ctx, exec, args.Container)
+	if err != nil {
+		return "", out, fmt.Errorf("failed to inspect container %q: %w", args.Container, err)
+	}
+	if len(details) == 0 {
+		return "", out, fmt.Errorf("no inspect data returned for container %s", args.Container)
+	}
+	d := details[0]
+	out.State = d.State.Status
+	out.Exit = explainExit(&d)
+	if out.Exit == nil {
+		return fmt.Sprintf("Container %s is %s; there is no exit to explain.", args.Container, out.State), out, nil
+	}
+	return formatExitExplanation(args.Container, out.Exit), out, nil
+}
+
+func registerExplainExit(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "explain_exit",
+		Description: "Explain why a container stopped: exit code meaning and signal name, OOM kill against the memory limit, start errors, whether the restart policy will bring it back, and which tools to use next.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args explainExitArgs) (*mcp.CallToolResult, explainExitOutput, error) {
+		result, out, err := handleExplainExit(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+func parseDetails(t *testing.T, inspect string) containerDetails {
+	t.Helper()
+	var details []containerDetails
+	if err := json.Unmarshal([]byte(inspect), &details); err != nil || len(details) != 1 {
+		t.Fatalf("bad fixture: %v", err)
+	}
+	return details[0]
+}
+
+func exitFixture(status string, exitCode int, oom bool, policy string, maxRetry, restarts int, memory int64) string {
+	return fmt.Sprintf(`[{"Id":"abc123","Name":"/api","RestartCount":%d,
+		"State":{"Status":%q,"Running":%t,"Restarting":%t,"ExitCode":%d,"OOMKilled":%t,"FinishedAt":"2024-01-01T00:10:00Z"},
+		"HostConfig":{"Memory":%d,"RestartPolicy":{"Name":%q,"MaximumRetryCount":%d}}}]`,
+		restarts, status, status == "restarting", status == "restarting", exitCode, oom, memory, policy, maxRetry)
+}
+
+func TestExplainExit(t *testing.T) {
+	tests := []struct {
+		name        string
+		inspect     string
+		signal      string
+		meaning     string
+		cause       string
+		willRestart bool
+		note        string
+		next        string
+	}{
+		{"oom with limit", exitFixture("exited", 137, true, "no", 0, 0, 268435456), "SIGKILL", "out-of-memory", "256MiB memory limit", false, "policy is no", "stats_history"},
+		{"oom without limit", exitFixture("exited", 137, true, "no", 0, 0, 0), "SIGKILL", "out-of-memory", "host or VM ran out of memory", false, "policy is no", "stats_history"},
+		{"sigkill", exitFixture("exited", 137, false, "", 0, 0, 0), "SIGKILL", "killed (SIGKILL)", "stop timeout", false, "policy is no", "container_events"},
+		{"segfault", exitFixture("exited", 139, false, "no", 0, 0, 0), "SIGSEGV", "segmentation fault", "native crash", false, "policy is no", "get_logs"},
+		{"sigterm with always", exitFixture("exited", 143, false, "always", 0, 0, 0), "SIGTERM", "terminated", "docker stop", false, "stopped manually", "container_events"},
+		{"unless-stopped", exitFixture("exited", 1, false, "unless-stopped", 0, 0, 0), "", "general application error", "configuration", false, "stays down", "get_logs"},
+		{"on-failure exhausted", exitFixture("exited", 1, false, "on-failure", 3, 3, 0), "", "general application error", "configuration", false, "gave up after 3 restarts (on-failure:3)", "detect_crash_loops"},
+		{"on-failure clean exit", exitFixture("exited", 0, false, "on-failure", 0, 0, 0), "", "exited normally", "main process finished", false, "only restarts on a non-zero", "get_logs"},
+		{"restarting", exitFixture("restarting", 127, false, "always", 0, 4, 0), "", "command not found", "entrypoint", true, "restarting it now (policy always, 4 restarts", "detect_crash_loops"},
+		{"unknown signal", exitFixture("exited", 128+31, false, "no", 0, 0, 0), "signal 31", "killed by signal 31", "fatal signal", false, "policy is no", "get_logs"},
+		{"app-defined code", exitFixture("exited", 3, false, "no", 0, 0, 0), "", "application-defined", "logs should say why", false, "policy is no", "search_logs"},
+		{"start error", inspectPortInUse, "", "could not start", "port is already in use", false, "policy is no", "list_containers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := parseDetails(t, tt.inspect)
+			e := explainExit(&d)
+			if e == nil {
+				t.Fatal("expected an explanation")
+			}
+			if e.Signal != tt.signal {
+				t.Errorf("signal = %q, want %q", e.Signal, tt.signal)
+			}
+			if !strings.Contains(e.Meaning, tt.meaning) || !strings.Contains(e.LikelyCause, tt.cause) {
+				t.Errorf("unexpected meaning/cause %q / %q", e.Meaning, e.LikelyCause)
+			}
+			if e.WillRestart != tt.willRestart || !strings.Contains(e.RestartNote, tt.note) {
+				t.Errorf("unexpected restart outlook %v %q", e.WillRestart, e.RestartNote)
+			}
+			if !strings.Contains(strings.Join(e.NextTools, ","), tt.next) {
+				t.Errorf("expected %s in next tools %v", tt.next, e.NextTools)
+			}
+		})
+	}
+}
+
+func TestExplainExit_NothingToExplain(t *testing.T) {
+	for _, inspect := range []string{
+		inspectRunning,
+		`[{"Id":"abc123","State":{"Status":"created","FinishedAt":"0001-01-01T00:00:00Z"}}]`,
+	} {
+		d := parseDetails(t, inspect)
+		if e := explainExit(&d); e != nil {
+			t.Errorf("expected no explanation, got %+v", e)
+		}
+	}
+}
+
+func TestNeedsExitExplanation(t *testing.T) {
+	for status, want := range map[string]bool{
+		"Exited (137) 5 minutes ago": true,
+		"Exited (0) 1 hour ago":      false,
+		"Up 2 hours":                 false,
+		"Created":                    false,
+	} {
+		if got := needsExitExplanation(containerInfo{State: "exited", Status: status}); got != want {
+			t.Errorf("needsExitExplanation(%q) = %v, want %v", status, got, want)
+		}
+	}
+	if !needsExitExplanation(containerInfo{State: "restarting", Status: "Restarting (1) 3 seconds ago"}) {
+		t.Error("expected restarting containers to be explained")
+	}
+}
+
+func TestHandleExplainExit(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("inspect api", inspectOOM, nil)
+	mock.On("inspect web", inspectRunning, nil)
+	mock.On("inspect gone", "[]", fmt.Errorf("Error: No such object: gone"))
+
+	result, out, err := handleExplainExit(context.Background(), mock, explainExitArgs{Container: "api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.State != "exited" || out.Exit == nil || !out.Exit.OOMKilled || out.Exit.FinishedAt != "2024-01-01T00:10:00Z" {
+		t.Errorf("unexpected output %+v", out)
+	}
+	for _, want := range []string{"Exit:         exit 137 (SIGKILL): killed by the kernel out-of-memory killer", "Likely cause: the process used more than its 256MiB memory limit", "Restarts:     no, restart policy is no", "Next:         stats_history"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+
+	result, out, err = handleExplainExit(context.Background(), mock, explainExitArgs{Container: "web"})
+	if err != nil || out.Exit != nil || !strings.Contains(result, "no exit to explain") {
+		t.Errorf("unexpected result for a running container: %q %+v %v", result, out, err)
+	}
+
+	if _, _, err := handleExplainExit(context.Background(), mock, explainExitArgs{Container: "gone"}); err == nil || !strings.Contains(err.Error(), "No such object") {
+		t.Errorf("expected inspect error, got %v", err)
+	}
+	if _, _, err := handleExplainExit(context.Background(), mock, explainExitArgs{}); err == nil {
+		t.Error("expected error for missing container")
+	}
+}
+
+func TestHandleListContainers_ExplainsAbnormalExits(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}}", strings.Join([]string{
+		`{"ID":"abc123","Names":"api","Image":"api","State":"exited","Status":"Exited (137) 5 minutes ago","Labels":""}`,
+		`{"ID":"def456","Names":"job","Image":"busybox","State":"exited","Status":"Exited (0) 1 hour ago","Labels":""}`,
+		`{"ID":"ghi789","Names":"web","Image":"nginx","State":"running","Status":"Up 1 hour","Labels":""}`,
+	}, "\n"), nil)
+	mock.On("inspect abc123", inspectOOM, nil)
+
+	result, out, err := handleListContainers(context.Background(), mock, listContainersArgs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	containers := out.Projects[0].Containers
+	if containers[0].Exit == nil || !containers[0].Exit.OOMKilled {
+		t.Errorf("expected an OOM explanation for api, got %+v", containers[0])
+	}
+	if containers[1].Exit != nil || containers[2].Exit != nil {
+		t.Errorf("expected only abnormal exits explained, got %+v", containers)
+	}
+	if !strings.Contains(result, "exit 137 (SIGKILL): killed by the kernel out-of-memory killer; the process used more than its 256MiB memory limit") {
+		t.Errorf("expected exit explanation in result:\n%s", result)
+	}
+}
+
+func TestHandleListContainers_InspectFailureKeepsListing(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}}", `{"ID":"abc123","Names":"api","Image":"api","State":"exited","Status":"Exited (1) 5 minutes ago","Labels":""}`, nil)
+	mock.On("inspect abc123", "", fmt.Errorf("Error: No such object: abc123"))
+
+	result, out, err := handleListContainers(context.Background(), mock, listContainersArgs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Projects[0].Containers[0].Exit != nil || !strings.Contains(result, "api") {
+		t.Errorf("expected the plain listing, got %q %+v", result, out)
+	}
+}
+
+func TestHandleContainerInspect_ExplainsExit(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("inspect api", inspectSIGKILL, nil)
+
+	result, out, err := handleContainerInspect(context.Background(), mock, containerInspectArgs{Container: "api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Exit == nil || out.Exit.Signal != "SIGKILL" || out.Inspect == nil {
+		t.Errorf("unexpected output %+v", out)
+	}
+	if !strings.HasPrefix(result, "=== Exit explanation: api ===") || !strings.Contains(result, `"Id": "abc123"`) {
+		t.Errorf("expected the explanation before the raw inspect:\n%s", result)
+	}
+}
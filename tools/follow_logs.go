@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	defaultFollowDuration = 30
+	maxFollowDuration     = 300
+	defaultFollowLines    = 500
+)
+
+type followLogsArgs struct {
+	Container  string `json:"container" jsonschema:"container name or ID"`
+	Duration   int    `json:"duration,omitempty" jsonschema:"seconds to follow the logs for (default: 30, max: 300)"`
+	MaxLines   int    `json:"max_lines,omitempty" jsonschema:"stop after this many lines (default: 500)"`
+	Tail       int    `json:"tail,omitempty" jsonschema:"number of existing lines to include before following (default: 0, only new lines)"`
+	Since      string `json:"since,omitempty" jsonschema:"include logs since timestamp (e.g. 2024-01-01T00:00:00) or relative (e.g. 5m) before following"`
+	Timestamps bool   `json:"timestamps,omitempty" jsonschema:"show timestamps in log output"`
+}
+
+type followLogsOutput struct {
+	Container       string   `json:"container"`
+	Lines           []string `json:"lines" jsonschema:"lines received while following"`
+	DurationSeconds float64  `json:"duration_seconds" jsonschema:"how long the logs were followed"`
+	StopReason      string   `json:"stop_reason" jsonschema:"why following stopped: max_lines, duration or stream_ended"`
+}
+
+// handleFollowLogs runs docker logs -f until the duration elapses, max_lines
+// lines have been read or the container stops. Each line is passed to notify
+// as it arrives; the returned string summarizes the whole run.
+func handleFollowLogs(ctx context.Context, exec docker.Executor, args followLogsArgs, notify func(string)) (string, followLogsOutput, error) {
+	out := followLogsOutput{Container: args.Container}
+	if args.Container == "" {
+		return "", out, fmt.Errorf("container name or ID is required")
+	}
+
+	duration := args.Duration
+	if duration <= 0 {
+		duration = defaultFollowDuration
+	}
+	if duration > maxFollowDuration {
+		duration = maxFollowDuration
+	}
+	maxLines := args.MaxLines
+	if maxLines <= 0 {
+		maxLines = defaultFollowLines
+	}
+
+	cmdArgs := []string{"logs", "--follow", "--tail", strconv.Itoa(args.Tail)}
+	if args.Since != "" {
+		cmdArgs = append(cmdArgs, "--since", args.Since)
+	}
+	if args.Timestamps {
+		cmdArgs = append(cmdArgs, "--timestamps")
+	}
+	cmdArgs = append(cmdArgs, args.Container)
+
+	followCtx, cancel := context.WithTimeout(ctx, time.Duration(duration)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	var lines []string
+	err := exec.StreamCombined(followCtx, func(line string) {
+		if len(lines) >= maxLines {
+			return
+		}
+		lines = append(lines, line)
+		notify(line)
+		if len(lines) >= maxLines {
+			cancel()
+		}
+	}, cmdArgs...)
+	elapsed := time.Since(start).Round(100 * time.Millisecond)
+
+	var reason string
+	switch {
+	case len(lines) >= maxLines:
+		reason = fmt.Sprintf("reached max_lines (%d)", maxLines)
+		out.StopReason = "max_lines"
+	case ctx.Err() != nil:
+		return "", out, fmt.Errorf("following logs for container %q was cancelled: %w", args.Container, ctx.Err())
+	case errors.Is(err, context.DeadlineExceeded):
+		reason = fmt.Sprintf("duration of %ds elapsed", duration)
+		out.StopReason = "duration"
+	case err != nil:
+		return "", out, fmt.Errorf("failed to follow logs for container %q: %w", args.Container, err)
+	default:
+		reason = "log stream ended (container stopped)"
+		out.StopReason = "stream_ended"
+	}
+	out.Lines = lines
+	out.DurationSeconds = elapsed.Seconds()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Followed logs of %s for %s: %d lines, %s.\n", args.Container, elapsed, len(lines), reason))
+	if len(lines) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(strings.Join(lines, "\n"))
+		sb.WriteString("\n")
+	}
+	return sb.String(), out, nil
+}
+
+func registerFollowLogs(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "follow_logs",
+		Description: "Follow a container's logs live (docker logs -f) for a bounded duration or number of lines. New lines are streamed as progress/log notifications while the call runs; the result summarizes everything received.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args followLogsArgs) (*mcp.CallToolResult, followLogsOutput, error) {
+		result, out, err := handleFollowLogs(ctx, exec, args, newNotifier(ctx, req, "follow_logs"))
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+func TestHandleFollowLogs_StreamEnds(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("logs --follow --tail 0 web", "line 1\nline 2\nline 3\n", nil)
+
+	var notified []string
+	result, _, err := handleFollowLogs(context.Background(), mock, followLogsArgs{Container: "web"}, func(msg string) {
+		notified = append(notified, msg)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(notified, ",") != "line 1,line 2,line 3" {
+		t.Errorf("expected each line to be notified in order, got %v", notified)
+	}
+	if !strings.Contains(result, "3 lines") {
+		t.Errorf("expected line count in summary, got:\n%s", result)
+	}
+	if !strings.Contains(result, "container stopped") {
+		t.Errorf("expected stream-ended reason, got:\n%s", result)
+	}
+	if !strings.Contains(result, "line 3") {
+		t.Errorf("expected collected lines in result, got:\n%s", result)
+	}
+}
+
+func TestHandleFollowLogs_MaxLines(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("logs --follow --tail 10 --since 5m --timestamps web", "a\nb\nc\nd\ne\n", nil)
+
+	var notified []string
+	result, _, err := handleFollowLogs(context.Background(), mock, followLogsArgs{
+		Container:  "web",
+		MaxLines:   2,
+		Tail:       10,
+		Since:      "5m",
+		Timestamps: true,
+	}, func(msg string) {
+		notified = append(notified, msg)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(notified) != 2 {
+		t.Errorf("expected 2 notifications, got %v", notified)
+	}
+	if !strings.Contains(result, "reached max_lines (2)") {
+		t.Errorf("expected max_lines reason, got:\n%s", result)
+	}
+	if strings.Contains(result, "\nc\n") {
+		t.Errorf("expected lines past max_lines to be dropped, got:\n%s", result)
+	}
+}
+
+// blockingStream emits a single line and then blocks until ctx is done, like
+// docker logs -f on a quiet container.
+type blockingStream struct {
+	*docker.Mock
+}
+
+func (b blockingStream) StreamCombined(ctx context.Context, onLine func(string), args ...string) error {
+	onLine("started")
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestHandleFollowLogs_DurationElapsed(t *testing.T) {
+	result, _, err := handleFollowLogs(context.Background(), blockingStream{docker.NewMock()}, followLogsArgs{
+		Container: "web",
+		Duration:  1,
+	}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "duration of 1s elapsed") {
+		t.Errorf("expected duration reason, got:\n%s", result)
+	}
+	if !strings.Contains(result, "1 lines") {
+		t.Errorf("expected the line read before the deadline, got:\n%s", result)
+	}
+}
+
+func TestHandleFollowLogs_Errors(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("logs --follow --tail 0 ghost", "", fmt.Errorf("Error: No such container: ghost"))
+
+	if _, _, err := handleFollowLogs(context.Background(), mock, followLogsArgs{}, func(string) {}); err == nil {
+		t.Error("expected error for missing container name")
+	}
+
+	_, _, err := handleFollowLogs(context.Background(), mock, followLogsArgs{Container: "ghost"}, func(string) {})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "No such container") {
+		t.Errorf("expected docker error, got: %v", err)
+	}
+}
+
+func TestFollowLogs_ProgressNotifications(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("logs --follow --tail 0 web", "hello\nworld\n", nil)
+
+	server := newTestServer()
+	registerFollowLogs(server, mock)
+
+	var mu sync.Mutex
+	var messages []string
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			messages = append(messages, req.Params.Message)
+		},
+	})
+	session := connectClient(t, server, client)
+
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "follow-1"},
+		Name:      "follow_logs",
+		Arguments: map[string]any{"container": "web"},
+	}
+	res, err := session.CallTool(context.Background(), params)
+	if err != nil {
+		t.Fatalf("unexpected protocol error: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("unexpected tool error: %v", res.Content[0].(*mcp.TextContent).Text)
+	}
+
+	// Notifications are handled asynchronously by the client.
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(messages)
+		mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(messages, ",") != "hello,world" {
+		t.Errorf("expected progress notifications for each line, got %v", messages)
+	}
+}
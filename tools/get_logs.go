@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/otsukatsuka/orbstack-mcp/docker"
@@ -17,9 +18,15 @@ type getLogsArgs struct {
 	Timestamps bool   `json:"timestamps,omitempty" jsonschema:"show timestamps in log output"`
 }
 
-func handleGetLogs(ctx context.Context, exec docker.Executor, args getLogsArgs) (string, error) {
+type getLogsOutput struct {
+	Container string   `json:"container"`
+	Lines     []string `json:"lines" jsonschema:"log lines, oldest first"`
+}
+
+func handleGetLogs(ctx context.Context, exec docker.Executor, args getLogsArgs) (string, getLogsOutput, error) {
+	out := getLogsOutput{Container: args.Container}
 	if args.Container == "" {
-		return "", fmt.Errorf("container name or ID is required")
+		return "", out, fmt.Errorf("container name or ID is required")
 	}
 
 	tail := args.Tail
@@ -43,30 +50,28 @@ func handleGetLogs(ctx context.Context, exec docker.Executor, args getLogsArgs)
 
 	output, err := exec.ExecCombined(ctx, cmdArgs...)
 	if err != nil {
-		return "", fmt.Errorf("failed to get logs for container %q: %w", args.Container, err)
+		return "", out, fmt.Errorf("failed to get logs for container %q: %w", args.Container, err)
 	}
 
 	if output == "" {
-		return "No log output.", nil
+		return "No log output.", out, nil
 	}
 
-	return output, nil
+	out.Lines = strings.Split(strings.TrimRight(output, "\n"), "\n")
+	return output, out, nil
 }
 
 func registerGetLogs(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_logs",
 		Description: "Get logs from a Docker container. Uses combined stdout and stderr output.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args getLogsArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleGetLogs(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args getLogsArgs) (*mcp.CallToolResult, getLogsOutput, error) {
+		result, out, err := handleGetLogs(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
@@ -15,7 +15,7 @@ func TestHandleGetLogs_BasicWithDefaults(t *testing.T) {
 	logOutput := "2024-01-01 line1\n2024-01-01 line2\n2024-01-01 line3"
 	mock.On("logs --tail 100 mycontainer", logOutput, nil)
 
-	result, err := handleGetLogs(context.Background(), mock, getLogsArgs{
+	result, _, err := handleGetLogs(context.Background(), mock, getLogsArgs{
 		Container: "mycontainer",
 	})
 	if err != nil {
@@ -33,7 +33,7 @@ func TestHandleGetLogs_WithAllOptions(t *testing.T) {
 	logOutput := "2024-01-01T10:00:00Z line1\n2024-01-01T11:00:00Z line2"
 	mock.On("logs --tail 50 --since 1h --until 30m --timestamps mycontainer", logOutput, nil)
 
-	result, err := handleGetLogs(context.Background(), mock, getLogsArgs{
+	result, _, err := handleGetLogs(context.Background(), mock, getLogsArgs{
 		Container:  "mycontainer",
 		Tail:       50,
 		Since:      "1h",
@@ -71,7 +71,7 @@ func TestHandleGetLogs_WithTailAndSince(t *testing.T) {
 	logOutput := "recent log line"
 	mock.On("logs --tail 20 --since 2h mycontainer", logOutput, nil)
 
-	result, err := handleGetLogs(context.Background(), mock, getLogsArgs{
+	result, _, err := handleGetLogs(context.Background(), mock, getLogsArgs{
 		Container: "mycontainer",
 		Tail:      20,
 		Since:     "2h",
@@ -89,7 +89,7 @@ func TestHandleGetLogs_ContainerNotFound(t *testing.T) {
 	mock := docker.NewMock()
 	mock.On("logs --tail 100 nonexistent", "", fmt.Errorf("Error: No such container: nonexistent"))
 
-	_, err := handleGetLogs(context.Background(), mock, getLogsArgs{
+	_, _, err := handleGetLogs(context.Background(), mock, getLogsArgs{
 		Container: "nonexistent",
 	})
 	if err == nil {
@@ -102,7 +102,7 @@ func TestHandleGetLogs_ContainerNotFound(t *testing.T) {
 }
 
 func TestHandleGetLogs_EmptyContainer(t *testing.T) {
-	_, err := handleGetLogs(context.Background(), docker.NewMock(), getLogsArgs{
+	_, _, err := handleGetLogs(context.Background(), docker.NewMock(), getLogsArgs{
 		Container: "",
 	})
 	if err == nil {
@@ -118,7 +118,7 @@ func TestHandleGetLogs_EmptyOutput(t *testing.T) {
 	mock := docker.NewMock()
 	mock.On("logs --tail 100 empty-container", "", nil)
 
-	result, err := handleGetLogs(context.Background(), mock, getLogsArgs{
+	result, _, err := handleGetLogs(context.Background(), mock, getLogsArgs{
 		Container: "empty-container",
 	})
 	if err != nil {
@@ -0,0 +1,351 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	// bloatDefaultTop is how many of the largest layers are ranked unless
+	// top says otherwise.
+	bloatDefaultTop = 10
+	// packageCacheMinSize is the smallest layer checked for package-manager
+	// caches; smaller layers cannot hold a meaningful cache.
+	packageCacheMinSize = 10_000_000
+	// largeCopySize and largeCopyShare flag COPY and ADD layers that are big
+	// in absolute terms or relative to the whole image.
+	largeCopySize  = 100_000_000
+	largeCopyShare = 0.25
+)
+
+// bloatLayer is one non-empty layer of an image, numbered by its build step.
+type bloatLayer struct {
+	Step        int     `json:"step" jsonschema:"1-based position in the image history, oldest first"`
+	Instruction string  `json:"instruction" jsonschema:"the Dockerfile instruction that created the layer"`
+	Size        int64   `json:"size_bytes"`
+	Percent     float64 `json:"percent" jsonschema:"share of the image size"`
+	DiffID      string  `json:"diff_id,omitempty" jsonschema:"layer digest from image inspect, when history and rootfs line up"`
+	Created     string  `json:"created"`
+}
+
+type bloatFinding struct {
+	Kind        string `json:"kind" jsonschema:"package_cache, add_then_delete or large_copy"`
+	Step        int    `json:"step" jsonschema:"build step of the layer holding the waste"`
+	Instruction string `json:"instruction"`
+	Size        int64  `json:"size_bytes" jsonschema:"size of that layer, an upper bound on the waste"`
+	Message     string `json:"message"`
+	Suggestion  string `json:"suggestion"`
+}
+
+type layerDelta struct {
+	Instruction string `json:"instruction"`
+	Status      string `json:"status" jsonschema:"grown, shrunk, changed, added or removed"`
+	BaseSize    int64  `json:"base_size_bytes"`
+	Size        int64  `json:"size_bytes"`
+	Delta       int64  `json:"delta_bytes"`
+}
+
+type bloatComparison struct {
+	Base     string       `json:"base"`
+	BaseSize int64        `json:"base_size_bytes"`
+	Delta    int64        `json:"delta_bytes" jsonschema:"image size minus base size"`
+	Layers   []layerDelta `json:"layers" jsonschema:"layers that differ, largest change first"`
+}
+
+type imageBloatArgs struct {
+	Image   string `json:"image" jsonschema:"image name, name:tag or ID"`
+	Compare string `json:"compare,omitempty" jsonschema:"another tag of the image, e.g. the previous release, to show which layers grew"`
+	Top     int    `json:"top,omitempty" jsonschema:"how many of the largest layers to rank (default 10)"`
+}
+
+type imageBloatOutput struct {
+	Image      string           `json:"image"`
+	Size       int64            `json:"size_bytes" jsonschema:"sum of the layer sizes"`
+	Layers     int              `json:"layers" jsonschema:"number of non-empty layers"`
+	Largest    []bloatLayer     `json:"largest" jsonschema:"largest layers first"`
+	Findings   []bloatFinding   `json:"findings" jsonschema:"likely waste, largest first"`
+	Comparison *bloatComparison `json:"comparison,omitempty"`
+}
+
+// imageLayers reads the history and inspect output of image and returns its
+// non-empty layers oldest first, with their rootfs digests when the two
+// line up, and the image config's environment.
+func imageLayers(ctx context.Context, exec docker.Executor, image string) ([]bloatLayer, []string, error) {
+	history, err := imageHistory(ctx, exec, image)
+	if err != nil {
+		return nil, nil, fmt.Errorf("image history failed: %w", err)
+	}
+	details, err := inspectImage(ctx, exec, image)
+	if err != nil {
+		return nil, nil, fmt.Errorf("image inspect failed: %w", err)
+	}
+
+	var layers []bloatLayer
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+		if h.Empty {
+			continue
+		}
+		layers = append(layers, bloatLayer{
+			Step:        len(history) - i,
+			Instruction: instruction(h.CreatedBy),
+			Size:        h.Size,
+			Created:     h.Created,
+		})
+	}
+	// Instructions that add no files may still produce an empty rootfs
+	// layer, so digests are only assigned when the counts match.
+	if len(layers) == len(details.RootFS.Layers) {
+		for i := range layers {
+			layers[i].DiffID = details.RootFS.Layers[i]
+		}
+	}
+	return layers, details.Config.Env, nil
+}
+
+// packageCacheRule flags a package manager whose cache stays in the layer.
+type packageCacheRule struct {
+	manager string
+	// installs reports whether the instruction runs the package manager.
+	installs []string
+	// cleans lists the ways the cache is avoided or removed; any one of
+	// them, or the environment variable env, keeps the layer clean.
+	cleans []string
+	env    string
+	fix    string
+}
+
+var packageCacheRules = []packageCacheRule{
+	{"apt", []string{"apt-get install", "apt install"}, []string{"rm -rf /var/lib/apt/lists"}, "", "end the same RUN with && rm -rf /var/lib/apt/lists/*"},
+	{"apk", []string{"apk add"}, []string{"--no-cache", "rm -rf /var/cache/apk"}, "", "use apk add --no-cache"},
+	{"yum", []string{"yum install"}, []string{"yum clean all", "rm -rf /var/cache/yum"}, "", "end the same RUN with && yum clean all"},
+	{"dnf", []string{"dnf install"}, []string{"dnf clean all", "rm -rf /var/cache/dnf"}, "", "end the same RUN with && dnf clean all"},
+	{"pip", []string{"pip install", "pip3 install"}, []string{"--no-cache-dir"}, "PIP_NO_CACHE_DIR", "use pip install --no-cache-dir"},
+	{"npm", []string{"npm install", "npm ci"}, []string{"npm cache clean"}, "", "end the same RUN with && npm cache clean --force, or build in a separate stage"},
+}
+
+// bloatFindings flags likely waste in layers, given oldest first.
+func bloatFindings(layers []bloatLayer, env []string, total int64) []bloatFinding {
+	var findings []bloatFinding
+	for i, l := range layers {
+		isRun := strings.HasPrefix(l.Instruction, "RUN ")
+		// A cache mount keeps package caches out of the layer entirely.
+		if isRun && l.Size >= packageCacheMinSize && !strings.Contains(l.Instruction, "--mount=type=cache") {
+			for _, rule := range packageCacheRules {
+				if !containsAny(l.Instruction, rule.installs) || containsAny(l.Instruction, rule.cleans) {
+					continue
+				}
+				if rule.env != "" && slices.ContainsFunc(env, func(e string) bool { return strings.HasPrefix(e, rule.env+"=") }) {
+					continue
+				}
+				findings = append(findings, bloatFinding{
+					Kind: "package_cache", Step: l.Step, Instruction: l.Instruction, Size: l.Size,
+					Message:    fmt.Sprintf("%s cache is left in the layer", rule.manager),
+					Suggestion: rule.fix,
+				})
+			}
+		}
+
+		if isRun {
+			for _, target := range removedPaths(l.Instruction) {
+				for _, earlier := range layers[:i] {
+					if !strings.Contains(earlier.Instruction, target) {
+						continue
+					}
+					findings = append(findings, bloatFinding{
+						Kind: "add_then_delete", Step: earlier.Step, Instruction: earlier.Instruction, Size: earlier.Size,
+						Message:    fmt.Sprintf("%s is deleted at step %d, but its bytes stay in the layer of step %d", target, l.Step, earlier.Step),
+						Suggestion: "create and delete the file in the same RUN, or build it in a separate stage and copy only the result",
+					})
+				}
+			}
+		}
+
+		if strings.HasPrefix(l.Instruction, "COPY ") || strings.HasPrefix(l.Instruction, "ADD ") {
+			share := float64(l.Size) / float64(max(total, 1))
+			if l.Size >= largeCopySize || l.Size >= packageCacheMinSize && share >= largeCopyShare {
+				findings = append(findings, bloatFinding{
+					Kind: "large_copy", Step: l.Step, Instruction: l.Instruction, Size: l.Size,
+					Message:    fmt.Sprintf("copies %s, %.0f%% of the image", decimalSize(uint64(l.Size)), share*100),
+					Suggestion: "exclude build context clutter with .dockerignore, or copy only build output from a separate stage",
+				})
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Size > findings[j].Size })
+	return findings
+}
+
+func containsAny(s string, subs []string) bool {
+	return slices.ContainsFunc(subs, func(sub string) bool { return strings.Contains(s, sub) })
+}
+
+// removedPaths returns the paths an instruction deletes with rm, without
+// trailing globs, skipping ones too short to match reliably.
+func removedPaths(instr string) []string {
+	var paths []string
+	segments := strings.FieldsFunc(strings.TrimPrefix(instr, "RUN "), func(r rune) bool {
+		return r == '&' || r == ';' || r == '|'
+	})
+	for _, seg := range segments {
+		fields := strings.Fields(seg)
+		if len(fields) == 0 || fields[0] != "rm" {
+			continue
+		}
+		for _, f := range fields[1:] {
+			f = strings.TrimRight(strings.TrimSuffix(f, "/*"), "*/")
+			if strings.HasPrefix(f, "-") || len(f) < 4 {
+				continue
+			}
+			paths = append(paths, f)
+		}
+	}
+	return paths
+}
+
+// compareLayers lines up the layers of base and image by instruction, in
+// build order, and reports every layer that differs.
+func compareLayers(base, image []bloatLayer) []layerDelta {
+	used := make([]bool, len(base))
+	var deltas []layerDelta
+	for _, l := range image {
+		match := -1
+		for j, b := range base {
+			if !used[j] && b.Instruction == l.Instruction {
+				match = j
+				break
+			}
+		}
+		if match < 0 {
+			deltas = append(deltas, layerDelta{Instruction: l.Instruction, Status: "added", Size: l.Size, Delta: l.Size})
+			continue
+		}
+		used[match] = true
+		b := base[match]
+		d := layerDelta{Instruction: l.Instruction, BaseSize: b.Size, Size: l.Size, Delta: l.Size - b.Size}
+		switch {
+		case d.Delta > 0:
+			d.Status = "grown"
+		case d.Delta < 0:
+			d.Status = "shrunk"
+		case b.DiffID != "" && l.DiffID != "" && b.DiffID != l.DiffID:
+			d.Status = "changed"
+		default:
+			continue
+		}
+		deltas = append(deltas, d)
+	}
+	for j, b := range base {
+		if !used[j] {
+			deltas = append(deltas, layerDelta{Instruction: b.Instruction, Status: "removed", BaseSize: b.Size, Delta: -b.Size})
+		}
+	}
+	sort.SliceStable(deltas, func(i, j int) bool { return abs64(deltas[i].Delta) > abs64(deltas[j].Delta) })
+	return deltas
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// signedSize formats a size difference with an explicit sign.
+func signedSize(n int64) string {
+	if n < 0 {
+		return "-" + decimalSize(uint64(-n))
+	}
+	return "+" + decimalSize(uint64(n))
+}
+
+func handleImageBloatReport(ctx context.Context, exec docker.Executor, args imageBloatArgs) (string, imageBloatOutput, error) {
+	out := imageBloatOutput{Image: args.Image}
+	if args.Image == "" {
+		return "", out, fmt.Errorf("image is required")
+	}
+	top := args.Top
+	if top <= 0 {
+		top = bloatDefaultTop
+	}
+
+	layers, env, err := imageLayers(ctx, exec, args.Image)
+	if err != nil {
+		return "", out, err
+	}
+	for _, l := range layers {
+		out.Size += l.Size
+	}
+	out.Layers = len(layers)
+	for i := range layers {
+		layers[i].Percent = float64(layers[i].Size) / float64(max(out.Size, 1)) * 100
+	}
+	out.Findings = bloatFindings(layers, env, out.Size)
+
+	ranked := slices.Clone(layers)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Size > ranked[j].Size })
+	out.Largest = ranked[:min(top, len(ranked))]
+
+	if args.Compare != "" {
+		baseLayers, _, err := imageLayers(ctx, exec, args.Compare)
+		if err != nil {
+			return "", out, fmt.Errorf("%s: %w", args.Compare, err)
+		}
+		c := &bloatComparison{Base: args.Compare, Layers: compareLayers(baseLayers, layers)}
+		for _, l := range baseLayers {
+			c.BaseSize += l.Size
+		}
+		c.Delta = out.Size - c.BaseSize
+		out.Comparison = c
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Image %s: %s in %d layers\n", args.Image, decimalSize(uint64(out.Size)), out.Layers)
+	sb.WriteString("\nLargest layers:\n")
+	for _, l := range out.Largest {
+		fmt.Fprintf(&sb, "  step %-3d %9s %5.1f%%  %s\n", l.Step, decimalSize(uint64(l.Size)), l.Percent, ellipsize(l.Instruction, 100))
+	}
+
+	if len(out.Findings) == 0 {
+		sb.WriteString("\nNo common waste patterns found.\n")
+	} else {
+		sb.WriteString("\nFindings:\n")
+		for _, f := range out.Findings {
+			fmt.Fprintf(&sb, "  [%s] step %d (%s): %s\n", f.Kind, f.Step, decimalSize(uint64(f.Size)), f.Message)
+			fmt.Fprintf(&sb, "    %s\n", ellipsize(f.Instruction, 100))
+			fmt.Fprintf(&sb, "    fix: %s\n", f.Suggestion)
+		}
+	}
+
+	if c := out.Comparison; c != nil {
+		fmt.Fprintf(&sb, "\nCompared with %s (%s): %s\n", c.Base, decimalSize(uint64(c.BaseSize)), signedSize(c.Delta))
+		if len(c.Layers) == 0 {
+			sb.WriteString("  no layer changed\n")
+		}
+		for _, d := range c.Layers {
+			fmt.Fprintf(&sb, "  %-7s %9s  %s -> %s  %s\n", d.Status, signedSize(d.Delta), decimalSize(uint64(d.BaseSize)), decimalSize(uint64(d.Size)), ellipsize(d.Instruction, 80))
+		}
+	}
+	return sb.String(), out, nil
+}
+
+func registerImageBloatReport(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "image_bloat_report",
+		Description: "Explain where an image's size comes from: ranks layers by size with the Dockerfile instruction behind each, flags package-manager caches left in a layer, files added and deleted in a later layer, and huge COPY layers, and optionally compares with another tag to show which layers grew.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args imageBloatArgs) (*mcp.CallToolResult, imageBloatOutput, error) {
+		result, out, err := handleImageBloatReport(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// historyStep is one Dockerfile step of a history fixture, oldest first.
+type historyStep struct {
+	createdBy string
+	size      int64
+}
+
+// onImage registers history and inspect output for image, built from steps
+// given oldest first; diffIDs become the rootfs layers.
+func onImage(mock *docker.Mock, image string, env []string, diffIDs []string, steps ...historyStep) {
+	var lines []string
+	for i := len(steps) - 1; i >= 0; i-- {
+		lines = append(lines, fmt.Sprintf(`{"ID":"<missing>","CreatedAt":"2024-01-01T00:00:00Z","CreatedBy":%q,"Size":"%d","Comment":""}`, steps[i].createdBy, steps[i].size))
+	}
+	mock.On("history --no-trunc --human=false --format {{json .}} "+image, strings.Join(lines, "\n"), nil)
+	envJSON, _ := json.Marshal(env)
+	layersJSON, _ := json.Marshal(diffIDs)
+	mock.On("image inspect "+image, fmt.Sprintf(`[{"Id":"sha256:%s","Config":{"Env":%s},"RootFS":{"Layers":%s}}]`, image, envJSON, layersJSON), nil)
+}
+
+func TestHandleImageBloatReport(t *testing.T) {
+	mock := docker.NewMock()
+	onImage(mock, "shop/api:v2", []string{"PATH=/usr/bin"}, []string{"sha256:base", "sha256:apt", "sha256:pip", "sha256:dl", "sha256:src", "sha256:rm"},
+		historyStep{"/bin/sh -c #(nop) ADD file:abc in / ", 80_000_000},
+		historyStep{"/bin/sh -c #(nop)  ENV LANG=C.UTF-8", 0},
+		historyStep{"RUN /bin/sh -c apt-get update && apt-get install -y build-essential # buildkit", 250_000_000},
+		historyStep{"RUN /bin/sh -c pip install -r requirements.txt # buildkit", 40_000_000},
+		historyStep{"RUN /bin/sh -c curl -o /tmp/model.tar.gz https://example.com/m && tar xzf /tmp/model.tar.gz # buildkit", 120_000_000},
+		historyStep{"COPY . /app # buildkit", 150_000_000},
+		historyStep{"RUN /bin/sh -c rm -rf /tmp/model.tar.gz # buildkit", 1_000},
+		historyStep{`CMD ["serve"]`, 0},
+	)
+
+	result, out, err := handleImageBloatReport(context.Background(), mock, imageBloatArgs{Image: "shop/api:v2", Top: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Size != 640_001_000 || out.Layers != 6 {
+		t.Errorf("unexpected totals %d bytes in %d layers", out.Size, out.Layers)
+	}
+	if len(out.Largest) != 3 || out.Largest[0].Step != 3 || out.Largest[1].Step != 6 || out.Largest[0].DiffID != "sha256:apt" {
+		t.Errorf("unexpected ranking %+v", out.Largest)
+	}
+	if !strings.HasPrefix(out.Largest[0].Instruction, "RUN apt-get update") || out.Largest[1].Instruction != "COPY . /app" {
+		t.Errorf("expected instructions mapped from history, got %+v", out.Largest)
+	}
+
+	var kinds []string
+	for _, f := range out.Findings {
+		kinds = append(kinds, fmt.Sprintf("%s@%d", f.Kind, f.Step))
+	}
+	if got := strings.Join(kinds, ","); got != "package_cache@3,large_copy@6,add_then_delete@5,package_cache@4" {
+		t.Errorf("unexpected findings %s", got)
+	}
+	for _, want := range []string{
+		"Image shop/api:v2: 640MB in 6 layers",
+		"step 3       250MB  39.1%  RUN apt-get update",
+		"[package_cache] step 3 (250MB): apt cache is left in the layer",
+		"/tmp/model.tar.gz is deleted at step 7, but its bytes stay in the layer of step 5",
+		"fix: use pip install --no-cache-dir",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestBloatFindings_CleanLayers(t *testing.T) {
+	layers := []bloatLayer{
+		{Step: 1, Instruction: "RUN apt-get update && apt-get install -y curl && rm -rf /var/lib/apt/lists/*", Size: 50_000_000},
+		{Step: 2, Instruction: "RUN apk add --no-cache git", Size: 20_000_000},
+		{Step: 3, Instruction: "RUN pip install flask", Size: 30_000_000},
+		{Step: 4, Instruction: "RUN --mount=type=cache,target=/root/.npm npm ci", Size: 90_000_000},
+		{Step: 5, Instruction: "RUN yum install -y tar", Size: 1_000_000},
+		{Step: 6, Instruction: "COPY package.json /app/", Size: 2_000},
+	}
+	if findings := bloatFindings(layers, []string{"PIP_NO_CACHE_DIR=1"}, 400_000_000); len(findings) != 0 {
+		t.Errorf("expected no findings, got %+v", findings)
+	}
+}
+
+func TestRemovedPaths(t *testing.T) {
+	got := removedPaths("RUN make && rm -rf /src/build/* /tmp/x.tgz; rm -f a && echo rm /not/this")
+	if strings.Join(got, ",") != "/src/build,/tmp/x.tgz" {
+		t.Errorf("unexpected removed paths %v", got)
+	}
+}
+
+func TestHandleImageBloatReport_Compare(t *testing.T) {
+	mock := docker.NewMock()
+	onImage(mock, "shop/api:v1", nil, []string{"sha256:base", "sha256:deps", "sha256:src1", "sha256:old"},
+		historyStep{"ADD file:abc in /", 80_000_000},
+		historyStep{"RUN pip install --no-cache-dir -r requirements.txt", 40_000_000},
+		historyStep{"COPY . /app", 45_000_000},
+		historyStep{"RUN python -m compileall /app", 5_000_000},
+	)
+	onImage(mock, "shop/api:v2", nil, []string{"sha256:base", "sha256:deps", "sha256:src2", "sha256:assets"},
+		historyStep{"ADD file:abc in /", 80_000_000},
+		historyStep{"RUN pip install --no-cache-dir -r requirements.txt", 40_000_000},
+		historyStep{"COPY . /app", 52_000_000},
+		historyStep{"COPY assets /app/assets", 12_000_000},
+	)
+
+	result, out, err := handleImageBloatReport(context.Background(), mock, imageBloatArgs{Image: "shop/api:v2", Compare: "shop/api:v1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := out.Comparison
+	if c == nil || c.BaseSize != 170_000_000 || c.Delta != 14_000_000 {
+		t.Fatalf("unexpected comparison %+v", c)
+	}
+	var deltas []string
+	for _, d := range c.Layers {
+		deltas = append(deltas, fmt.Sprintf("%s:%s:%d", d.Status, d.Instruction, d.Delta))
+	}
+	if got := strings.Join(deltas, ","); got != "added:COPY assets /app/assets:12000000,grown:COPY . /app:7000000,removed:RUN python -m compileall /app:-5000000" {
+		t.Errorf("unexpected deltas %s", got)
+	}
+	for _, want := range []string{"Compared with shop/api:v1 (170MB): +14MB", "grown        +7MB  45MB -> 52MB  COPY . /app"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleImageBloatReport_Errors(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("history --no-trunc --human=false --format {{json .}} ghost", "", fmt.Errorf("Error: No such image: ghost"))
+	onImage(mock, "app", nil, []string{"sha256:a"}, historyStep{"ADD file:abc in /", 1_000})
+
+	if _, _, err := handleImageBloatReport(context.Background(), mock, imageBloatArgs{}); err == nil {
+		t.Error("expected error for missing image")
+	}
+	if _, _, err := handleImageBloatReport(context.Background(), mock, imageBloatArgs{Image: "ghost"}); err == nil || !strings.Contains(err.Error(), "No such image") {
+		t.Errorf("expected history error, got %v", err)
+	}
+	if _, _, err := handleImageBloatReport(context.Background(), mock, imageBloatArgs{Image: "app", Compare: "ghost"}); err == nil || !strings.Contains(err.Error(), "ghost") {
+		t.Errorf("expected compare error, got %v", err)
+	}
+}
@@ -0,0 +1,348 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// buildTailLines is how many output lines of the failing step a build
+// failure reports.
+const buildTailLines = 20
+
+var (
+	// buildVertexLine matches a line of BuildKit's plain progress output,
+	// e.g. "#5 [2/4] RUN npm ci" or "#5 0.523 npm ERR! missing script".
+	buildVertexLine = regexp.MustCompile(`^#(\d+) (.*)$`)
+	// buildStepName matches the name of a Dockerfile step, such as
+	// "[2/4] RUN npm ci" or, in Compose builds, "[web 2/4] RUN npm ci".
+	buildStepName = regexp.MustCompile(`^\[(\S+ )?\d+/\d+\] `)
+	// buildElapsed matches the elapsed-time prefix of a step's output line.
+	buildElapsed = regexp.MustCompile(`^\d+\.\d+ `)
+)
+
+type imageBuildArgs struct {
+	Context    string            `json:"context" jsonschema:"build context directory"`
+	Dockerfile string            `json:"dockerfile,omitempty" jsonschema:"Dockerfile path, relative to the context unless absolute (default: Dockerfile in the context)"`
+	Target     string            `json:"target,omitempty" jsonschema:"build stage to stop at"`
+	BuildArgs  map[string]string `json:"build_args,omitempty" jsonschema:"build-time variables passed with --build-arg"`
+	Tags       []string          `json:"tags,omitempty" jsonschema:"names to tag the image with, e.g. shop/api:dev"`
+	NoCache    bool              `json:"no_cache,omitempty" jsonschema:"do not use the build cache (default: false)"`
+}
+
+type composeBuildArgs struct {
+	Project  string   `json:"project,omitempty" jsonschema:"Compose project name (optional when file is given)"`
+	File     string   `json:"file,omitempty" jsonschema:"compose file or project directory to use instead of discovering it from existing containers"`
+	Profiles []string `json:"profiles,omitempty" jsonschema:"Compose profiles to enable"`
+	EnvFiles []string `json:"env_files,omitempty" jsonschema:"env files to load, relative to the project directory unless absolute"`
+	Services []string `json:"services,omitempty" jsonschema:"specific services to build (default: all with a build section)"`
+	NoCache  bool     `json:"no_cache,omitempty" jsonschema:"do not use the build cache (default: false)"`
+}
+
+// builtImage is an image exported at the end of a build.
+type builtImage struct {
+	ID    string   `json:"id,omitempty"`
+	Names []string `json:"names"`
+}
+
+// buildFailure describes the step a build failed at.
+type buildFailure struct {
+	Step  string   `json:"step,omitempty" jsonschema:"the failing step, e.g. [2/4] RUN npm ci; empty when the build failed before running any step"`
+	Error string   `json:"error"`
+	Lines []string `json:"lines" jsonschema:"last output lines of the failing step, or of the whole build when no step failed"`
+}
+
+type buildOutput struct {
+	Project         string        `json:"project,omitempty"`
+	WorkDir         string        `json:"work_dir,omitempty" jsonschema:"directory docker compose was run in"`
+	Images          []builtImage  `json:"images"`
+	Steps           int           `json:"steps" jsonschema:"Dockerfile steps the build went through"`
+	Cached          int           `json:"cached" jsonschema:"steps served from the build cache"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Failure         *buildFailure `json:"failure,omitempty"`
+}
+
+// buildStep is one vertex of a BuildKit build, such as a Dockerfile step or
+// the export of the result.
+type buildStep struct {
+	name  string
+	lines []string
+	err   string
+	image *builtImage
+}
+
+// buildLog follows BuildKit's plain progress output and keeps just enough of
+// it to summarize the build and explain a failure.
+type buildLog struct {
+	steps   map[string]*buildStep
+	order   []string
+	failed  *buildStep
+	summary string
+	tail    []string
+	cached  int
+}
+
+func newBuildLog() *buildLog {
+	return &buildLog{steps: make(map[string]*buildStep)}
+}
+
+// appendTail appends line to lines, keeping only the last buildTailLines.
+func appendTail(lines []string, line string) []string {
+	lines = append(lines, line)
+	if len(lines) > buildTailLines {
+		lines = lines[len(lines)-buildTailLines:]
+	}
+	return lines
+}
+
+// add feeds one line of build output to the log.
+func (b *buildLog) add(line string) {
+	m := buildVertexLine.FindStringSubmatch(line)
+	if m == nil {
+		// Outside any vertex: the final error, and the excerpt of the failing
+		// step and Dockerfile that BuildKit prints before it.
+		b.tail = appendTail(b.tail, line)
+		if strings.HasPrefix(line, "ERROR: ") || strings.Contains(line, "failed to solve:") {
+			b.summary = strings.TrimPrefix(line, "ERROR: ")
+		}
+		return
+	}
+
+	id, text := m[1], m[2]
+	step, ok := b.steps[id]
+	if !ok {
+		// The first line of a vertex names it.
+		step = &buildStep{name: text}
+		b.steps[id] = step
+		b.order = append(b.order, id)
+		return
+	}
+	switch {
+	case text == step.name:
+		// BuildKit repeats the name when output switches between vertices.
+	case text == "CACHED":
+		b.cached++
+	case strings.HasPrefix(text, "DONE "), text == "CANCELED":
+	case strings.HasPrefix(text, "ERROR: "):
+		step.err = strings.TrimPrefix(text, "ERROR: ")
+		if b.failed == nil {
+			b.failed = step
+		}
+	case strings.HasPrefix(text, "writing image "), strings.HasPrefix(text, "exporting manifest list "):
+		for _, f := range strings.Fields(text) {
+			if strings.HasPrefix(f, "sha256:") {
+				step.exported().ID = f
+			}
+		}
+	case strings.HasPrefix(text, "naming to "):
+		name := strings.TrimSuffix(strings.TrimPrefix(text, "naming to "), " done")
+		step.exported().Names = append(step.exported().Names, name)
+	default:
+		step.lines = appendTail(step.lines, buildElapsed.ReplaceAllString(text, ""))
+	}
+}
+
+// exported returns the image the step exports, creating it on first use.
+func (s *buildStep) exported() *builtImage {
+	if s.image == nil {
+		s.image = &builtImage{Names: []string{}}
+	}
+	return s.image
+}
+
+// result summarizes the build into out.
+func (b *buildLog) result(out *buildOutput) {
+	out.Images = []builtImage{}
+	for _, id := range b.order {
+		step := b.steps[id]
+		if buildStepName.MatchString(step.name) {
+			out.Steps++
+		}
+		if step.image != nil {
+			out.Images = append(out.Images, *step.image)
+		}
+	}
+	out.Cached = b.cached
+}
+
+// failure explains why the build failed, falling back to err when the
+// output did not say.
+func (b *buildLog) failure(err error) *buildFailure {
+	f := &buildFailure{Error: b.summary, Lines: b.tail}
+	if b.failed != nil {
+		f = &buildFailure{Step: b.failed.name, Error: b.failed.err, Lines: b.failed.lines}
+	}
+	if f.Error == "" {
+		f.Error = err.Error()
+	}
+	if f.Lines == nil {
+		f.Lines = []string{}
+	}
+	return f
+}
+
+// formatBuildFailure renders a failure of the build named what as an error
+// message.
+func formatBuildFailure(what string, f *buildFailure) string {
+	var sb strings.Builder
+	if f.Step != "" {
+		fmt.Fprintf(&sb, "%s failed at step %s: %s", what, f.Step, f.Error)
+	} else {
+		fmt.Fprintf(&sb, "%s failed: %s", what, f.Error)
+	}
+	if len(f.Lines) > 0 {
+		fmt.Fprintf(&sb, "\n\nLast output:\n  %s", strings.Join(f.Lines, "\n  "))
+	}
+	return sb.String()
+}
+
+// runBuild streams a build command, relaying each line to notify, and
+// summarizes it into out. what names the build in errors.
+func runBuild(ctx context.Context, exec docker.Executor, cmdArgs []string, notify func(string), what string, out *buildOutput) error {
+	log := newBuildLog()
+	start := time.Now()
+	err := exec.StreamCombined(ctx, func(line string) {
+		log.add(line)
+		notify(line)
+	}, cmdArgs...)
+	out.DurationSeconds = time.Since(start).Round(100 * time.Millisecond).Seconds()
+	log.result(out)
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s was cancelled: %w", what, ctx.Err())
+	}
+	if err != nil {
+		out.Failure = log.failure(err)
+		return errors.New(formatBuildFailure(what, out.Failure))
+	}
+	return nil
+}
+
+// formatBuildResult renders a successful build.
+func formatBuildResult(what string, out buildOutput) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s finished in %.1fs: %d steps, %d cached.\n", what, out.DurationSeconds, out.Steps, out.Cached)
+	for _, img := range out.Images {
+		id := "unknown ID"
+		if img.ID != "" {
+			id = shortImageID(img.ID)
+		}
+		if len(img.Names) > 0 {
+			fmt.Fprintf(&sb, "  %s  %s\n", id, strings.Join(img.Names, ", "))
+		} else {
+			fmt.Fprintf(&sb, "  %s\n", id)
+		}
+	}
+	return sb.String()
+}
+
+func handleImageBuild(ctx context.Context, exec docker.Executor, args imageBuildArgs, notify func(string)) (string, buildOutput, error) {
+	out := buildOutput{Images: []builtImage{}}
+	if args.Context == "" {
+		return "", out, fmt.Errorf("context is required")
+	}
+	dir, err := filepath.Abs(args.Context)
+	if err != nil {
+		return "", out, fmt.Errorf("invalid build context %q: %w", args.Context, err)
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return "", out, fmt.Errorf("build context %q: %w", args.Context, err)
+	} else if !info.IsDir() {
+		return "", out, fmt.Errorf("build context %q is not a directory", args.Context)
+	}
+
+	cmdArgs := []string{"build", "--progress", "plain"}
+	if args.Dockerfile != "" {
+		dockerfile := args.Dockerfile
+		if !filepath.IsAbs(dockerfile) {
+			dockerfile = filepath.Join(dir, dockerfile)
+		}
+		cmdArgs = append(cmdArgs, "-f", dockerfile)
+	}
+	if args.Target != "" {
+		cmdArgs = append(cmdArgs, "--target", args.Target)
+	}
+	keys := make([]string, 0, len(args.BuildArgs))
+	for k := range args.BuildArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmdArgs = append(cmdArgs, "--build-arg", k+"="+args.BuildArgs[k])
+	}
+	for _, tag := range args.Tags {
+		cmdArgs = append(cmdArgs, "-t", tag)
+	}
+	if args.NoCache {
+		cmdArgs = append(cmdArgs, "--no-cache")
+	}
+	cmdArgs = append(cmdArgs, dir)
+
+	if err := runBuild(ctx, exec, cmdArgs, notify, "build", &out); err != nil {
+		return "", out, err
+	}
+	return formatBuildResult("Build of "+dir, out), out, nil
+}
+
+func handleComposeBuild(ctx context.Context, exec docker.Executor, projects *ProjectRegistry, args composeBuildArgs, notify func(string)) (string, buildOutput, error) {
+	out := buildOutput{Project: args.Project, Images: []builtImage{}}
+	p, _, err := composeTarget(ctx, exec, projects, args.Project, args.File, true)
+	if err != nil {
+		return "", out, err
+	}
+	out.WorkDir = p.WorkDir
+
+	cmdArgs := append(composeArgs(p, args.Project, args.Profiles, args.EnvFiles), "--progress", "plain", "build")
+	if args.NoCache {
+		cmdArgs = append(cmdArgs, "--no-cache")
+	}
+	cmdArgs = append(cmdArgs, args.Services...)
+
+	if err := runBuild(ctx, exec, cmdArgs, notify, "compose build", &out); err != nil {
+		return "", out, err
+	}
+	return formatBuildResult(fmt.Sprintf("Build of Compose project %s", projectLabel(args.Project, p)), out), out, nil
+}
+
+// buildResult turns a build handler's return values into a tool result.
+// A failed build is reported as a tool error that still carries the
+// structured output, so clients get the failing step and its log lines.
+func buildResult(result string, out buildOutput, err error) (*mcp.CallToolResult, buildOutput, error) {
+	if err != nil && out.Failure != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+		}, out, nil
+	}
+	if err != nil {
+		return nil, out, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result}},
+	}, out, nil
+}
+
+func registerImageBuild(server *mcp.Server, exec docker.Executor, projects *ProjectRegistry) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "image_build",
+		Description: "Build an image from a context directory (docker build) with an optional Dockerfile, target stage, build args, tags and no-cache. BuildKit progress is streamed as progress/log notifications while the call runs; on failure the error names the failing step and its last output lines.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args imageBuildArgs) (*mcp.CallToolResult, buildOutput, error) {
+		return buildResult(handleImageBuild(ctx, exec, args, newNotifier(ctx, req, "image_build")))
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "compose_build",
+		Description: "Build the images of a Docker Compose project (docker compose build), optionally only some services. BuildKit progress is streamed as progress/log notifications while the call runs; on failure the error names the failing step and its last output lines.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args composeBuildArgs) (*mcp.CallToolResult, buildOutput, error) {
+		return buildResult(handleComposeBuild(ctx, exec, projects, args, newNotifier(ctx, req, "compose_build")))
+	})
+}
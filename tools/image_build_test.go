@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const buildSuccessOutput = `#0 building with "orbstack" instance using docker driver
+#1 [internal] load build definition from Dockerfile
+#1 transferring dockerfile: 210B done
+#1 DONE 0.0s
+#2 [internal] load metadata for docker.io/library/node:20-alpine
+#2 DONE 0.8s
+#3 [1/4] FROM docker.io/library/node:20-alpine@sha256:aaa
+#3 CACHED
+#4 [2/4] WORKDIR /app
+#4 CACHED
+#5 [3/4] COPY package.json package-lock.json ./
+#5 DONE 0.1s
+#6 [4/4] RUN npm ci
+#6 1.204 added 120 packages in 1s
+#6 DONE 2.3s
+#7 exporting to image
+#7 exporting layers 0.4s done
+#7 writing image sha256:1f2e3d4c5b6a79880123456789abcdef0123456789abcdef0123456789abcdef done
+#7 naming to docker.io/shop/api:dev done
+#7 naming to docker.io/shop/api:latest done
+#7 DONE 0.4s
+`
+
+const buildFailureOutput = `#0 building with "orbstack" instance using docker driver
+#1 [internal] load build definition from Dockerfile
+#1 DONE 0.0s
+#3 [1/3] FROM docker.io/library/node:20-alpine@sha256:aaa
+#3 CACHED
+#4 [2/3] COPY . /app
+#4 DONE 0.1s
+#5 [3/3] RUN npm run build
+#5 0.412 > shop@1.0.0 build
+#5 0.413 > tsc -p .
+#5 3.100 src/index.ts(4,7): error TS2322: Type 'string' is not assignable to type 'number'.
+#5 ERROR: process "/bin/sh -c npm run build" did not complete successfully: exit code: 2
+------
+ > [3/3] RUN npm run build:
+3.100 src/index.ts(4,7): error TS2322: Type 'string' is not assignable to type 'number'.
+------
+ERROR: failed to solve: process "/bin/sh -c npm run build" did not complete successfully: exit code: 2
+`
+
+func TestHandleImageBuild(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	mock.On(fmt.Sprintf("build --progress plain -f %s --target prod --build-arg NODE_ENV=production --build-arg VERSION=1.2 -t shop/api:dev -t shop/api:latest --no-cache %s", filepath.Join(dir, "docker/Dockerfile"), dir), buildSuccessOutput, nil)
+
+	var notified []string
+	result, out, err := handleImageBuild(context.Background(), mock, imageBuildArgs{
+		Context:    dir,
+		Dockerfile: "docker/Dockerfile",
+		Target:     "prod",
+		BuildArgs:  map[string]string{"VERSION": "1.2", "NODE_ENV": "production"},
+		Tags:       []string{"shop/api:dev", "shop/api:latest"},
+		NoCache:    true,
+	}, func(msg string) {
+		notified = append(notified, msg)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(notified) != strings.Count(buildSuccessOutput, "\n") {
+		t.Errorf("expected every line to be notified, got %d", len(notified))
+	}
+	if out.Steps != 4 || out.Cached != 2 {
+		t.Errorf("expected 4 steps with 2 cached, got %d and %d", out.Steps, out.Cached)
+	}
+	if len(out.Images) != 1 || !strings.HasPrefix(out.Images[0].ID, "sha256:1f2e3d4c5b6a") || strings.Join(out.Images[0].Names, ",") != "docker.io/shop/api:dev,docker.io/shop/api:latest" {
+		t.Errorf("unexpected images %+v", out.Images)
+	}
+	if out.Failure != nil {
+		t.Errorf("expected no failure, got %+v", out.Failure)
+	}
+	for _, want := range []string{"4 steps, 2 cached", "1f2e3d4c5b6a  docker.io/shop/api:dev, docker.io/shop/api:latest"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleImageBuild_FailingStep(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	mock.On("build --progress plain "+dir, buildFailureOutput, fmt.Errorf("docker build: exit status 1: ERROR: failed to solve"))
+
+	_, out, err := handleImageBuild(context.Background(), mock, imageBuildArgs{Context: dir}, func(string) {})
+	if err == nil {
+		t.Fatal("expected build error")
+	}
+	f := out.Failure
+	if f == nil || f.Step != "[3/3] RUN npm run build" || !strings.Contains(f.Error, "exit code: 2") {
+		t.Fatalf("unexpected failure %+v", f)
+	}
+	if len(f.Lines) != 3 || f.Lines[0] != "> shop@1.0.0 build" {
+		t.Errorf("expected the step's output without elapsed times, got %q", f.Lines)
+	}
+	for _, want := range []string{
+		`build failed at step [3/3] RUN npm run build: process "/bin/sh -c npm run build" did not complete successfully`,
+		"Last output:\n  > shop@1.0.0 build\n  > tsc -p .\n  src/index.ts(4,7): error TS2322",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error:\n%s", want, err)
+		}
+	}
+}
+
+func TestRegisterImageBuild_FailureKeepsStructuredOutput(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	mock.On("build --progress plain "+dir, buildFailureOutput, fmt.Errorf("docker build: exit status 1: ERROR: failed to solve"))
+	server := newTestServer()
+	registerImageBuild(server, mock, NewProjectRegistry())
+	session := connect(t, server)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "image_build",
+		Arguments: map[string]any{"context": dir},
+	})
+	if err != nil {
+		t.Fatalf("unexpected protocol error: %v", err)
+	}
+	if !res.IsError {
+		t.Fatal("expected the failed build to be a tool error")
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "build failed at step [3/3] RUN npm run build") {
+		t.Errorf("unexpected error text %q", text)
+	}
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out buildOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid structured content %s: %v", data, err)
+	}
+	if out.Failure == nil || out.Failure.Step != "[3/3] RUN npm run build" || len(out.Failure.Lines) != 3 {
+		t.Errorf("expected the failure in the structured content, got %s", data)
+	}
+}
+
+func TestHandleImageBuild_FailureBeforeSteps(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	output := "#1 [internal] load build definition from Dockerfile\n#1 DONE 0.0s\nDockerfile:3\n--------------------\n   3 | >>> RNU make\n--------------------\nERROR: failed to solve: dockerfile parse error on line 3: unknown instruction: RNU\n"
+	mock.On("build --progress plain "+dir, output, fmt.Errorf("docker build: exit status 1"))
+
+	_, out, err := handleImageBuild(context.Background(), mock, imageBuildArgs{Context: dir}, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "build failed: failed to solve: dockerfile parse error on line 3") {
+		t.Fatalf("expected summary error, got %v", err)
+	}
+	if out.Failure == nil || out.Failure.Step != "" || !strings.Contains(strings.Join(out.Failure.Lines, "\n"), ">>> RNU make") {
+		t.Errorf("expected the log tail as output lines, got %+v", out.Failure)
+	}
+}
+
+func TestHandleImageBuild_InvalidContext(t *testing.T) {
+	mock := docker.NewMock()
+	file := filepath.Join(t.TempDir(), "Dockerfile")
+	if err := os.WriteFile(file, []byte("FROM scratch\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ctxDir := range []string{"", file, filepath.Join(t.TempDir(), "missing")} {
+		if _, _, err := handleImageBuild(context.Background(), mock, imageBuildArgs{Context: ctxDir}, func(string) {}); err == nil {
+			t.Errorf("expected error for context %q", ctxDir)
+		}
+	}
+	if len(mock.Calls()) != 0 {
+		t.Errorf("expected no docker calls, got %v", mock.Calls())
+	}
+}
+
+func TestHandleComposeBuild(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	output := "#1 [web internal] load build definition from Dockerfile\n#1 DONE 0.0s\n#4 [web 1/2] FROM docker.io/library/python:3.12\n#4 CACHED\n#5 [web 2/2] RUN pip install -r requirements.txt\n#5 DONE 5.1s\n#6 [web] exporting to image\n#6 writing image sha256:abcdef0123456789 done\n#6 naming to docker.io/library/shop-web done\n#6 DONE 0.2s\n"
+	mock.On(fmt.Sprintf("compose --project-directory %s -p shop --progress plain build --no-cache web", dir), output, nil)
+
+	result, out, err := handleComposeBuild(context.Background(), mock, NewProjectRegistry(), composeBuildArgs{
+		Project:  "shop",
+		File:     dir,
+		Services: []string{"web"},
+		NoCache:  true,
+	}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.WorkDir != dir || out.Steps != 2 || out.Cached != 1 {
+		t.Errorf("unexpected output %+v", out)
+	}
+	if !strings.Contains(result, `Build of Compose project "shop" finished`) || !strings.Contains(result, "abcdef012345  docker.io/library/shop-web") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+}
@@ -0,0 +1,678 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// dockerTimeLayout is how docker image ls prints creation times.
+const dockerTimeLayout = "2006-01-02 15:04:05 -0700 MST"
+
+// imageSummary is one image as listed by docker image ls, with all its tags
+// merged into one entry.
+type imageSummary struct {
+	ID         string   `json:"id"`
+	Repository string   `json:"repository" jsonschema:"<none> for dangling images"`
+	Tags       []string `json:"tags"`
+	Size       uint64   `json:"size_bytes"`
+	Created    string   `json:"created"`
+	Dangling   bool     `json:"dangling" jsonschema:"whether the image has neither repository nor tag"`
+	Containers []string `json:"containers" jsonschema:"containers, running or stopped, created from this image"`
+}
+
+// shortImageID returns the 12-character form docker shows for image IDs.
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// listImageSummaries runs docker image ls with the given extra arguments and
+// merges the rows of images that carry several tags of one repository.
+func listImageSummaries(ctx context.Context, exec docker.Executor, extra ...string) ([]imageSummary, error) {
+	output, err := exec.Exec(ctx, append([]string{"image", "ls", "--no-trunc", "--format", "{{json .}}"}, extra...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	var images []imageSummary
+	index := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw struct {
+			ID         string `json:"ID"`
+			Repository string `json:"Repository"`
+			Tag        string `json:"Tag"`
+			CreatedAt  string `json:"CreatedAt"`
+			Size       string `json:"Size"`
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse image JSON: %w", err)
+		}
+		key := raw.ID + " " + raw.Repository
+		i, ok := index[key]
+		if !ok {
+			size, err := parseSize(raw.Size)
+			if err != nil {
+				return nil, err
+			}
+			created := raw.CreatedAt
+			if t, err := time.Parse(dockerTimeLayout, raw.CreatedAt); err == nil {
+				created = t.UTC().Format(time.RFC3339)
+			}
+			i = len(images)
+			index[key] = i
+			images = append(images, imageSummary{
+				ID:         raw.ID,
+				Repository: raw.Repository,
+				Size:       size,
+				Created:    created,
+				Dangling:   raw.Repository == "<none>" && raw.Tag == "<none>",
+			})
+		}
+		if raw.Tag != "<none>" {
+			images[i].Tags = append(images[i].Tags, raw.Tag)
+		}
+	}
+	return images, nil
+}
+
+// imageUsers maps image IDs to the names of the containers, running or
+// stopped, created from them.
+func imageUsers(ctx context.Context, exec docker.Executor) (map[string][]string, error) {
+	details, err := allContainerDetails(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string][]string)
+	for _, d := range details {
+		users[d.Image] = append(users[d.Image], strings.TrimPrefix(d.Name, "/"))
+	}
+	return users, nil
+}
+
+// imageDetails is the part of docker image inspect the image tools use.
+type imageDetails struct {
+	ID           string   `json:"Id"`
+	RepoTags     []string `json:"RepoTags"`
+	RepoDigests  []string `json:"RepoDigests"`
+	Created      string   `json:"Created"`
+	Size         int64    `json:"Size"`
+	Architecture string   `json:"Architecture"`
+	Os           string   `json:"Os"`
+	Variant      string   `json:"Variant"`
+	Config       struct {
+		Entrypoint   []string            `json:"Entrypoint"`
+		Cmd          []string            `json:"Cmd"`
+		Env          []string            `json:"Env"`
+		ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+		Labels       map[string]string   `json:"Labels"`
+		WorkingDir   string              `json:"WorkingDir"`
+		User         string              `json:"User"`
+	} `json:"Config"`
+	RootFS struct {
+		Layers []string `json:"Layers"`
+	} `json:"RootFS"`
+}
+
+// inspectImage runs docker image inspect for a single image.
+func inspectImage(ctx context.Context, exec docker.Executor, image string) (*imageDetails, error) {
+	output, err := exec.Exec(ctx, "image", "inspect", image)
+	if err != nil {
+		return nil, err
+	}
+	var details []imageDetails
+	if err := json.Unmarshal([]byte(output), &details); err != nil {
+		return nil, fmt.Errorf("failed to parse image inspect JSON: %w", err)
+	}
+	if len(details) == 0 {
+		return nil, fmt.Errorf("no such image: %s", image)
+	}
+	return &details[0], nil
+}
+
+// imageLayer is one entry of docker history, newest first.
+type imageLayer struct {
+	ID        string `json:"id" jsonschema:"layer image ID, <missing> for layers built elsewhere"`
+	Created   string `json:"created"`
+	CreatedBy string `json:"created_by" jsonschema:"the instruction that created the layer"`
+	Size      int64  `json:"size_bytes"`
+	Comment   string `json:"comment,omitempty"`
+	Empty     bool   `json:"empty" jsonschema:"whether the instruction only changed metadata and added no files"`
+}
+
+// imageHistory runs docker history with exact sizes and untruncated
+// instructions.
+func imageHistory(ctx context.Context, exec docker.Executor, image string) ([]imageLayer, error) {
+	output, err := exec.Exec(ctx, "history", "--no-trunc", "--human=false", "--format", "{{json .}}", image)
+	if err != nil {
+		return nil, err
+	}
+	var layers []imageLayer
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw struct {
+			ID        string `json:"ID"`
+			CreatedAt string `json:"CreatedAt"`
+			CreatedBy string `json:"CreatedBy"`
+			Size      string `json:"Size"`
+			Comment   string `json:"Comment"`
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse history JSON: %w", err)
+		}
+		size, err := strconv.ParseInt(raw.Size, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid layer size %q", raw.Size)
+		}
+		layers = append(layers, imageLayer{
+			ID:        raw.ID,
+			Created:   raw.CreatedAt,
+			CreatedBy: raw.CreatedBy,
+			Size:      size,
+			Comment:   raw.Comment,
+			Empty:     size == 0,
+		})
+	}
+	return layers, nil
+}
+
+// instruction shortens a history CreatedBy to the Dockerfile instruction
+// it came from, dropping the shell prefix and the marker builders record.
+func instruction(createdBy string) string {
+	s := strings.TrimSuffix(strings.TrimSpace(createdBy), " # buildkit")
+	s = strings.TrimPrefix(s, "/bin/sh -c #(nop) ")
+	if rest, ok := strings.CutPrefix(s, "/bin/sh -c "); ok {
+		s = "RUN " + rest
+	} else if rest, ok := strings.CutPrefix(s, "RUN /bin/sh -c "); ok {
+		s = "RUN " + rest
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ellipsize cuts s to at most n runes for table output.
+func ellipsize(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
+
+type listImagesArgs struct {
+	Repository string `json:"repository,omitempty" jsonschema:"only list images of this repository, optionally with :tag"`
+	Dangling   bool   `json:"dangling,omitempty" jsonschema:"only list dangling images"`
+}
+
+type imageRepository struct {
+	Repository string         `json:"repository"`
+	Images     []imageSummary `json:"images"`
+}
+
+type listImagesOutput struct {
+	Repositories []imageRepository `json:"repositories" jsonschema:"images grouped by repository; dangling images come last under <none>"`
+	Images       int               `json:"images"`
+	Dangling     int               `json:"dangling"`
+	TotalSize    uint64            `json:"total_size_bytes" jsonschema:"sum of image sizes; shared layers are counted once per image"`
+	Warnings     []string          `json:"warnings"`
+}
+
+func handleListImages(ctx context.Context, exec docker.Executor, args listImagesArgs) (string, listImagesOutput, error) {
+	var out listImagesOutput
+	var extra []string
+	if args.Dangling {
+		extra = append(extra, "--filter", "dangling=true")
+	}
+	if args.Repository != "" {
+		extra = append(extra, args.Repository)
+	}
+	images, err := listImageSummaries(ctx, exec, extra...)
+	if err != nil {
+		return "", out, err
+	}
+	if len(images) == 0 {
+		return "No images found.", out, nil
+	}
+
+	// Container usage is best effort; the listing stands on its own.
+	users, err := imageUsers(ctx, exec)
+	if err != nil {
+		out.Warnings = append(out.Warnings, err.Error())
+	}
+
+	groups := make(map[string][]imageSummary)
+	for _, img := range images {
+		img.Containers = users[img.ID]
+		groups[img.Repository] = append(groups[img.Repository], img)
+		out.Images++
+		out.TotalSize += img.Size
+		if img.Dangling {
+			out.Dangling++
+		}
+	}
+	repos := make([]string, 0, len(groups))
+	for repo := range groups {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i] == "<none>" || repos[j] == "<none>" {
+			return repos[j] == "<none>" && repos[i] != "<none>"
+		}
+		return repos[i] < repos[j]
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d images, %s", out.Images, decimalSize(out.TotalSize))
+	if out.Dangling > 0 {
+		fmt.Fprintf(&sb, ", %d dangling", out.Dangling)
+	}
+	sb.WriteString("\n")
+	for _, repo := range repos {
+		fmt.Fprintf(&sb, "\n=== %s ===\n", repo)
+		for _, img := range groups[repo] {
+			tags := strings.Join(img.Tags, ",")
+			if tags == "" {
+				tags = "<none>"
+			}
+			used := "unused"
+			if len(img.Containers) > 0 {
+				used = "used by " + strings.Join(img.Containers, ", ")
+			}
+			fmt.Fprintf(&sb, "  %-12s %-20s %8s  %s  %s\n", shortImageID(img.ID), tags, decimalSize(img.Size), img.Created, used)
+		}
+		out.Repositories = append(out.Repositories, imageRepository{Repository: repo, Images: groups[repo]})
+	}
+	writeWarnings(&sb, out.Warnings)
+	return sb.String(), out, nil
+}
+
+type imageInspectArgs struct {
+	Image string `json:"image" jsonschema:"image name, name:tag or ID"`
+}
+
+type imageInspectOutput struct {
+	ID           string            `json:"id"`
+	Tags         []string          `json:"tags"`
+	Digests      []string          `json:"digests"`
+	Created      string            `json:"created"`
+	Size         int64             `json:"size_bytes"`
+	Architecture string            `json:"architecture"`
+	OS           string            `json:"os"`
+	Variant      string            `json:"variant,omitempty"`
+	Entrypoint   []string          `json:"entrypoint"`
+	Cmd          []string          `json:"cmd"`
+	Env          []string          `json:"env"`
+	ExposedPorts []string          `json:"exposed_ports"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	WorkingDir   string            `json:"working_dir,omitempty"`
+	User         string            `json:"user,omitempty"`
+	Layers       int               `json:"layers"`
+}
+
+func handleImageInspect(ctx context.Context, exec docker.Executor, args imageInspectArgs) (string, imageInspectOutput, error) {
+	var out imageInspectOutput
+	if args.Image == "" {
+		return "", out, fmt.Errorf("image is required")
+	}
+	d, err := inspectImage(ctx, exec, args.Image)
+	if err != nil {
+		return "", out, fmt.Errorf("image inspect failed: %w", err)
+	}
+	out = imageInspectOutput{
+		ID:           d.ID,
+		Tags:         d.RepoTags,
+		Digests:      d.RepoDigests,
+		Created:      d.Created,
+		Size:         d.Size,
+		Architecture: d.Architecture,
+		OS:           d.Os,
+		Variant:      d.Variant,
+		Entrypoint:   d.Config.Entrypoint,
+		Cmd:          d.Config.Cmd,
+		Env:          d.Config.Env,
+		Labels:       d.Config.Labels,
+		WorkingDir:   d.Config.WorkingDir,
+		User:         d.Config.User,
+		Layers:       len(d.RootFS.Layers),
+	}
+	for port := range d.Config.ExposedPorts {
+		out.ExposedPorts = append(out.ExposedPorts, port)
+	}
+	slices.Sort(out.ExposedPorts)
+
+	platform := out.OS + "/" + out.Architecture
+	if out.Variant != "" {
+		platform += "/" + out.Variant
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "=== Image: %s ===\n", args.Image)
+	fmt.Fprintf(&sb, "ID:          %s\n", out.ID)
+	fmt.Fprintf(&sb, "Tags:        %s\n", strings.Join(out.Tags, ", "))
+	fmt.Fprintf(&sb, "Created:     %s\n", out.Created)
+	fmt.Fprintf(&sb, "Size:        %s in %d layers\n", decimalSize(uint64(out.Size)), out.Layers)
+	fmt.Fprintf(&sb, "Platform:    %s\n", platform)
+	fmt.Fprintf(&sb, "Entrypoint:  %s\n", formatCommand(out.Entrypoint))
+	fmt.Fprintf(&sb, "Cmd:         %s\n", formatCommand(out.Cmd))
+	if out.WorkingDir != "" {
+		fmt.Fprintf(&sb, "Workdir:     %s\n", out.WorkingDir)
+	}
+	if out.User != "" {
+		fmt.Fprintf(&sb, "User:        %s\n", out.User)
+	}
+	if len(out.ExposedPorts) > 0 {
+		fmt.Fprintf(&sb, "Ports:       %s\n", strings.Join(out.ExposedPorts, ", "))
+	}
+	if len(out.Env) > 0 {
+		sb.WriteString("Env:\n")
+		for _, e := range out.Env {
+			fmt.Fprintf(&sb, "  %s\n", e)
+		}
+	}
+	if len(out.Labels) > 0 {
+		sb.WriteString("Labels:\n")
+		keys := make([]string, 0, len(out.Labels))
+		for k := range out.Labels {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&sb, "  %s=%s\n", k, out.Labels[k])
+		}
+	}
+	return sb.String(), out, nil
+}
+
+// formatCommand renders an exec-form command as JSON, the way a Dockerfile
+// spells it.
+func formatCommand(cmd []string) string {
+	if len(cmd) == 0 {
+		return "(none)"
+	}
+	data, _ := json.Marshal(cmd)
+	return string(data)
+}
+
+type imageHistoryArgs struct {
+	Image string `json:"image" jsonschema:"image name, name:tag or ID"`
+}
+
+type imageHistoryOutput struct {
+	Image  string       `json:"image"`
+	Layers []imageLayer `json:"layers" jsonschema:"layers newest first, as docker history lists them"`
+	Size   int64        `json:"size_bytes" jsonschema:"sum of the layer sizes"`
+}
+
+func handleImageHistory(ctx context.Context, exec docker.Executor, args imageHistoryArgs) (string, imageHistoryOutput, error) {
+	out := imageHistoryOutput{Image: args.Image}
+	if args.Image == "" {
+		return "", out, fmt.Errorf("image is required")
+	}
+	layers, err := imageHistory(ctx, exec, args.Image)
+	if err != nil {
+		return "", out, fmt.Errorf("image history failed: %w", err)
+	}
+	out.Layers = layers
+
+	var sb strings.Builder
+	for _, l := range layers {
+		out.Size += l.Size
+		fmt.Fprintf(&sb, "  %9s  %-20s  %s\n", decimalSize(uint64(l.Size)), l.Created, ellipsize(instruction(l.CreatedBy), 120))
+	}
+	return fmt.Sprintf("History of %s: %d layers, %s\n", args.Image, len(layers), decimalSize(uint64(out.Size))) + sb.String(), out, nil
+}
+
+type imageRemoveArgs struct {
+	Image  string `json:"image" jsonschema:"image name, name:tag or ID"`
+	Force  bool   `json:"force,omitempty" jsonschema:"remove the image even if stopped containers use it, or by ID when it has several tags"`
+	DryRun bool   `json:"dry_run,omitempty" jsonschema:"only report what would be removed"`
+}
+
+type imageRemoveOutput struct {
+	Image      string   `json:"image"`
+	ID         string   `json:"id"`
+	DryRun     bool     `json:"dry_run"`
+	Action     string   `json:"action" jsonschema:"untag when other tags keep the image, delete otherwise"`
+	Tags       []string `json:"tags"`
+	Size       int64    `json:"size_bytes"`
+	Containers []string `json:"containers" jsonschema:"containers created from the image, which make docker refuse unless forced"`
+	Output     []string `json:"output" jsonschema:"Untagged and Deleted lines reported by docker"`
+}
+
+// imageTagRef adds the implicit :latest to an image reference without a
+// tag, the way docker resolves it, so it can be compared with RepoTags. A
+// colon before the last slash belongs to a registry port, not a tag.
+// Digest references are returned unchanged.
+func imageTagRef(ref string) string {
+	if strings.Contains(ref, "@") || strings.Contains(ref[strings.LastIndex(ref, "/")+1:], ":") {
+		return ref
+	}
+	return ref + ":latest"
+}
+
+func handleImageRemove(ctx context.Context, exec docker.Executor, args imageRemoveArgs) (string, imageRemoveOutput, error) {
+	out := imageRemoveOutput{Image: args.Image, DryRun: args.DryRun}
+	if args.Image == "" {
+		return "", out, fmt.Errorf("image is required")
+	}
+	if !args.DryRun {
+		dockerArgs := []string{"image", "rm"}
+		if args.Force {
+			dockerArgs = append(dockerArgs, "--force")
+		}
+		output, err := exec.ExecCombined(ctx, append(dockerArgs, args.Image)...)
+		if err != nil {
+			return "", out, fmt.Errorf("image remove failed: %w", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				out.Output = append(out.Output, line)
+			}
+		}
+		out.Action = "untag"
+		for _, line := range out.Output {
+			if strings.HasPrefix(line, "Deleted:") {
+				out.Action = "delete"
+			}
+		}
+		return fmt.Sprintf("Removed %s:\n  %s", args.Image, strings.Join(out.Output, "\n  ")), out, nil
+	}
+
+	d, err := inspectImage(ctx, exec, args.Image)
+	if err != nil {
+		return "", out, fmt.Errorf("image inspect failed: %w", err)
+	}
+	users, err := imageUsers(ctx, exec)
+	if err != nil {
+		return "", out, err
+	}
+	out.ID, out.Tags, out.Size, out.Containers = d.ID, d.RepoTags, d.Size, users[d.ID]
+
+	// Removing one of several tags by name only untags it, even when forced.
+	tag := imageTagRef(args.Image)
+	out.Action = "delete"
+	if len(d.RepoTags) > 1 && slices.Contains(d.RepoTags, tag) {
+		out.Action = "untag"
+	}
+
+	var sb strings.Builder
+	if out.Action == "untag" {
+		others := slices.DeleteFunc(slices.Clone(d.RepoTags), func(t string) bool { return t == tag })
+		fmt.Fprintf(&sb, "Dry run: would untag %s; image %s stays, tagged %s.\n", tag, shortImageID(d.ID), strings.Join(others, ", "))
+	} else {
+		fmt.Fprintf(&sb, "Dry run: would delete image %s (%s, tags: %s).\n", shortImageID(d.ID), decimalSize(uint64(d.Size)), strings.Join(d.RepoTags, ", "))
+	}
+	if out.Action == "delete" && len(d.RepoTags) > 1 && !args.Force {
+		sb.WriteString("The image has several tags, so docker will refuse to delete it by ID unless force is set.\n")
+	}
+	if len(out.Containers) > 0 {
+		fmt.Fprintf(&sb, "Used by containers: %s.", strings.Join(out.Containers, ", "))
+		if !args.Force && out.Action == "delete" {
+			sb.WriteString(" docker will refuse unless they are removed or force is set.")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), out, nil
+}
+
+type imagePruneArgs struct {
+	All    bool `json:"all,omitempty" jsonschema:"remove every image no container uses, not only dangling ones"`
+	DryRun bool `json:"dry_run,omitempty" jsonschema:"only list the images that would be removed"`
+}
+
+type imagePruneOutput struct {
+	DryRun    bool           `json:"dry_run"`
+	All       bool           `json:"all"`
+	Images    []imageSummary `json:"images" jsonschema:"images that would be removed, for a dry run"`
+	Deleted   []string       `json:"deleted" jsonschema:"Untagged and Deleted lines reported by docker"`
+	Reclaimed uint64         `json:"reclaimed_bytes" jsonschema:"space docker reclaimed, or an upper bound for a dry run since layers may be shared"`
+}
+
+func handleImagePrune(ctx context.Context, exec docker.Executor, args imagePruneArgs) (string, imagePruneOutput, error) {
+	out := imagePruneOutput{DryRun: args.DryRun, All: args.All}
+	if !args.DryRun {
+		dockerArgs := []string{"image", "prune", "--force"}
+		if args.All {
+			dockerArgs = append(dockerArgs, "--all")
+		}
+		output, err := exec.Exec(ctx, dockerArgs...)
+		if err != nil {
+			return "", out, fmt.Errorf("image prune failed: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "untagged:"), strings.HasPrefix(line, "deleted:"),
+				strings.HasPrefix(line, "Untagged:"), strings.HasPrefix(line, "Deleted:"):
+				out.Deleted = append(out.Deleted, line)
+			case strings.HasPrefix(line, "Total reclaimed space:"):
+				out.Reclaimed, _ = parseSize(strings.TrimPrefix(line, "Total reclaimed space:"))
+			}
+		}
+		return fmt.Sprintf("Pruned images: %d entries removed, %s reclaimed.\n", len(out.Deleted), decimalSize(out.Reclaimed)), out, nil
+	}
+
+	var extra []string
+	if !args.All {
+		extra = []string{"--filter", "dangling=true"}
+	}
+	images, err := listImageSummaries(ctx, exec, extra...)
+	if err != nil {
+		return "", out, err
+	}
+	users, err := imageUsers(ctx, exec)
+	if err != nil {
+		return "", out, err
+	}
+	for _, img := range images {
+		// docker keeps any image a container, even a stopped one, uses.
+		if len(users[img.ID]) > 0 {
+			continue
+		}
+		out.Images = append(out.Images, img)
+		out.Reclaimed += img.Size
+	}
+
+	kind := "dangling"
+	if args.All {
+		kind = "unused"
+	}
+	if len(out.Images) == 0 {
+		return fmt.Sprintf("Dry run: no %s images to prune.\n", kind), out, nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Dry run: would remove %d %s images, reclaiming up to %s:\n", len(out.Images), kind, decimalSize(out.Reclaimed))
+	for _, img := range out.Images {
+		name := img.Repository
+		if len(img.Tags) > 0 {
+			name += ":" + strings.Join(img.Tags, ",")
+		}
+		fmt.Fprintf(&sb, "  %-12s %-30s %8s  %s\n", shortImageID(img.ID), name, decimalSize(img.Size), img.Created)
+	}
+	return sb.String(), out, nil
+}
+
+func registerImageTools(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_images",
+		Description: "List images grouped by repository with size, creation time, dangling flag and the containers (running or stopped) created from each, to spot stale builds and unused images.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args listImagesArgs) (*mcp.CallToolResult, listImagesOutput, error) {
+		result, out, err := handleListImages(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "image_inspect",
+		Description: "Show an image's entrypoint, cmd, env, exposed ports, labels, working directory, user, architecture and size.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args imageInspectArgs) (*mcp.CallToolResult, imageInspectOutput, error) {
+		result, out, err := handleImageInspect(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "image_history",
+		Description: "List an image's layers, newest first, with exact sizes and the instruction that created each.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args imageHistoryArgs) (*mcp.CallToolResult, imageHistoryOutput, error) {
+		result, out, err := handleImageHistory(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
+
+func registerImageRemoval(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "image_remove",
+		Description: "Remove or untag an image (docker image rm). With dry_run, reports whether it would be untagged or deleted, its size and the containers that use it, without removing anything.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args imageRemoveArgs) (*mcp.CallToolResult, imageRemoveOutput, error) {
+		result, out, err := handleImageRemove(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "image_prune",
+		Description: "Remove dangling images, or with all every image no container uses (docker image prune). With dry_run, lists the images that would go and the space they take, without removing anything.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args imagePruneArgs) (*mcp.CallToolResult, imagePruneOutput, error) {
+		result, out, err := handleImagePrune(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
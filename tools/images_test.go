@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const imagesLs = `{"ID":"sha256:aaa111aaa111aaa111","Repository":"shop/api","Tag":"latest","CreatedAt":"2024-01-01 10:00:00 +0000 UTC","Size":"187MB"}
+{"ID":"sha256:aaa111aaa111aaa111","Repository":"shop/api","Tag":"v2","CreatedAt":"2024-01-01 10:00:00 +0000 UTC","Size":"187MB"}
+{"ID":"sha256:bbb222bbb222bbb222","Repository":"shop/api","Tag":"v1","CreatedAt":"2023-12-01 10:00:00 +0000 UTC","Size":"180MB"}
+{"ID":"sha256:ccc333ccc333ccc333","Repository":"nginx","Tag":"1.25","CreatedAt":"2023-11-01 10:00:00 +0000 UTC","Size":"43.2MB"}
+{"ID":"sha256:ddd444ddd444ddd444","Repository":"<none>","Tag":"<none>","CreatedAt":"2023-12-31 10:00:00 +0000 UTC","Size":"185MB"}`
+
+// imagesMock answers the container lookups images use: api runs the old v1
+// build, web runs nginx.
+func imagesMock() *docker.Mock {
+	mock := docker.NewMock()
+	mock.On("image ls --no-trunc --format {{json .}}", imagesLs, nil)
+	mock.On("ps -a --format {{json .}}", `{"ID":"c1","Names":"shop-api-1","Image":"shop/api:v1","State":"running","Labels":""}
+{"ID":"c2","Names":"web","Image":"nginx:1.25","State":"exited","Labels":""}`, nil)
+	mock.On("inspect c1 c2", `[{"Id":"c1","Name":"/shop-api-1","Image":"sha256:bbb222bbb222bbb222"},{"Id":"c2","Name":"/web","Image":"sha256:ccc333ccc333ccc333"}]`, nil)
+	return mock
+}
+
+func TestHandleListImages(t *testing.T) {
+	result, out, err := handleListImages(context.Background(), imagesMock(), listImagesArgs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Images != 4 || out.Dangling != 1 || out.TotalSize != 595_200_000 || len(out.Warnings) != 0 {
+		t.Errorf("unexpected totals %+v", out)
+	}
+	var repos []string
+	for _, r := range out.Repositories {
+		repos = append(repos, r.Repository)
+	}
+	if got := strings.Join(repos, ","); got != "nginx,shop/api,<none>" {
+		t.Fatalf("unexpected repository order %s", got)
+	}
+	api := out.Repositories[1].Images
+	if len(api) != 2 || strings.Join(api[0].Tags, ",") != "latest,v2" || api[0].Created != "2024-01-01T10:00:00Z" || len(api[0].Containers) != 0 {
+		t.Errorf("expected latest and v2 merged into one unused image, got %+v", api[0])
+	}
+	if strings.Join(api[1].Containers, ",") != "shop-api-1" {
+		t.Errorf("expected v1 used by shop-api-1, got %+v", api[1])
+	}
+	if none := out.Repositories[2].Images[0]; !none.Dangling || len(none.Tags) != 0 {
+		t.Errorf("unexpected dangling image %+v", none)
+	}
+	for _, want := range []string{"4 images, 595MB, 1 dangling", "=== shop/api ===", "aaa111aaa111 latest,v2", "used by shop-api-1", "used by web"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleListImages_FiltersAndWarnings(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("image ls --no-trunc --format {{json .}} --filter dangling=true shop/api", "", nil)
+	result, _, err := handleListImages(context.Background(), mock, listImagesArgs{Repository: "shop/api", Dangling: true})
+	if err != nil || result != "No images found." {
+		t.Errorf("unexpected result %q %v", result, err)
+	}
+
+	mock.On("image ls --no-trunc --format {{json .}}", imagesLs, nil)
+	mock.On("ps -a --format {{json .}}", "", fmt.Errorf("daemon busy"))
+	_, out, err := handleListImages(context.Background(), mock, listImagesArgs{})
+	if err != nil || out.Images != 4 || len(out.Warnings) != 1 {
+		t.Errorf("expected the listing with a warning, got %+v %v", out, err)
+	}
+}
+
+const imageInspectJSON = `[{"Id":"sha256:aaa111aaa111aaa111","RepoTags":["shop/api:latest","shop/api:v2"],"RepoDigests":[],
+	"Created":"2024-01-01T10:00:00Z","Size":187000000,"Architecture":"arm64","Os":"linux","Variant":"v8",
+	"Config":{"Entrypoint":["/entrypoint.sh"],"Cmd":["serve","--port","8080"],"Env":["PATH=/usr/bin","APP_ENV=prod"],
+	"ExposedPorts":{"9090/tcp":{},"8080/tcp":{}},"Labels":{"org.opencontainers.image.version":"2.0"},"WorkingDir":"/app","User":"app"},
+	"RootFS":{"Layers":["sha256:l1","sha256:l2","sha256:l3"]}}]`
+
+func TestHandleImageInspect(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("image inspect shop/api", imageInspectJSON, nil)
+	mock.On("image inspect ghost", "[]", fmt.Errorf("Error: No such image: ghost"))
+
+	result, out, err := handleImageInspect(context.Background(), mock, imageInspectArgs{Image: "shop/api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Architecture != "arm64" || out.Layers != 3 || strings.Join(out.ExposedPorts, ",") != "8080/tcp,9090/tcp" || out.Cmd[0] != "serve" {
+		t.Errorf("unexpected output %+v", out)
+	}
+	for _, want := range []string{"Platform:    linux/arm64/v8", `Entrypoint:  ["/entrypoint.sh"]`, `Cmd:         ["serve","--port","8080"]`, "  APP_ENV=prod", "  org.opencontainers.image.version=2.0", "187MB in 3 layers"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+
+	if _, _, err := handleImageInspect(context.Background(), mock, imageInspectArgs{Image: "ghost"}); err == nil || !strings.Contains(err.Error(), "No such image") {
+		t.Errorf("expected inspect error, got %v", err)
+	}
+	if _, _, err := handleImageInspect(context.Background(), mock, imageInspectArgs{}); err == nil {
+		t.Error("expected error for missing image")
+	}
+}
+
+func TestHandleImageHistory(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("history --no-trunc --human=false --format {{json .}} shop/api", strings.Join([]string{
+		`{"ID":"sha256:aaa111","CreatedAt":"2024-01-01T10:00:00Z","CreatedBy":"CMD [\"serve\"]","Size":"0","Comment":"buildkit.dockerfile.v0"}`,
+		`{"ID":"<missing>","CreatedAt":"2024-01-01T09:59:00Z","CreatedBy":"COPY . /app # buildkit","Size":"52000000","Comment":"buildkit.dockerfile.v0"}`,
+		`{"ID":"<missing>","CreatedAt":"2023-11-01T00:00:00Z","CreatedBy":"/bin/sh -c apt-get update && apt-get install -y curl","Size":"30000000","Comment":""}`,
+		`{"ID":"<missing>","CreatedAt":"2023-11-01T00:00:00Z","CreatedBy":"/bin/sh -c #(nop) ADD file:abc in / ","Size":"80000000","Comment":""}`,
+	}, "\n"), nil)
+
+	result, out, err := handleImageHistory(context.Background(), mock, imageHistoryArgs{Image: "shop/api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Layers) != 4 || out.Size != 162_000_000 || !out.Layers[0].Empty || out.Layers[1].Size != 52_000_000 {
+		t.Errorf("unexpected output %+v", out)
+	}
+	for _, want := range []string{"4 layers, 162MB", "52MB", "COPY . /app\n", "RUN apt-get update && apt-get install -y curl", "ADD file:abc in /\n"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleImageRemove(t *testing.T) {
+	mock := imagesMock()
+	mock.On("image inspect shop/api:v2", imageInspectJSON, nil)
+	mock.On("image inspect shop/api:v1", `[{"Id":"sha256:bbb222bbb222bbb222","RepoTags":["shop/api:v1"],"Size":180000000}]`, nil)
+	mock.On("image rm shop/api:v1", "Untagged: shop/api:v1\nDeleted: sha256:bbb222bbb222bbb222\n", nil)
+	mock.On("image rm --force shop/api:v2", "Untagged: shop/api:v2\n", nil)
+
+	result, out, err := handleImageRemove(context.Background(), mock, imageRemoveArgs{Image: "shop/api:v2", DryRun: true})
+	if err != nil || out.Action != "untag" || !strings.Contains(result, "would untag shop/api:v2; image aaa111aaa111 stays, tagged shop/api:latest") {
+		t.Errorf("unexpected untag preview %q %+v %v", result, out, err)
+	}
+
+	mock.On("image inspect shop/api", imageInspectJSON, nil)
+	result, out, err = handleImageRemove(context.Background(), mock, imageRemoveArgs{Image: "shop/api", DryRun: true})
+	if err != nil || out.Action != "untag" || !strings.Contains(result, "would untag shop/api:latest; image aaa111aaa111 stays, tagged shop/api:v2") {
+		t.Errorf("expected a name without a tag to untag :latest, got %q %+v %v", result, out, err)
+	}
+
+	result, out, err = handleImageRemove(context.Background(), mock, imageRemoveArgs{Image: "shop/api:v1", DryRun: true})
+	if err != nil || out.Action != "delete" || out.Size != 180_000_000 || strings.Join(out.Containers, ",") != "shop-api-1" {
+		t.Errorf("unexpected delete preview %+v %v", out, err)
+	}
+	if !strings.Contains(result, "would delete image bbb222bbb222 (180MB") || !strings.Contains(result, "docker will refuse") {
+		t.Errorf("unexpected preview:\n%s", result)
+	}
+	for _, call := range mock.Calls() {
+		if call[0] == "image" && call[1] == "rm" {
+			t.Fatalf("dry run removed an image: %v", call)
+		}
+	}
+
+	_, out, err = handleImageRemove(context.Background(), mock, imageRemoveArgs{Image: "shop/api:v1"})
+	if err != nil || out.Action != "delete" || len(out.Output) != 2 {
+		t.Errorf("unexpected removal %+v %v", out, err)
+	}
+	_, out, err = handleImageRemove(context.Background(), mock, imageRemoveArgs{Image: "shop/api:v2", Force: true})
+	if err != nil || out.Action != "untag" {
+		t.Errorf("unexpected forced removal %+v %v", out, err)
+	}
+	if _, _, err := handleImageRemove(context.Background(), mock, imageRemoveArgs{}); err == nil {
+		t.Error("expected error for missing image")
+	}
+}
+
+func TestImageTagRef(t *testing.T) {
+	for ref, want := range map[string]string{
+		"nginx":                       "nginx:latest",
+		"nginx:1.27":                  "nginx:1.27",
+		"localhost:5000/shop/api":     "localhost:5000/shop/api:latest",
+		"localhost:5000/shop/api:v2":  "localhost:5000/shop/api:v2",
+		"nginx@sha256:0123456789abcd": "nginx@sha256:0123456789abcd",
+	} {
+		if got := imageTagRef(ref); got != want {
+			t.Errorf("imageTagRef(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestHandleImagePrune(t *testing.T) {
+	mock := imagesMock()
+	mock.On("image ls --no-trunc --format {{json .}} --filter dangling=true", `{"ID":"sha256:ddd444ddd444ddd444","Repository":"<none>","Tag":"<none>","CreatedAt":"2023-12-31 10:00:00 +0000 UTC","Size":"185MB"}`, nil)
+	mock.On("image prune --force --all", "Deleted Images:\nuntagged: shop/api:latest\ndeleted: sha256:aaa111aaa111aaa111\n\nTotal reclaimed space: 187MB\n", nil)
+
+	result, out, err := handleImagePrune(context.Background(), mock, imagePruneArgs{DryRun: true})
+	if err != nil || len(out.Images) != 1 || out.Reclaimed != 185_000_000 || !strings.Contains(result, "would remove 1 dangling images, reclaiming up to 185MB") {
+		t.Errorf("unexpected dangling preview %q %+v %v", result, out, err)
+	}
+
+	result, out, err = handleImagePrune(context.Background(), mock, imagePruneArgs{DryRun: true, All: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, img := range out.Images {
+		ids = append(ids, shortImageID(img.ID))
+	}
+	if got := strings.Join(ids, ","); got != "aaa111aaa111,ddd444ddd444" {
+		t.Errorf("expected the images no container uses, got %s\n%s", got, result)
+	}
+
+	result, out, err = handleImagePrune(context.Background(), mock, imagePruneArgs{All: true})
+	if err != nil || len(out.Deleted) != 2 || out.Reclaimed != 187_000_000 || !strings.Contains(result, "2 entries removed, 187MB reclaimed") {
+		t.Errorf("unexpected prune %q %+v %v", result, out, err)
+	}
+}
+
+func TestInstruction(t *testing.T) {
+	for createdBy, want := range map[string]string{
+		"/bin/sh -c #(nop)  EXPOSE 8080":           "EXPOSE 8080",
+		"/bin/sh -c apt-get update":                "RUN apt-get update",
+		"RUN /bin/sh -c npm ci # buildkit":         "RUN npm ci",
+		"COPY --from=build /out /app # buildkit":   "COPY --from=build /out /app",
+		"|1 VERSION=2 /bin/sh -c make\n\t install": "|1 VERSION=2 /bin/sh -c make install",
+	} {
+		if got := instruction(createdBy); got != want {
+			t.Errorf("instruction(%q) = %q, want %q", createdBy, got, want)
+		}
+	}
+}
@@ -16,60 +16,144 @@ type listContainersArgs struct {
 	Project string `json:"project,omitempty" jsonschema:"filter by Compose project name"`
 }
 
-// containerInfo represents a single container from docker ps JSON output.
+type listContainersOutput struct {
+	Projects []projectContainers `json:"projects" jsonschema:"containers grouped by Compose project; standalone containers come last with an empty project"`
+}
+
+type projectContainers struct {
+	Project    string             `json:"project" jsonschema:"Compose project name, empty for standalone containers"`
+	Containers []containerSummary `json:"containers"`
+}
+
+type containerSummary struct {
+	ID      string           `json:"id"`
+	Name    string           `json:"name"`
+	Image   string           `json:"image"`
+	State   string           `json:"state"`
+	Status  string           `json:"status"`
+	Service string           `json:"service,omitempty" jsonschema:"Compose service name"`
+	Ports   string           `json:"ports,omitempty"`
+	Exit    *exitExplanation `json:"exit,omitempty" jsonschema:"why the container stopped, for abnormal exits and restarting containers"`
+}
+
+// containerInfo represents a single container, listed either through the
+// typed docker.ContainerLister path or parsed from docker ps JSON output.
 type containerInfo struct {
-	ID        string `json:"ID"`
-	Names     string `json:"Names"`
-	Image     string `json:"Image"`
-	State     string `json:"State"`
-	Status    string `json:"Status"`
-	Ports     string `json:"Ports"`
-	Labels    string `json:"Labels"`
-	CreatedAt string `json:"CreatedAt"`
-	Networks  string `json:"Networks"`
+	ID        string
+	Names     string
+	Image     string
+	State     string
+	Status    string
+	Ports     string
+	Labels    map[string]string
+	CreatedAt string
+	Networks  string
 }
 
 // composeProject extracts the com.docker.compose.project label value.
 func (c *containerInfo) composeProject() string {
-	for _, label := range strings.Split(c.Labels, ",") {
-		parts := strings.SplitN(label, "=", 2)
-		if len(parts) == 2 && parts[0] == "com.docker.compose.project" {
-			return parts[1]
+	return c.Labels["com.docker.compose.project"]
+}
+
+// psContainers lists containers, optionally including stopped ones and
+// restricted to those carrying every given label ("key" or "key=value").
+// Executors implementing docker.ContainerLister are queried directly;
+// otherwise docker ps JSON output is parsed.
+func psContainers(ctx context.Context, exec docker.Executor, all bool, labels ...string) ([]containerInfo, error) {
+	if lister, ok := exec.(docker.ContainerLister); ok {
+		list, err := lister.ListContainers(ctx, docker.ListOptions{All: all, Labels: labels})
+		if err != nil {
+			return nil, err
+		}
+		containers := make([]containerInfo, 0, len(list))
+		for _, c := range list {
+			containers = append(containers, containerInfo{
+				ID:        c.ID,
+				Names:     c.Name,
+				Image:     c.Image,
+				State:     c.State,
+				Status:    c.Status,
+				Ports:     docker.FormatPorts(c.Ports),
+				Labels:    c.Labels,
+				CreatedAt: c.Created.Format("2006-01-02 15:04:05 -0700 MST"),
+				Networks:  strings.Join(c.Networks, ","),
+			})
 		}
+		return containers, nil
 	}
-	return ""
-}
 
-func handleListContainers(ctx context.Context, exec docker.Executor, args listContainersArgs) (string, error) {
-	// Default to showing all containers (including stopped) when not explicitly set.
-	showAll := args.All == nil || *args.All
 	cmdArgs := []string{"ps"}
-	if showAll {
+	if all {
 		cmdArgs = append(cmdArgs, "-a")
 	}
 	cmdArgs = append(cmdArgs, "--format", "{{json .}}")
+	for _, label := range labels {
+		cmdArgs = append(cmdArgs, "--filter", "label="+label)
+	}
 
 	output, err := exec.Exec(ctx, cmdArgs...)
 	if err != nil {
-		return "", fmt.Errorf("failed to list containers: %w", err)
-	}
-
-	output = strings.TrimSpace(output)
-	if output == "" {
-		return "No containers found.", nil
+		return nil, err
 	}
 
 	var containers []containerInfo
-	for _, line := range strings.Split(output, "\n") {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
-		var c containerInfo
-		if err := json.Unmarshal([]byte(line), &c); err != nil {
-			return "", fmt.Errorf("failed to parse container JSON: %w", err)
+		// docker ps outputs Labels as a comma-separated string like
+		// "key1=val1,key2=val2" rather than a JSON map.
+		var raw struct {
+			ID        string `json:"ID"`
+			Names     string `json:"Names"`
+			Image     string `json:"Image"`
+			State     string `json:"State"`
+			Status    string `json:"Status"`
+			Ports     string `json:"Ports"`
+			Labels    string `json:"Labels"`
+			CreatedAt string `json:"CreatedAt"`
+			Networks  string `json:"Networks"`
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse container JSON: %w", err)
+		}
+		labels := make(map[string]string)
+		if raw.Labels != "" {
+			for _, pair := range strings.Split(raw.Labels, ",") {
+				kv := strings.SplitN(pair, "=", 2)
+				if len(kv) == 2 {
+					labels[kv[0]] = kv[1]
+				}
+			}
 		}
-		containers = append(containers, c)
+		containers = append(containers, containerInfo{
+			ID:        raw.ID,
+			Names:     raw.Names,
+			Image:     raw.Image,
+			State:     raw.State,
+			Status:    raw.Status,
+			Ports:     raw.Ports,
+			Labels:    labels,
+			CreatedAt: raw.CreatedAt,
+			Networks:  raw.Networks,
+		})
+	}
+	return containers, nil
+}
+
+func handleListContainers(ctx context.Context, exec docker.Executor, args listContainersArgs) (string, listContainersOutput, error) {
+	// Default to showing all containers (including stopped) when not explicitly set.
+	showAll := args.All == nil || *args.All
+
+	var out listContainersOutput
+	containers, err := psContainers(ctx, exec, showAll)
+	if err != nil {
+		return "", out, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	if len(containers) == 0 {
+		return "No containers found.", out, nil
 	}
 
 	// Group by Compose project
@@ -87,11 +171,13 @@ func handleListContainers(ctx context.Context, exec docker.Executor, args listCo
 
 	if len(groups) == 0 {
 		if args.Project != "" {
-			return fmt.Sprintf("No containers found for project %q.", args.Project), nil
+			return fmt.Sprintf("No containers found for project %q.", args.Project), out, nil
 		}
-		return "No containers found.", nil
+		return "No containers found.", out, nil
 	}
 
+	exits := explainListedExits(ctx, exec, containers)
+
 	// Sort group names for deterministic output, with (standalone) last
 	groupNames := make([]string, 0, len(groups))
 	for name := range groups {
@@ -113,29 +199,74 @@ func handleListContainers(ctx context.Context, exec docker.Executor, args listCo
 			sb.WriteString("\n")
 		}
 		sb.WriteString(fmt.Sprintf("=== %s ===\n", groupName))
+		group := projectContainers{Containers: []containerSummary{}}
+		if groupName != "(standalone)" {
+			group.Project = groupName
+		}
 		for _, c := range groups[groupName] {
 			sb.WriteString(fmt.Sprintf("  %-15s %-25s %-10s %s\n", c.Names, c.Image, c.State, c.Status))
+			exit := exits[c.ID]
+			if exit != nil {
+				sb.WriteString(fmt.Sprintf("    %s; %s\n", exit.describe(), exit.LikelyCause))
+			}
+			group.Containers = append(group.Containers, containerSummary{
+				ID:      c.ID,
+				Name:    c.Names,
+				Image:   c.Image,
+				State:   c.State,
+				Status:  c.Status,
+				Service: c.Labels["com.docker.compose.service"],
+				Ports:   c.Ports,
+				Exit:    exit,
+			})
 		}
+		out.Projects = append(out.Projects, group)
 	}
 
-	return sb.String(), nil
+	return sb.String(), out, nil
+}
+
+// explainListedExits explains the containers that stopped abnormally or are
+// restarting, keyed by their listed ID. Only those containers are inspected,
+// in a single call; inspect failures leave the listing unexplained.
+func explainListedExits(ctx context.Context, exec docker.Executor, containers []containerInfo) map[string]*exitExplanation {
+	var ids []string
+	for _, c := range containers {
+		if needsExitExplanation(c) {
+			ids = append(ids, c.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	details, err := inspectExisting(ctx, exec, ids...)
+	if err != nil {
+		return nil
+	}
+	exits := make(map[string]*exitExplanation, len(ids))
+	for _, id := range ids {
+		for i := range details {
+			if strings.HasPrefix(details[i].ID, id) {
+				exits[id] = explainExit(&details[i])
+				break
+			}
+		}
+	}
+	return exits
 }
 
 func registerListContainers(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_containers",
 		Description: "List Docker containers, grouped by Compose project. Shows container name, image, state, and status.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args listContainersArgs) (*mcp.CallToolResult, any, error) {
-			// All defaults to true when nil (not provided by client).
-		result, err := handleListContainers(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args listContainersArgs) (*mcp.CallToolResult, listContainersOutput, error) {
+		// All defaults to true when nil (not provided by client).
+		result, out, err := handleListContainers(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
@@ -25,11 +25,18 @@ func TestHandleListContainers_MixedComposeAndStandalone(t *testing.T) {
 
 	mock.On("ps -a --format {{json .}}", lines, nil)
 
-	result, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true)})
+	result, out, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true)})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
+	if len(out.Projects) != 2 || out.Projects[0].Project != "webapp" || out.Projects[1].Project != "" {
+		t.Fatalf("expected webapp then standalone groups, got %+v", out.Projects)
+	}
+	if web := out.Projects[0].Containers[0]; web.Name != "webapp-web-1" || web.Service != "web" || web.Ports != "0.0.0.0:80->80/tcp" {
+		t.Errorf("unexpected structured container: %+v", web)
+	}
+
 	// Should have webapp group before standalone
 	if !strings.Contains(result, "=== webapp ===") {
 		t.Error("expected webapp group header")
@@ -66,7 +73,7 @@ func TestHandleListContainers_FilterByProject(t *testing.T) {
 
 	mock.On("ps -a --format {{json .}}", lines, nil)
 
-	result, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true), Project: "webapp"})
+	result, _, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true), Project: "webapp"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -89,7 +96,7 @@ func TestHandleListContainers_EmptyResult(t *testing.T) {
 	mock := docker.NewMock()
 	mock.On("ps -a --format {{json .}}", "", nil)
 
-	result, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true)})
+	result, _, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true)})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -103,7 +110,7 @@ func TestHandleListContainers_DockerError(t *testing.T) {
 	mock := docker.NewMock()
 	mock.On("ps -a --format {{json .}}", "", fmt.Errorf("Cannot connect to the Docker daemon"))
 
-	_, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true)})
+	_, _, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true)})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -119,7 +126,7 @@ func TestHandleListContainers_NotAllFlag(t *testing.T) {
 	line := `{"ID":"abc123","Names":"running-container","Image":"nginx","State":"running","Status":"Up 1 hour","Ports":"","Labels":"","CreatedAt":"","Networks":""}`
 	mock.On("ps --format {{json .}}", line, nil)
 
-	result, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(false)})
+	result, _, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(false)})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -146,7 +153,7 @@ func TestHandleListContainers_FilterByProjectNoMatch(t *testing.T) {
 	line := `{"ID":"abc123","Names":"webapp-web-1","Image":"nginx","State":"running","Status":"Up","Ports":"","Labels":"com.docker.compose.project=webapp","CreatedAt":"","Networks":""}`
 	mock.On("ps -a --format {{json .}}", line, nil)
 
-	result, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true), Project: "nonexistent"})
+	result, _, err := handleListContainers(context.Background(), mock, listContainersArgs{All: boolPtr(true), Project: "nonexistent"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -163,7 +170,7 @@ func TestHandleListContainers_DefaultAllTrue(t *testing.T) {
 	// When All is nil (not provided), the handler should default to true and pass -a flag
 	mock.On("ps -a --format {{json .}}", line, nil)
 
-	result, err := handleListContainers(context.Background(), mock, listContainersArgs{})
+	result, _, err := handleListContainers(context.Background(), mock, listContainersArgs{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -188,3 +195,69 @@ func TestHandleListContainers_DefaultAllTrue(t *testing.T) {
 		t.Error("expected -a flag when All is nil (default true)")
 	}
 }
+
+// typedMock is a docker.Mock that also serves typed container listings and
+// stats, the way docker.API does.
+type typedMock struct {
+	*docker.Mock
+	containers []docker.Container
+	stats      []docker.Stats
+}
+
+func (m *typedMock) ListContainers(ctx context.Context, opts docker.ListOptions) ([]docker.Container, error) {
+	var result []docker.Container
+	for _, c := range m.containers {
+		if !opts.All && c.State != "running" {
+			continue
+		}
+		matched := true
+		for _, label := range opts.Labels {
+			key, value, _ := strings.Cut(label, "=")
+			if v, ok := c.Labels[key]; !ok || v != value {
+				matched = false
+			}
+		}
+		if matched {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *typedMock) ContainerStats(ctx context.Context, container string) ([]docker.Stats, error) {
+	if container == "" {
+		return m.stats, nil
+	}
+	for _, s := range m.stats {
+		if s.Name == container {
+			return []docker.Stats{s}, nil
+		}
+	}
+	return nil, fmt.Errorf("no such container: %s", container)
+}
+
+func TestHandleListContainers_TypedLister(t *testing.T) {
+	mock := &typedMock{
+		Mock: docker.NewMock(),
+		containers: []docker.Container{
+			{ID: "abc123", Name: "webapp-web-1", Image: "nginx:latest", State: "running", Status: "Up 2 hours",
+				Labels: map[string]string{"com.docker.compose.project": "webapp", "com.docker.compose.project.config_files": "a.yml,b.yml"}},
+			{ID: "def456", Name: "old-job", Image: "busybox", State: "exited", Status: "Exited (0) 1 hour ago"},
+		},
+	}
+
+	result, _, err := handleListContainers(context.Background(), mock, listContainersArgs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(result, "=== webapp ===") {
+		t.Errorf("expected webapp group header, got:\n%s", result)
+	}
+	if !strings.Contains(result, "old-job") {
+		t.Errorf("expected stopped container with default all=true, got:\n%s", result)
+	}
+	if len(mock.Calls()) != 0 {
+		t.Errorf("expected no CLI calls when the executor lists containers natively, got %v", mock.Calls())
+	}
+}
@@ -34,15 +34,30 @@ func countLines(text string) map[string]int {
 	return counts
 }
 
-func handleLogDiff(ctx context.Context, exec docker.Executor, args logDiffArgs) (string, error) {
+type logDiffOutput struct {
+	Container     string        `json:"container"`
+	OnlyInPeriod1 []string      `json:"only_in_period1"`
+	OnlyInPeriod2 []string      `json:"only_in_period2"`
+	Changed       []logLineDiff `json:"changed" jsonschema:"lines present in both periods with different counts"`
+	Common        []string      `json:"common" jsonschema:"lines present in both periods with the same count"`
+}
+
+type logLineDiff struct {
+	Line    string `json:"line"`
+	Period1 int    `json:"period1" jsonschema:"occurrences in period 1"`
+	Period2 int    `json:"period2" jsonschema:"occurrences in period 2"`
+}
+
+func handleLogDiff(ctx context.Context, exec docker.Executor, args logDiffArgs) (string, logDiffOutput, error) {
+	out := logDiffOutput{Container: args.Container}
 	logs1, err := fetchLogs(ctx, exec, args.Container, args.Period1Start, args.Period1End)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch period 1 logs: %w", err)
+		return "", out, fmt.Errorf("failed to fetch period 1 logs: %w", err)
 	}
 
 	logs2, err := fetchLogs(ctx, exec, args.Container, args.Period2Start, args.Period2End)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch period 2 logs: %w", err)
+		return "", out, fmt.Errorf("failed to fetch period 2 logs: %w", err)
 	}
 
 	counts1 := countLines(logs1)
@@ -73,6 +88,7 @@ func handleLogDiff(ctx context.Context, exec docker.Executor, args logDiffArgs)
 			onlyIn2 = append(onlyIn2, line)
 		case c1 != c2:
 			changed = append(changed, fmt.Sprintf("  %s (period1: %dx, period2: %dx)", line, c1, c2))
+			out.Changed = append(out.Changed, logLineDiff{Line: line, Period1: c1, Period2: c2})
 		default:
 			common = append(common, line)
 		}
@@ -82,6 +98,8 @@ func handleLogDiff(ctx context.Context, exec docker.Executor, args logDiffArgs)
 	sort.Strings(onlyIn2)
 	sort.Strings(changed)
 	sort.Strings(common)
+	sort.Slice(out.Changed, func(i, j int) bool { return out.Changed[i].Line < out.Changed[j].Line })
+	out.OnlyInPeriod1, out.OnlyInPeriod2, out.Common = onlyIn1, onlyIn2, common
 
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("=== Log Diff: %s ===\n", args.Container))
@@ -124,23 +142,20 @@ func handleLogDiff(ctx context.Context, exec docker.Executor, args logDiffArgs)
 		}
 	}
 
-	return sb.String(), nil
+	return sb.String(), out, nil
 }
 
 func registerLogDiff(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "log_diff",
 		Description: "Compare container logs between two time periods. Useful for debugging regressions by identifying what changed in log output.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args logDiffArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleLogDiff(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args logDiffArgs) (*mcp.CallToolResult, logDiffOutput, error) {
+		result, out, err := handleLogDiff(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
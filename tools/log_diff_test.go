@@ -26,7 +26,7 @@ func TestHandleLogDiff_BasicDiff(t *testing.T) {
 		Period2End:   "now",
 	}
 
-	result, err := handleLogDiff(context.Background(), mock, args)
+	result, _, err := handleLogDiff(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -73,7 +73,7 @@ func TestHandleLogDiff_IdenticalLogs(t *testing.T) {
 		Period2End:   "now",
 	}
 
-	result, err := handleLogDiff(context.Background(), mock, args)
+	result, _, err := handleLogDiff(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -130,7 +130,7 @@ func TestHandleLogDiff_EmptyPeriod(t *testing.T) {
 		Period2End:   "now",
 	}
 
-	result, err := handleLogDiff(context.Background(), mock, args)
+	result, _, err := handleLogDiff(context.Background(), mock, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -165,7 +165,7 @@ func TestHandleLogDiff_DockerError(t *testing.T) {
 		Period2End:   "now",
 	}
 
-	_, err := handleLogDiff(context.Background(), mock, args)
+	_, _, err := handleLogDiff(context.Background(), mock, args)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newNotifier returns a function that relays intermediate output of a
+// long-running tool call to the client while the call is in flight. Messages
+// are sent as progress notifications when the client supplied a progress
+// token, and as logging notifications (under the given logger name)
+// otherwise. Delivery is best effort: errors are ignored.
+func newNotifier(ctx context.Context, req *mcp.CallToolRequest, logger string) func(msg string) {
+	if req == nil || req.Session == nil {
+		return func(string) {}
+	}
+
+	token := req.Params.GetProgressToken()
+	var progress float64
+	return func(msg string) {
+		if token != nil {
+			progress++
+			req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Message:       msg,
+				Progress:      progress,
+			})
+			return
+		}
+		req.Session.Log(ctx, &mcp.LoggingMessageParams{
+			Level:  "info",
+			Logger: logger,
+			Data:   msg,
+		})
+	}
+}
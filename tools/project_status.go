@@ -0,0 +1,411 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	defaultStatusMinutes = 15
+	maxStatusMinutes     = 1440
+	// projectStatusWorkers bounds how many containers are inspected and
+	// have their logs read at the same time.
+	projectStatusWorkers = 4
+	// projectStatusLogTail caps the log lines read per container.
+	projectStatusLogTail = 5000
+)
+
+type projectStatusArgs struct {
+	Project string `json:"project" jsonschema:"Compose project name"`
+	Minutes int    `json:"minutes,omitempty" jsonschema:"count error log lines written in the last N minutes (default: 15, max: 1440)"`
+}
+
+type projectStatusOutput struct {
+	Project     string          `json:"project"`
+	LogMinutes  int             `json:"log_minutes" jsonschema:"window error lines were counted over"`
+	Services    []serviceStatus `json:"services"`
+	Degraded    []string        `json:"degraded" jsonschema:"services that need attention"`
+	Warnings    []string        `json:"warnings,omitempty" jsonschema:"data sources that could not be read"`
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+type serviceStatus struct {
+	Service       string          `json:"service"`
+	Replicas      int             `json:"replicas"`
+	Running       int             `json:"running" jsonschema:"replicas currently running"`
+	State         string          `json:"state" jsonschema:"common container state, or mixed"`
+	Health        string          `json:"health,omitempty" jsonschema:"health summary, empty without a healthcheck"`
+	Restarts      int             `json:"restarts" jsonschema:"restart count summed over replicas"`
+	UptimeSeconds float64         `json:"uptime_seconds" jsonschema:"uptime of the most recently started running replica"`
+	Ports         []string        `json:"ports" jsonschema:"published ports as host->container/proto"`
+	CPUPercent    float64         `json:"cpu_percent" jsonschema:"CPU summed over replicas"`
+	MemUsage      uint64          `json:"mem_usage_bytes" jsonschema:"memory summed over replicas"`
+	ErrorLines    int             `json:"error_lines" jsonschema:"log lines in the window that look like errors, as counted by diagnose_container"`
+	Degraded      bool            `json:"degraded"`
+	Reasons       []string        `json:"reasons,omitempty" jsonschema:"why the service is degraded"`
+	Containers    []replicaStatus `json:"containers"`
+}
+
+type replicaStatus struct {
+	Container     string  `json:"container"`
+	ID            string  `json:"id"`
+	State         string  `json:"state"`
+	ExitCode      int     `json:"exit_code,omitempty"`
+	Health        string  `json:"health,omitempty"`
+	Restarts      int     `json:"restarts"`
+	UptimeSeconds float64 `json:"uptime_seconds,omitempty"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemUsage      uint64  `json:"mem_usage_bytes"`
+	MemPercent    float64 `json:"mem_percent"`
+	ErrorLines    int     `json:"error_lines"`
+}
+
+// replicaData is what the worker pool gathers for one container.
+type replicaData struct {
+	details    *containerDetails
+	errorLines int
+	warnings   []string
+}
+
+// gatherReplicas inspects every container and counts its recent error lines
+// using a bounded pool of workers. Results are indexed like containers.
+func gatherReplicas(ctx context.Context, exec docker.Executor, containers []containerInfo, minutes int) []replicaData {
+	results := make([]replicaData, len(containers))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(projectStatusWorkers, len(containers)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = gatherReplica(ctx, exec, containers[i], minutes)
+			}
+		}()
+	}
+	for i := range containers {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func gatherReplica(ctx context.Context, exec docker.Executor, c containerInfo, minutes int) replicaData {
+	var r replicaData
+	details, err := inspectDetails(ctx, exec, c.ID)
+	switch {
+	case err != nil:
+		r.warnings = append(r.warnings, fmt.Sprintf("inspect %s: %v", c.Names, err))
+	case len(details) == 0:
+		r.warnings = append(r.warnings, fmt.Sprintf("inspect %s: no data returned", c.Names))
+	default:
+		r.details = &details[0]
+	}
+
+	_, logs, err := handleGetLogs(ctx, exec, getLogsArgs{Container: c.ID, Tail: projectStatusLogTail, Since: strconv.Itoa(minutes) + "m"})
+	if err != nil {
+		r.warnings = append(r.warnings, fmt.Sprintf("logs %s: %v", c.Names, err))
+	}
+	for _, line := range logs.Lines {
+		if errorLinePattern.MatchString(line) {
+			r.errorLines++
+		}
+	}
+	return r
+}
+
+// findStats returns the stats sample for a container, matching by name or
+// by ID, where either side may be a short ID.
+func findStats(stats []docker.Stats, c containerInfo) *docker.Stats {
+	for i, s := range stats {
+		if s.Name != "" && s.Name == c.Names {
+			return &stats[i]
+		}
+		if s.ID != "" && c.ID != "" && (strings.HasPrefix(s.ID, c.ID) || strings.HasPrefix(c.ID, s.ID)) {
+			return &stats[i]
+		}
+	}
+	return nil
+}
+
+// publishedPorts extracts "host->container/proto" mappings from a docker ps
+// ports column, dropping the host IP so IPv4 and IPv6 bindings collapse.
+func publishedPorts(ports string) []string {
+	var out []string
+	for _, p := range strings.Split(ports, ",") {
+		p = strings.TrimSpace(p)
+		host, target, ok := strings.Cut(p, "->")
+		if !ok {
+			continue
+		}
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[i+1:]
+		}
+		out = append(out, host+"->"+target)
+	}
+	return out
+}
+
+func handleProjectStatus(ctx context.Context, exec docker.Executor, clk clock, args projectStatusArgs) (string, projectStatusOutput, error) {
+	minutes := args.Minutes
+	if minutes <= 0 {
+		minutes = defaultStatusMinutes
+	}
+	if minutes > maxStatusMinutes {
+		minutes = maxStatusMinutes
+	}
+	out := projectStatusOutput{Project: args.Project, LogMinutes: minutes}
+	if args.Project == "" {
+		return "", out, fmt.Errorf("project is required")
+	}
+
+	containers, err := psContainers(ctx, exec, true, "com.docker.compose.project="+args.Project)
+	if err != nil {
+		return "", out, fmt.Errorf("failed to list containers: %w", err)
+	}
+	if len(containers) == 0 {
+		return "", out, fmt.Errorf("no containers found for project %s", args.Project)
+	}
+
+	// One docker stats call covers every running container; it runs while
+	// the pool inspects containers and reads their logs.
+	var (
+		stats    []docker.Stats
+		statsErr error
+		wg       sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, c := range containers {
+			if c.State == "running" {
+				stats, _, statsErr = readStats(ctx, exec, "")
+				return
+			}
+		}
+	}()
+	replicas := gatherReplicas(ctx, exec, containers, minutes)
+	wg.Wait()
+
+	now := clk.Now()
+	out.GeneratedAt = now
+	if statsErr != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("stats: %v", statsErr))
+	}
+
+	byService := make(map[string]*serviceStatus)
+	for i, c := range containers {
+		name := c.Labels["com.docker.compose.service"]
+		if name == "" {
+			name = c.Names
+		}
+		svc, ok := byService[name]
+		if !ok {
+			svc = &serviceStatus{Service: name}
+			byService[name] = svc
+		}
+		out.Warnings = append(out.Warnings, replicas[i].warnings...)
+		svc.Containers = append(svc.Containers, newReplicaStatus(c, replicas[i], findStats(stats, c), now))
+		for _, p := range publishedPorts(c.Ports) {
+			if !slices.Contains(svc.Ports, p) {
+				svc.Ports = append(svc.Ports, p)
+			}
+		}
+	}
+
+	for _, svc := range byService {
+		summarizeService(svc, time.Duration(minutes)*time.Minute)
+		out.Services = append(out.Services, *svc)
+	}
+	sort.Slice(out.Services, func(i, j int) bool { return out.Services[i].Service < out.Services[j].Service })
+	for _, svc := range out.Services {
+		if svc.Degraded {
+			out.Degraded = append(out.Degraded, svc.Service)
+		}
+	}
+	return formatProjectStatus(out), out, nil
+}
+
+func newReplicaStatus(c containerInfo, data replicaData, stats *docker.Stats, now time.Time) replicaStatus {
+	r := replicaStatus{Container: c.Names, ID: c.ID, State: c.State, ErrorLines: data.errorLines}
+	if d := data.details; d != nil {
+		r.State = d.State.Status
+		r.Restarts = d.RestartCount
+		if !d.State.Running {
+			r.ExitCode = d.State.ExitCode
+		}
+		if d.State.Health != nil {
+			r.Health = d.State.Health.Status
+		}
+		if started, err := time.Parse(time.RFC3339Nano, d.State.StartedAt); err == nil && d.State.Running {
+			r.UptimeSeconds = now.Sub(started).Round(time.Second).Seconds()
+		}
+	}
+	if stats != nil && r.State == "running" {
+		r.CPUPercent = stats.CPUPercent
+		r.MemUsage = stats.MemUsage
+		r.MemPercent = stats.MemPercent
+	}
+	return r
+}
+
+// summarizeService aggregates replicas into the service row and decides
+// whether the service is degraded. A replica that exited with code 0 counts
+// as completed (e.g. a migration job), not as down.
+func summarizeService(svc *serviceStatus, window time.Duration) {
+	svc.Replicas = len(svc.Containers)
+	states := make(map[string]int)
+	health := make(map[string]int)
+	completed, restarting, unhealthy, recentRestarts, memPressure := 0, 0, 0, 0, 0
+	for _, r := range svc.Containers {
+		states[r.State]++
+		if r.Health != "" {
+			health[r.Health]++
+		}
+		svc.Restarts += r.Restarts
+		svc.CPUPercent += r.CPUPercent
+		svc.MemUsage += r.MemUsage
+		svc.ErrorLines += r.ErrorLines
+		switch r.State {
+		case "running":
+			svc.Running++
+			if svc.UptimeSeconds == 0 || r.UptimeSeconds < svc.UptimeSeconds {
+				svc.UptimeSeconds = r.UptimeSeconds
+			}
+			if r.Restarts > 0 && r.UptimeSeconds < window.Seconds() {
+				recentRestarts++
+			}
+		case "exited":
+			if r.ExitCode == 0 {
+				completed++
+			}
+		case "restarting":
+			restarting++
+		}
+		if r.Health == "unhealthy" {
+			unhealthy++
+		}
+		if r.MemPercent >= 90 {
+			memPressure++
+		}
+	}
+	if svc.Ports == nil {
+		svc.Ports = []string{}
+	}
+
+	svc.State = "mixed"
+	if len(states) == 1 {
+		svc.State = svc.Containers[0].State
+	}
+	if len(health) > 0 {
+		keys := make([]string, 0, len(health))
+		for k := range health {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%d/%d %s", health[k], svc.Replicas, k))
+		}
+		svc.Health = strings.Join(parts, ", ")
+	}
+
+	if down := svc.Replicas - svc.Running - completed; down > 0 {
+		svc.Reasons = append(svc.Reasons, fmt.Sprintf("%d of %d replicas not running", down, svc.Replicas))
+	}
+	if restarting > 0 {
+		svc.Reasons = append(svc.Reasons, fmt.Sprintf("%d replicas restarting", restarting))
+	}
+	if unhealthy > 0 {
+		svc.Reasons = append(svc.Reasons, fmt.Sprintf("%d replicas unhealthy", unhealthy))
+	}
+	if recentRestarts > 0 {
+		svc.Reasons = append(svc.Reasons, fmt.Sprintf("%d replicas restarted in the last %s", recentRestarts, window))
+	}
+	if memPressure > 0 {
+		svc.Reasons = append(svc.Reasons, fmt.Sprintf("%d replicas above 90%% of their memory limit", memPressure))
+	}
+	if svc.ErrorLines > 0 {
+		svc.Reasons = append(svc.Reasons, fmt.Sprintf("%d error lines in the last %s", svc.ErrorLines, window))
+	}
+	svc.Degraded = len(svc.Reasons) > 0
+}
+
+func formatProjectStatus(out projectStatusOutput) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("=== Project %s: %d services, %d degraded ===\n\n", out.Project, len(out.Services), len(out.Degraded)))
+	b.WriteString(fmt.Sprintf("   %-20s %-8s %-10s %-16s %-8s %-10s %-8s %-10s %-7s %s\n",
+		"SERVICE", "REPLICAS", "STATE", "HEALTH", "RESTARTS", "UPTIME", "CPU", "MEM", "ERRORS", "PORTS"))
+	for _, svc := range out.Services {
+		mark := "  "
+		if svc.Degraded {
+			mark = "! "
+		}
+		health := svc.Health
+		if health == "" {
+			health = "-"
+		}
+		uptime := "-"
+		if svc.Running > 0 {
+			uptime = formatSeconds(svc.UptimeSeconds)
+		}
+		ports := strings.Join(svc.Ports, ", ")
+		if ports == "" {
+			ports = "-"
+		}
+		b.WriteString(fmt.Sprintf("%s %-20s %-8s %-10s %-16s %-8d %-10s %-8s %-10s %-7d %s\n",
+			mark, svc.Service, fmt.Sprintf("%d/%d", svc.Running, svc.Replicas), svc.State, health, svc.Restarts,
+			uptime, fmt.Sprintf("%.1f%%", svc.CPUPercent), binarySize(svc.MemUsage), svc.ErrorLines, ports))
+	}
+
+	for _, svc := range out.Services {
+		if !svc.Degraded {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\n! %s: %s\n", svc.Service, strings.Join(svc.Reasons, "; ")))
+		for _, r := range svc.Containers {
+			state := r.State
+			if r.Health != "" {
+				state += " (" + r.Health + ")"
+			}
+			if r.State == "exited" {
+				state += fmt.Sprintf(", exit code %d", r.ExitCode)
+			}
+			b.WriteString(fmt.Sprintf("    %-30s %-30s restarts %d, %d error lines\n", r.Container, state, r.Restarts, r.ErrorLines))
+		}
+	}
+	if len(out.Degraded) > 0 {
+		b.WriteString("\nUse diagnose_container on a degraded replica for likely causes.\n")
+	}
+	if len(out.Warnings) > 0 {
+		b.WriteString("\nIncomplete data:\n")
+		for _, w := range out.Warnings {
+			b.WriteString("  " + w + "\n")
+		}
+	}
+	return b.String()
+}
+
+func registerProjectStatus(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "project_status",
+		Description: "Status dashboard for a Compose project: one row per service with replicas, state, health, restart count, uptime, published ports, CPU/memory and the number of error log lines in the last N minutes. Degraded services are highlighted with the reasons and their replicas.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args projectStatusArgs) (*mcp.CallToolResult, projectStatusOutput, error) {
+		result, out, err := handleProjectStatus(ctx, exec, realClock{}, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const statusPs = `{"ID":"web1","Names":"shop-web-1","State":"running","Ports":"0.0.0.0:8080->80/tcp, :::8080->80/tcp","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}
+{"ID":"web2","Names":"shop-web-2","State":"running","Ports":"0.0.0.0:8081->80/tcp","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}
+{"ID":"db1","Names":"shop-db-1","State":"running","Labels":"com.docker.compose.project=shop,com.docker.compose.service=db"}
+{"ID":"mig1","Names":"shop-migrate-1","State":"exited","Labels":"com.docker.compose.project=shop,com.docker.compose.service=migrate"}
+{"ID":"wrk1","Names":"shop-worker-1","State":"exited","Labels":"com.docker.compose.project=shop,com.docker.compose.service=worker"}`
+
+// statusInspect renders inspect output; startedAt is relative to the fake
+// clock's start time.
+func statusInspect(id, status string, running bool, exitCode, restarts int, startedAgo string, health string) string {
+	h := ""
+	if health != "" {
+		h = fmt.Sprintf(`,"Health":{"Status":%q,"FailingStreak":0}`, health)
+	}
+	return fmt.Sprintf(`[{"Id":%q,"RestartCount":%d,"State":{"Status":%q,"Running":%t,"ExitCode":%d,"StartedAt":%q%s}}]`,
+		id, restarts, status, running, exitCode, startedAgo, h)
+}
+
+func TestHandleProjectStatus(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop", statusPs, nil)
+	mock.On("stats --no-stream --format {{json .}}", strings.Join([]string{
+		`{"Name":"shop-web-1","ID":"web1","CPUPerc":"10.00%","MemUsage":"100MiB / 1GiB","MemPerc":"9.77%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"3"}`,
+		`{"Name":"shop-web-2","ID":"web2","CPUPerc":"5.00%","MemUsage":"50MiB / 1GiB","MemPerc":"4.88%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"3"}`,
+		`{"Name":"shop-db-1","ID":"db1","CPUPerc":"2.50%","MemUsage":"200MiB / 1GiB","MemPerc":"19.53%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"3"}`,
+		`{"Name":"other","ID":"zzz","CPUPerc":"99.00%","MemUsage":"1GiB / 1GiB","MemPerc":"100.00%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"3"}`,
+	}, "\n"), nil)
+	mock.On("inspect web1", statusInspect("web1", "running", true, 0, 0, "2023-12-31T22:00:00Z", "healthy"), nil)
+	mock.On("inspect web2", statusInspect("web2", "running", true, 0, 3, "2023-12-31T23:55:00Z", "unhealthy"), nil)
+	mock.On("inspect db1", statusInspect("db1", "running", true, 0, 0, "2023-12-31T20:00:00Z", ""), nil)
+	mock.On("inspect mig1", statusInspect("mig1", "exited", false, 0, 0, "2023-12-31T20:00:00Z", ""), nil)
+	mock.On("inspect wrk1", statusInspect("wrk1", "exited", false, 1, 0, "2023-12-31T20:00:00Z", ""), nil)
+	mock.On("logs --tail 5000 --since 30m web1", "GET / 200\n", nil)
+	mock.On("logs --tail 5000 --since 30m web2", "GET / 200\nERROR upstream timed out\nlevel=error msg=\"db down\"\n", nil)
+	mock.On("logs --tail 5000 --since 30m db1", "checkpoint complete\n", nil)
+	mock.On("logs --tail 5000 --since 30m mig1", "", nil)
+	mock.On("logs --tail 5000 --since 30m wrk1", "", fmt.Errorf("log driver unavailable"))
+
+	result, out, err := handleProjectStatus(context.Background(), mock, newFakeClock(), projectStatusArgs{Project: "shop", Minutes: 30})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(out.Services) != 4 {
+		t.Fatalf("expected 4 services, got %+v", out.Services)
+	}
+	byName := make(map[string]serviceStatus)
+	for _, s := range out.Services {
+		byName[s.Service] = s
+	}
+
+	web := byName["web"]
+	if web.Replicas != 2 || web.Running != 2 || web.State != "running" || web.Restarts != 3 {
+		t.Errorf("unexpected web summary %+v", web)
+	}
+	if web.Health != "1/2 healthy, 1/2 unhealthy" || web.UptimeSeconds != 300 || web.ErrorLines != 2 {
+		t.Errorf("unexpected web health/uptime/errors %+v", web)
+	}
+	if web.CPUPercent != 15 || web.MemUsage != 150*1024*1024 {
+		t.Errorf("unexpected web usage %v %v", web.CPUPercent, web.MemUsage)
+	}
+	if strings.Join(web.Ports, ",") != "8080->80/tcp,8081->80/tcp" {
+		t.Errorf("unexpected web ports %v", web.Ports)
+	}
+	if !web.Degraded || len(web.Reasons) != 3 {
+		t.Errorf("expected web degraded by unhealthy, recent restart and errors, got %v", web.Reasons)
+	}
+
+	if db := byName["db"]; db.Degraded || db.Health != "" {
+		t.Errorf("expected db to be fine, got %+v", db)
+	}
+	if mig := byName["migrate"]; mig.Degraded || mig.State != "exited" {
+		t.Errorf("expected a completed one-shot service not to be degraded, got %+v", mig)
+	}
+	if wrk := byName["worker"]; !wrk.Degraded || wrk.Reasons[0] != "1 of 1 replicas not running" {
+		t.Errorf("expected worker degraded, got %+v", wrk)
+	}
+	if strings.Join(out.Degraded, ",") != "web,worker" {
+		t.Errorf("unexpected degraded list %v", out.Degraded)
+	}
+	if len(out.Warnings) != 1 || !strings.Contains(out.Warnings[0], "log driver unavailable") {
+		t.Errorf("unexpected warnings %v", out.Warnings)
+	}
+
+	for _, want := range []string{"Project shop: 4 services, 2 degraded", "! web", "2/2", "8080->80/tcp, 8081->80/tcp", "! worker: 1 of 1 replicas not running", "exited, exit code 1"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleProjectStatus_StoppedProjectSkipsStats(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop",
+		`{"ID":"mig1","Names":"shop-migrate-1","State":"exited","Labels":"com.docker.compose.project=shop,com.docker.compose.service=migrate"}`, nil)
+	mock.On("inspect mig1", statusInspect("mig1", "exited", false, 0, 0, "2023-12-31T20:00:00Z", ""), nil)
+	mock.On("logs --tail 5000 --since 15m mig1", "", nil)
+
+	_, out, err := handleProjectStatus(context.Background(), mock, newFakeClock(), projectStatusArgs{Project: "shop"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Degraded) != 0 || len(out.Warnings) != 0 {
+		t.Errorf("unexpected report %+v", out)
+	}
+	for _, call := range mock.Calls() {
+		if call[0] == "stats" {
+			t.Error("expected no stats call when nothing is running")
+		}
+	}
+}
+
+func TestHandleProjectStatus_Errors(t *testing.T) {
+	if _, _, err := handleProjectStatus(context.Background(), docker.NewMock(), newFakeClock(), projectStatusArgs{}); err == nil {
+		t.Error("expected error for missing project")
+	}
+
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=none", "", nil)
+	if _, _, err := handleProjectStatus(context.Background(), mock, newFakeClock(), projectStatusArgs{Project: "none"}); err == nil || !strings.Contains(err.Error(), "no containers") {
+		t.Errorf("expected no containers error, got %v", err)
+	}
+}
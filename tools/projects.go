@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// ComposeProject records where a Compose project's files live.
+type ComposeProject struct {
+	WorkDir     string   `json:"work_dir"`
+	ConfigFiles []string `json:"config_files,omitempty"`
+}
+
+// ProjectRegistry remembers the location of Compose projects seen earlier,
+// so that compose tools keep working after compose down has removed every
+// container that carried the location labels. A registry opened from a file
+// persists across restarts; the zero value is not usable, use
+// NewProjectRegistry or OpenProjectRegistry.
+type ProjectRegistry struct {
+	mu       sync.Mutex
+	path     string
+	projects map[string]ComposeProject
+}
+
+// NewProjectRegistry returns a registry that is kept in memory only.
+func NewProjectRegistry() *ProjectRegistry {
+	return &ProjectRegistry{projects: make(map[string]ComposeProject)}
+}
+
+// OpenProjectRegistry loads the registry stored at path. A missing file
+// yields an empty registry that is created on the first Remember.
+func OpenProjectRegistry(path string) (*ProjectRegistry, error) {
+	r := NewProjectRegistry()
+	r.path = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project registry: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.projects); err != nil {
+		return nil, fmt.Errorf("failed to parse project registry %s: %w", path, err)
+	}
+	if r.projects == nil {
+		r.projects = make(map[string]ComposeProject)
+	}
+	return r, nil
+}
+
+// Lookup returns the recorded location of project.
+func (r *ProjectRegistry) Lookup(project string) (ComposeProject, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.projects[project]
+	return p, ok
+}
+
+// Remember records the location of project, persisting the registry if it
+// was opened from a file. Unchanged entries are not rewritten.
+func (r *ProjectRegistry) Remember(project string, p ComposeProject) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.projects[project]; ok && old.WorkDir == p.WorkDir && slices.Equal(old.ConfigFiles, p.ConfigFiles) {
+		return nil
+	}
+	r.projects[project] = p
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.projects, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save project registry: %w", err)
+	}
+	// Write to a temporary file first so a crash never leaves a truncated
+	// registry behind.
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save project registry: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to save project registry: %w", err)
+	}
+	return nil
+}
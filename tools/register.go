@@ -1,22 +1,135 @@
 package tools
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/otsukatsuka/orbstack-mcp/docker"
 )
 
+// Options configures which tools RegisterAll exposes.
+type Options struct {
+	// ReadOnly registers only tools that observe containers, never ones
+	// that change them.
+	ReadOnly bool
+	// Projects remembers where Compose projects live. When nil, an
+	// in-memory registry is used.
+	Projects *ProjectRegistry
+	// Stats is the background stats recorder queried by stats_history.
+	// When nil, stats_history reports that recording is disabled.
+	Stats *StatsRecorder
+	// Alerts evaluates the rules managed by the alert tools. When nil,
+	// those tools report that alerts are disabled.
+	Alerts *AlertScheduler
+	// CopyDir is the only host directory container_cp_to copies from and
+	// container_cp_from saves to. When empty, copies only return content
+	// inline.
+	CopyDir string
+	// BackupDir is where volume_backup writes archives and volume_restore
+	// reads them. When empty, the backup tools report that backups are
+	// disabled.
+	BackupDir string
+}
+
+// readOnlyTools is the allow-list of tools that are safe to expose in
+// read-only mode. Anything not listed here is refused in that mode, even if
+// it ends up registered.
+var readOnlyTools = map[string]bool{
+	"list_containers":     true,
+	"get_logs":            true,
+	"follow_logs":         true,
+	"search_logs":         true,
+	"compose_logs":        true,
+	"container_stats":     true,
+	"container_inspect":   true,
+	"container_health":    true,
+	"container_cp_from":   true,
+	"container_ls":        true,
+	"container_find":      true,
+	"container_read_file": true,
+	"list_images":         true,
+	"image_inspect":       true,
+	"image_history":       true,
+	"image_bloat_report":  true,
+	"list_volumes":        true,
+	"volume_inspect":      true,
+	"list_volume_backups": true,
+	"log_diff":            true,
+	"container_events":    true,
+	"watch_events":        true,
+	"detect_crash_loops":  true,
+	"diagnose_container":  true,
+	"explain_exit":        true,
+	"project_status":      true,
+	"wait_healthy":        true,
+	"compose_config":      true,
+	"stats_sample":        true,
+	"stats_history":       true,
+	"alert_add":           true,
+	"alert_list":          true,
+	"alert_remove":        true,
+}
+
 // RegisterAll registers all OrbStack MCP tools on the server.
-func RegisterAll(server *mcp.Server, exec docker.Executor) {
+func RegisterAll(server *mcp.Server, exec docker.Executor, opts Options) {
+	projects := opts.Projects
+	if projects == nil {
+		projects = NewProjectRegistry()
+	}
+
 	registerListContainers(server, exec)
 	registerGetLogs(server, exec)
+	registerFollowLogs(server, exec)
 	registerSearchLogs(server, exec)
 	registerComposeLogs(server, exec)
-	registerContainerExec(server, exec)
-	registerRestartService(server, exec)
 	registerContainerStats(server, exec)
+	registerStatsSample(server, exec)
+	registerStatsHistory(server, opts.Stats)
+	registerAlertRules(server, opts.Alerts)
 	registerContainerInspect(server, exec)
 	registerContainerHealth(server, exec)
+	registerContainerCpFrom(server, exec, opts.CopyDir, opts.ReadOnly)
+	registerContainerFiles(server, exec)
+	registerImageTools(server, exec)
+	registerImageBloatReport(server, exec)
+	registerVolumeTools(server, exec)
+	registerListVolumeBackups(server, opts.BackupDir)
 	registerLogDiff(server, exec)
-	registerComposeUpDown(server, exec)
 	registerContainerEvents(server, exec)
+	registerWatchEvents(server, exec)
+	registerDetectCrashLoops(server, exec)
+	registerExplainExit(server, exec)
+	registerDiagnoseContainer(server, exec)
+	registerProjectStatus(server, exec)
+	registerWaitHealthy(server, exec)
+	registerComposeConfig(server, exec, projects)
+
+	if opts.ReadOnly {
+		server.AddReceivingMiddleware(readOnlyGuard)
+		return
+	}
+
+	registerContainerExec(server, exec)
+	registerContainerCpTo(server, exec, opts.CopyDir)
+	registerImageRemoval(server, exec)
+	registerImageBuild(server, exec, projects)
+	registerVolumeRemoval(server, exec)
+	registerVolumeBackup(server, exec, opts.BackupDir)
+	registerRestartService(server, exec)
+	registerContainerLifecycle(server, exec)
+	registerComposeUpDown(server, exec, projects)
+}
+
+// readOnlyGuard refuses calls to any tool outside readOnlyTools.
+func readOnlyGuard(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if call, ok := req.(*mcp.CallToolRequest); ok && !readOnlyTools[call.Params.Name] {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("tool %q is not available in read-only mode", call.Params.Name)}},
+				IsError: true,
+			}, nil
+		}
+		return next(ctx, method, req)
+	}
 }
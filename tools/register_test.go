@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// connect returns a client session connected to server over an in-memory
+// transport.
+func connect(t *testing.T, server *mcp.Server) *mcp.ClientSession {
+	t.Helper()
+	return connectClient(t, server, mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil))
+}
+
+// connectClient connects the given client to server over an in-memory
+// transport.
+func connectClient(t *testing.T, server *mcp.Server, client *mcp.Client) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func newTestServer() *mcp.Server {
+	return mcp.NewServer(&mcp.Implementation{Name: "orbstack-mcp", Version: "test"}, nil)
+}
+
+func toolNames(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools failed: %v", err)
+	}
+	var names []string
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestRegisterAll_ToolSets(t *testing.T) {
+	readOnly := []string{
+		"alert_add",
+		"alert_list",
+		"alert_remove",
+		"compose_config",
+		"compose_logs",
+		"container_cp_from",
+		"container_events",
+		"container_find",
+		"container_health",
+		"container_inspect",
+		"container_ls",
+		"container_read_file",
+		"container_stats",
+		"detect_crash_loops",
+		"diagnose_container",
+		"explain_exit",
+		"follow_logs",
+		"get_logs",
+		"image_bloat_report",
+		"image_history",
+		"image_inspect",
+		"list_containers",
+		"list_images",
+		"list_volume_backups",
+		"list_volumes",
+		"log_diff",
+		"project_status",
+		"search_logs",
+		"stats_history",
+		"stats_sample",
+		"volume_inspect",
+		"wait_healthy",
+		"watch_events",
+	}
+	mutating := []string{
+		"compose_build",
+		"compose_down",
+		"compose_up",
+		"container_cp_to",
+		"container_exec",
+		"container_kill",
+		"container_pause",
+		"container_remove",
+		"container_start",
+		"container_stop",
+		"container_unpause",
+		"image_build",
+		"image_prune",
+		"image_remove",
+		"restart_service",
+		"volume_backup",
+		"volume_backup_prune",
+		"volume_prune",
+		"volume_remove",
+		"volume_restore",
+	}
+	all := slices.Sorted(slices.Values(append(slices.Clone(readOnly), mutating...)))
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"default", Options{}, all},
+		{"read-only", Options{ReadOnly: true}, readOnly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer()
+			RegisterAll(server, docker.NewMock(), tt.opts)
+			got := toolNames(t, connect(t, server))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected tools\n  %v\ngot\n  %v", tt.want, got)
+			}
+		})
+	}
+
+	// Every read-only tool must be on the allow-list enforced by the guard.
+	for _, name := range readOnly {
+		if !readOnlyTools[name] {
+			t.Errorf("read-only tool %q is missing from readOnlyTools", name)
+		}
+	}
+}
+
+func TestRegisterAll_ReadOnlyRefusesMutatingTools(t *testing.T) {
+	mock := docker.NewMock()
+	server := newTestServer()
+	RegisterAll(server, mock, Options{ReadOnly: true})
+	// Simulate a mutating tool being registered by mistake.
+	registerContainerExec(server, mock)
+	session := connect(t, server)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "container_exec",
+		Arguments: map[string]any{"container": "web", "command": "rm -rf /data"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected protocol error: %v", err)
+	}
+	if !res.IsError {
+		t.Fatal("expected container_exec to be refused in read-only mode")
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "read-only") {
+		t.Errorf("expected read-only refusal message, got %q", text)
+	}
+	if len(mock.Calls()) != 0 {
+		t.Errorf("expected no docker calls, got %v", mock.Calls())
+	}
+}
+
+func TestRegisterAll_StructuredOutput(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}}", `{"ID":"abc123","Names":"webapp-web-1","Image":"nginx","State":"running","Status":"Up","Labels":"com.docker.compose.project=webapp,com.docker.compose.service=web"}`, nil)
+	mock.On("stats --no-stream --format {{json .}}", `{"Container":"abc123","Name":"webapp-web-1","ID":"abc123","CPUPerc":"1.50%","MemUsage":"64MiB / 1GiB","MemPerc":"6.25%","NetIO":"1kB / 2kB","BlockIO":"0B / 0B","PIDs":"3"}`, nil)
+	server := newTestServer()
+	RegisterAll(server, mock, Options{})
+	session := connect(t, server)
+
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools failed: %v", err)
+	}
+	for _, tool := range res.Tools {
+		if tool.OutputSchema == nil {
+			t.Errorf("tool %q declares no output schema", tool.Name)
+		}
+	}
+
+	call := func(name string, args map[string]any, out any) {
+		t.Helper()
+		res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s: unexpected protocol error: %v", name, err)
+		}
+		if res.IsError {
+			t.Fatalf("%s: unexpected tool error: %v", name, res.Content)
+		}
+		if len(res.Content) == 0 {
+			t.Errorf("%s: expected text content alongside structured content", name)
+		}
+		data, err := json.Marshal(res.StructuredContent)
+		if err != nil {
+			t.Fatalf("%s: marshal structured content: %v", name, err)
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s: decode structured content %s: %v", name, data, err)
+		}
+	}
+
+	var list listContainersOutput
+	call("list_containers", map[string]any{}, &list)
+	if len(list.Projects) != 1 || list.Projects[0].Project != "webapp" || list.Projects[0].Containers[0].Service != "web" {
+		t.Errorf("unexpected list_containers structured content: %+v", list)
+	}
+
+	var stats containerStatsOutput
+	call("container_stats", map[string]any{}, &stats)
+	if len(stats.Containers) != 1 || stats.Containers[0].MemUsage != 64<<20 || stats.Containers[0].CPUPercent != 1.5 {
+		t.Errorf("unexpected container_stats structured content: %+v", stats)
+	}
+}
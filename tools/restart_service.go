@@ -3,46 +3,169 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/otsukatsuka/orbstack-mcp/docker"
 )
 
 type restartServiceArgs struct {
-	Container string `json:"container" jsonschema:"container name or ID to restart"`
-	Timeout   int    `json:"timeout,omitempty" jsonschema:"seconds to wait before killing the container (default: 10)"`
+	Container     string `json:"container,omitempty" jsonschema:"container name or ID to restart"`
+	Project       string `json:"project,omitempty" jsonschema:"Compose project name (instead of container)"`
+	Service       string `json:"service,omitempty" jsonschema:"Compose service whose replicas to restart (default: all services of the project)"`
+	Timeout       int    `json:"timeout,omitempty" jsonschema:"seconds to wait before killing the container (default: 10)"`
+	Strategy      string `json:"strategy,omitempty" jsonschema:"rolling (one replica at a time) or parallel (default: rolling)"`
+	WaitHealthy   bool   `json:"wait_healthy,omitempty" jsonschema:"wait for each replica to become healthy (or running, without a healthcheck) before moving on"`
+	HealthTimeout int    `json:"health_timeout,omitempty" jsonschema:"seconds to wait for each replica to become healthy (default: 60)"`
 }
 
-func handleRestartService(ctx context.Context, exec docker.Executor, args restartServiceArgs) (string, error) {
+type restartServiceOutput struct {
+	Strategy string          `json:"strategy"`
+	Replicas []restartResult `json:"replicas" jsonschema:"outcome for each replica, in restart order"`
+}
+
+type restartResult struct {
+	Container      string  `json:"container"`
+	ID             string  `json:"id,omitempty"`
+	Service        string  `json:"service,omitempty"`
+	Status         string  `json:"status" jsonschema:"restarted, unhealthy, failed or skipped"`
+	Message        string  `json:"message,omitempty"`
+	RestartSeconds float64 `json:"restart_seconds" jsonschema:"time docker restart took"`
+	ReadySeconds   float64 `json:"ready_seconds,omitempty" jsonschema:"time until the replica was healthy, when wait_healthy is set"`
+	State          string  `json:"state,omitempty" jsonschema:"last observed state, when wait_healthy is set"`
+}
+
+// restartReplica restarts one container and, if requested, waits for it to
+// become ready again.
+func restartReplica(ctx context.Context, exec docker.Executor, c containerInfo, args restartServiceArgs, timeout int, healthTimeout time.Duration) restartResult {
+	r := restartResult{Container: c.Names, Service: c.Labels["com.docker.compose.service"]}
+	if c.ID != c.Names {
+		r.ID = c.ID
+	}
+
+	start := time.Now()
+	_, err := exec.Exec(ctx, "restart", "--time", fmt.Sprintf("%d", timeout), c.ID)
+	r.RestartSeconds = time.Since(start).Seconds()
+	if err != nil {
+		r.Status = "failed"
+		r.Message = err.Error()
+		return r
+	}
+	r.Status = "restarted"
+	if !args.WaitHealthy {
+		return r
+	}
+
+	start = time.Now()
+	state, err := waitReady(ctx, exec, c.ID, healthTimeout)
+	r.ReadySeconds = time.Since(start).Seconds()
+	if state != nil {
+		r.State = state.describe()
+	}
+	if err != nil {
+		r.Status = "unhealthy"
+		r.Message = err.Error()
+	}
+	return r
+}
+
+func handleRestartService(ctx context.Context, exec docker.Executor, args restartServiceArgs) (string, restartServiceOutput, error) {
 	timeout := args.Timeout
 	if timeout <= 0 {
 		timeout = 10
 	}
+	healthTimeout := time.Duration(args.HealthTimeout) * time.Second
+	if healthTimeout <= 0 {
+		healthTimeout = 60 * time.Second
+	}
+	strategy := args.Strategy
+	if strategy == "" {
+		strategy = "rolling"
+	}
 
-	dockerArgs := []string{"restart", "--time", fmt.Sprintf("%d", timeout), args.Container}
+	out := restartServiceOutput{Strategy: strategy}
+	if strategy != "rolling" && strategy != "parallel" {
+		return "", out, fmt.Errorf("unknown strategy %q: must be rolling or parallel", strategy)
+	}
 
-	_, err := exec.Exec(ctx, dockerArgs...)
+	sel := targetSelector{args.Container, args.Project, args.Service}
+	replicas, err := resolveTargets(ctx, exec, sel)
 	if err != nil {
-		return "", fmt.Errorf("restart failed: %w", err)
+		return "", out, err
 	}
 
-	return fmt.Sprintf("Successfully restarted container %s", args.Container), nil
+	out.Replicas = make([]restartResult, len(replicas))
+	if strategy == "parallel" {
+		var wg sync.WaitGroup
+		for i, c := range replicas {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				out.Replicas[i] = restartReplica(ctx, exec, c, args, timeout, healthTimeout)
+			}()
+		}
+		wg.Wait()
+	} else {
+		// A rolling restart stops at the first replica that does not come
+		// back, so the remaining ones keep serving.
+		halted := false
+		for i, c := range replicas {
+			if halted {
+				out.Replicas[i] = restartResult{Container: c.Names, Service: c.Labels["com.docker.compose.service"], Status: "skipped", Message: "rolling restart halted after an earlier replica failed"}
+				if c.ID != c.Names {
+					out.Replicas[i].ID = c.ID
+				}
+				continue
+			}
+			out.Replicas[i] = restartReplica(ctx, exec, c, args, timeout, healthTimeout)
+			halted = out.Replicas[i].Status != "restarted"
+		}
+	}
+
+	var failures []string
+	var b strings.Builder
+	for _, r := range out.Replicas {
+		switch r.Status {
+		case "restarted":
+			b.WriteString(fmt.Sprintf("Successfully restarted container %s in %.1fs", r.Container, r.RestartSeconds))
+			if args.WaitHealthy {
+				b.WriteString(fmt.Sprintf(", %s after %.1fs", r.State, r.ReadySeconds))
+			}
+			b.WriteString("\n")
+		case "unhealthy":
+			b.WriteString(fmt.Sprintf("Restarted container %s in %.1fs but it did not become ready: %s\n", r.Container, r.RestartSeconds, r.Message))
+		case "skipped":
+			b.WriteString(fmt.Sprintf("Skipped container %s: %s\n", r.Container, r.Message))
+		default:
+			b.WriteString(fmt.Sprintf("Failed to restart container %s: %s\n", r.Container, r.Message))
+			failures = append(failures, r.Message)
+		}
+	}
+
+	// Only report an error when nothing could be restarted at all; partial
+	// failures are part of the per-replica report.
+	if len(failures) == len(replicas) {
+		return "", out, fmt.Errorf("restart failed: %s", strings.Join(failures, "; "))
+	}
+	if len(replicas) > 1 {
+		return fmt.Sprintf("%s restart of %d replicas (%s):\n%s", capitalize(strategy), len(replicas), sel, b.String()), out, nil
+	}
+	return b.String(), out, nil
 }
 
 func registerRestartService(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "restart_service",
-		Description: "Restart a container or all containers in a Compose service.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args restartServiceArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleRestartService(ctx, exec, args)
+		Description: "Restart a container or all replicas of a Compose service (project + service), either rolling one at a time or in parallel, optionally waiting for each replica to become healthy. Reports per-replica outcome and timing.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args restartServiceArgs) (*mcp.CallToolResult, restartServiceOutput, error) {
+		result, out, err := handleRestartService(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
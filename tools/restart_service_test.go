@@ -3,8 +3,10 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/otsukatsuka/orbstack-mcp/docker"
 )
@@ -14,7 +16,7 @@ func TestHandleRestartService_BasicRestart(t *testing.T) {
 
 	mock.On("restart --time 10 mycontainer", "mycontainer\n", nil)
 
-	result, err := handleRestartService(context.Background(), mock, restartServiceArgs{
+	result, _, err := handleRestartService(context.Background(), mock, restartServiceArgs{
 		Container: "mycontainer",
 	})
 
@@ -32,7 +34,7 @@ func TestHandleRestartService_WithCustomTimeout(t *testing.T) {
 
 	mock.On("restart --time 30 mycontainer", "mycontainer\n", nil)
 
-	result, err := handleRestartService(context.Background(), mock, restartServiceArgs{
+	result, _, err := handleRestartService(context.Background(), mock, restartServiceArgs{
 		Container: "mycontainer",
 		Timeout:   30,
 	})
@@ -51,7 +53,7 @@ func TestHandleRestartService_ContainerNotFound(t *testing.T) {
 
 	mock.On("restart --time 10 nosuchcontainer", "", fmt.Errorf("Error: No such container: nosuchcontainer"))
 
-	_, err := handleRestartService(context.Background(), mock, restartServiceArgs{
+	_, _, err := handleRestartService(context.Background(), mock, restartServiceArgs{
 		Container: "nosuchcontainer",
 	})
 
@@ -62,3 +64,109 @@ func TestHandleRestartService_ContainerNotFound(t *testing.T) {
 		t.Errorf("expected 'restart failed' error, got: %v", err)
 	}
 }
+
+const restartPs = `{"ID":"aaa111","Names":"shop-web-1","State":"running","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}
+{"ID":"bbb222","Names":"shop-web-2","State":"running","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}`
+
+func fastHealthPolling(t *testing.T) {
+	t.Helper()
+	prev := healthPollInterval
+	healthPollInterval = time.Millisecond
+	t.Cleanup(func() { healthPollInterval = prev })
+}
+
+func TestHandleRestartService_RollingWaitsForHealthy(t *testing.T) {
+	fastHealthPolling(t)
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop --filter label=com.docker.compose.service=web", restartPs, nil)
+	mock.On("restart --time 10 aaa111", "aaa111\n", nil)
+	mock.On("restart --time 10 bbb222", "bbb222\n", nil)
+	mock.OnSequence("inspect --format {{json .State}} aaa111",
+		`{"Status":"running","Health":{"Status":"starting"}}`,
+		`{"Status":"running","Health":{"Status":"healthy"}}`)
+	mock.On("inspect --format {{json .State}} bbb222", `{"Status":"running","Health":{"Status":"healthy"}}`, nil)
+
+	result, out, err := handleRestartService(context.Background(), mock, restartServiceArgs{
+		Project: "shop", Service: "web", WaitHealthy: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Strategy != "rolling" || len(out.Replicas) != 2 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	for _, r := range out.Replicas {
+		if r.Status != "restarted" || r.State != "running (healthy)" || r.Service != "web" {
+			t.Errorf("unexpected replica result: %+v", r)
+		}
+	}
+	if !strings.Contains(result, "Rolling restart of 2 replicas") {
+		t.Errorf("expected rolling summary, got:\n%s", result)
+	}
+
+	// The second replica must only be restarted after the first is healthy.
+	var order []string
+	for _, call := range mock.Calls() {
+		order = append(order, strings.Join(call, " "))
+	}
+	firstHealthy := slices.Index(order, "inspect --format {{json .State}} aaa111")
+	secondRestart := slices.Index(order, "restart --time 10 bbb222")
+	if firstHealthy < 0 || secondRestart < firstHealthy {
+		t.Errorf("expected rolling order, got calls %v", order)
+	}
+}
+
+func TestHandleRestartService_RollingHaltsOnUnhealthy(t *testing.T) {
+	fastHealthPolling(t)
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop --filter label=com.docker.compose.service=web", restartPs, nil)
+	mock.On("restart --time 10 aaa111", "aaa111\n", nil)
+	mock.On("inspect --format {{json .State}} aaa111", `{"Status":"running","Health":{"Status":"unhealthy"}}`, nil)
+
+	_, out, err := handleRestartService(context.Background(), mock, restartServiceArgs{
+		Project: "shop", Service: "web", WaitHealthy: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Replicas[0].Status != "unhealthy" || out.Replicas[1].Status != "skipped" {
+		t.Errorf("expected rolling restart to halt, got %+v", out.Replicas)
+	}
+	for _, call := range mock.Calls() {
+		if strings.Join(call, " ") == "restart --time 10 bbb222" {
+			t.Error("should not restart the second replica after the first became unhealthy")
+		}
+	}
+}
+
+func TestHandleRestartService_ParallelReportsEachReplica(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop", restartPs, nil)
+	mock.On("restart --time 5 aaa111", "aaa111\n", nil)
+	mock.On("restart --time 5 bbb222", "", fmt.Errorf("Error response from daemon: cannot restart"))
+
+	result, out, err := handleRestartService(context.Background(), mock, restartServiceArgs{
+		Project: "shop", Strategy: "parallel", Timeout: 5,
+	})
+	if err != nil {
+		t.Fatalf("partial failures should be reported, not returned: %v", err)
+	}
+
+	if out.Replicas[0].Status != "restarted" || out.Replicas[1].Status != "failed" {
+		t.Errorf("unexpected replica results: %+v", out.Replicas)
+	}
+	if !strings.Contains(result, "Failed to restart container shop-web-2") {
+		t.Errorf("expected failure line, got:\n%s", result)
+	}
+}
+
+func TestHandleRestartService_InvalidStrategy(t *testing.T) {
+	_, _, err := handleRestartService(context.Background(), docker.NewMock(), restartServiceArgs{
+		Container: "web", Strategy: "random",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown strategy") {
+		t.Errorf("expected unknown strategy error, got %v", err)
+	}
+}
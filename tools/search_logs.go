@@ -20,17 +20,30 @@ type searchLogsArgs struct {
 	ContextLines int    `json:"context_lines,omitempty" jsonschema:"number of lines of context around each match (like grep -C) (default: 0)"`
 }
 
-func handleSearchLogs(ctx context.Context, exec docker.Executor, args searchLogsArgs) (string, error) {
+type searchLogsOutput struct {
+	Container     string     `json:"container"`
+	Pattern       string     `json:"pattern"`
+	LinesSearched int        `json:"lines_searched"`
+	Matches       []logMatch `json:"matches"`
+}
+
+type logMatch struct {
+	Line int    `json:"line" jsonschema:"1-based position of the line within the fetched logs"`
+	Text string `json:"text"`
+}
+
+func handleSearchLogs(ctx context.Context, exec docker.Executor, args searchLogsArgs) (string, searchLogsOutput, error) {
+	out := searchLogsOutput{Container: args.Container, Pattern: args.Pattern}
 	if args.Container == "" {
-		return "", fmt.Errorf("container name or ID is required")
+		return "", out, fmt.Errorf("container name or ID is required")
 	}
 	if args.Pattern == "" {
-		return "", fmt.Errorf("pattern is required")
+		return "", out, fmt.Errorf("pattern is required")
 	}
 
 	re, err := regexp.Compile(args.Pattern)
 	if err != nil {
-		return "", fmt.Errorf("invalid regex pattern %q: %w", args.Pattern, err)
+		return "", out, fmt.Errorf("invalid regex pattern %q: %w", args.Pattern, err)
 	}
 
 	tail := args.Tail
@@ -51,25 +64,27 @@ func handleSearchLogs(ctx context.Context, exec docker.Executor, args searchLogs
 
 	output, err := exec.ExecCombined(ctx, cmdArgs...)
 	if err != nil {
-		return "", fmt.Errorf("failed to get logs for container %q: %w", args.Container, err)
+		return "", out, fmt.Errorf("failed to get logs for container %q: %w", args.Container, err)
 	}
 
 	if output == "" {
-		return "No log output.", nil
+		return "No log output.", out, nil
 	}
 
 	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	out.LinesSearched = len(lines)
 
 	// Find matching line indices
 	var matchIndices []int
 	for i, line := range lines {
 		if re.MatchString(line) {
 			matchIndices = append(matchIndices, i)
+			out.Matches = append(out.Matches, logMatch{Line: i + 1, Text: line})
 		}
 	}
 
 	if len(matchIndices) == 0 {
-		return fmt.Sprintf("No matches found for pattern %q in %d log lines.", args.Pattern, len(lines)), nil
+		return fmt.Sprintf("No matches found for pattern %q in %d log lines.", args.Pattern, len(lines)), out, nil
 	}
 
 	var result string
@@ -84,7 +99,7 @@ func handleSearchLogs(ctx context.Context, exec docker.Executor, args searchLogs
 	}
 
 	header := fmt.Sprintf("Found %d matches for pattern %q:\n\n", len(matchIndices), args.Pattern)
-	return header + result, nil
+	return header + result, out, nil
 }
 
 // formatWithContext formats matched lines with surrounding context lines,
@@ -150,16 +165,13 @@ func registerSearchLogs(server *mcp.Server, exec docker.Executor) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "search_logs",
 		Description: "Search Docker container logs using a regex pattern. Fetches logs then filters matching lines, with optional context lines around matches.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args searchLogsArgs) (*mcp.CallToolResult, any, error) {
-		result, err := handleSearchLogs(ctx, exec, args)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args searchLogsArgs) (*mcp.CallToolResult, searchLogsOutput, error) {
+		result, out, err := handleSearchLogs(ctx, exec, args)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-				IsError: true,
-			}, nil, nil
+			return nil, out, err
 		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, nil, nil
+		}, out, nil
 	})
 }
@@ -15,7 +15,7 @@ func TestHandleSearchLogs_BasicMatch(t *testing.T) {
 	logOutput := "INFO starting server\nERROR connection refused\nINFO request handled\nERROR timeout\nINFO shutting down"
 	mock.On("logs --tail 1000 myapp", logOutput, nil)
 
-	result, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	result, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container: "myapp",
 		Pattern:   "ERROR",
 	})
@@ -43,7 +43,7 @@ func TestHandleSearchLogs_WithContextLines(t *testing.T) {
 	logOutput := "line1\nline2\nERROR something broke\nline4\nline5\nline6\nline7\nERROR another failure\nline9\nline10"
 	mock.On("logs --tail 1000 myapp", logOutput, nil)
 
-	result, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	result, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container:    "myapp",
 		Pattern:      "ERROR",
 		ContextLines: 1,
@@ -90,7 +90,7 @@ func TestHandleSearchLogs_OverlappingContext(t *testing.T) {
 	logOutput := "line1\nERROR first\nline3\nERROR second\nline5"
 	mock.On("logs --tail 1000 myapp", logOutput, nil)
 
-	result, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	result, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container:    "myapp",
 		Pattern:      "ERROR",
 		ContextLines: 2,
@@ -118,7 +118,7 @@ func TestHandleSearchLogs_InvalidRegex(t *testing.T) {
 	// Don't need to set up mock since it should fail at regex compilation
 	_ = mock
 
-	_, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	_, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container: "myapp",
 		Pattern:   "[invalid",
 	})
@@ -137,7 +137,7 @@ func TestHandleSearchLogs_NoMatches(t *testing.T) {
 	logOutput := "INFO all is well\nINFO nothing to see here\nINFO carry on"
 	mock.On("logs --tail 1000 myapp", logOutput, nil)
 
-	result, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	result, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container: "myapp",
 		Pattern:   "ERROR",
 	})
@@ -157,7 +157,7 @@ func TestHandleSearchLogs_DockerError(t *testing.T) {
 	mock := docker.NewMock()
 	mock.On("logs --tail 1000 nonexistent", "", fmt.Errorf("Error: No such container: nonexistent"))
 
-	_, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	_, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container: "nonexistent",
 		Pattern:   "ERROR",
 	})
@@ -176,7 +176,7 @@ func TestHandleSearchLogs_RegexPattern(t *testing.T) {
 	logOutput := "2024-01-01 10:00:00 GET /api/users 200\n2024-01-01 10:00:01 POST /api/users 201\n2024-01-01 10:00:02 GET /api/health 200\n2024-01-01 10:00:03 GET /api/users/123 404"
 	mock.On("logs --tail 1000 myapp", logOutput, nil)
 
-	result, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	result, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container: "myapp",
 		Pattern:   `(4\d{2}|5\d{2})$`,
 	})
@@ -198,7 +198,7 @@ func TestHandleSearchLogs_WithTimestampsAndSince(t *testing.T) {
 	logOutput := "2024-01-01T10:00:00Z ERROR something broke"
 	mock.On("logs --tail 500 --since 1h --timestamps myapp", logOutput, nil)
 
-	result, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	result, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container:  "myapp",
 		Pattern:    "ERROR",
 		Tail:       500,
@@ -215,7 +215,7 @@ func TestHandleSearchLogs_WithTimestampsAndSince(t *testing.T) {
 }
 
 func TestHandleSearchLogs_EmptyContainer(t *testing.T) {
-	_, err := handleSearchLogs(context.Background(), docker.NewMock(), searchLogsArgs{
+	_, _, err := handleSearchLogs(context.Background(), docker.NewMock(), searchLogsArgs{
 		Container: "",
 		Pattern:   "ERROR",
 	})
@@ -225,7 +225,7 @@ func TestHandleSearchLogs_EmptyContainer(t *testing.T) {
 }
 
 func TestHandleSearchLogs_EmptyPattern(t *testing.T) {
-	_, err := handleSearchLogs(context.Background(), docker.NewMock(), searchLogsArgs{
+	_, _, err := handleSearchLogs(context.Background(), docker.NewMock(), searchLogsArgs{
 		Container: "myapp",
 		Pattern:   "",
 	})
@@ -241,7 +241,7 @@ func TestHandleSearchLogs_ContextAtBoundary(t *testing.T) {
 	logOutput := "ERROR first line\nline2\nline3\nline4\nERROR last line"
 	mock.On("logs --tail 1000 myapp", logOutput, nil)
 
-	result, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
+	result, _, err := handleSearchLogs(context.Background(), mock, searchLogsArgs{
 		Container:    "myapp",
 		Pattern:      "ERROR",
 		ContextLines: 2,
@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const defaultHistoryPoints = 60
+
+type statsHistoryArgs struct {
+	Container string  `json:"container" jsonschema:"container name or ID"`
+	Since     string  `json:"since,omitempty" jsonschema:"start of the range: a duration ago (e.g. 20m, 1h) or an RFC3339 time (default: all recorded history)"`
+	Until     string  `json:"until,omitempty" jsonschema:"end of the range: a duration ago or an RFC3339 time (default: now)"`
+	At        string  `json:"at,omitempty" jsonschema:"also report the sample closest to this time: a duration ago (e.g. 20m) or an RFC3339 time"`
+	Metric    string  `json:"metric,omitempty" jsonschema:"metric for above: cpu_percent, mem_percent, mem_usage_bytes or pids"`
+	Above     float64 `json:"above,omitempty" jsonschema:"also report the first sample in the range where metric exceeded this value"`
+	MaxPoints int     `json:"max_points,omitempty" jsonschema:"downsample the returned points to at most this many (default: 60)"`
+}
+
+type historyPoint struct {
+	Time time.Time `json:"time"`
+	docker.Stats
+}
+
+type statsHistoryOutput struct {
+	Container       string                 `json:"container"`
+	IntervalSeconds float64                `json:"interval_seconds" jsonschema:"recorder sampling interval"`
+	TotalPoints     int                    `json:"total_points" jsonschema:"samples in the range before downsampling"`
+	Points          []historyPoint         `json:"points"`
+	Summary         *containerStatsSummary `json:"summary,omitempty"`
+	At              *historyPoint          `json:"at,omitempty" jsonschema:"sample closest to the requested time"`
+	FirstAbove      *historyPoint          `json:"first_above,omitempty" jsonschema:"first sample where metric exceeded above"`
+}
+
+// historyMetrics maps metric names accepted by stats_history to values.
+var historyMetrics = map[string]func(docker.Stats) float64{
+	"cpu_percent":     func(s docker.Stats) float64 { return s.CPUPercent },
+	"mem_percent":     func(s docker.Stats) float64 { return s.MemPercent },
+	"mem_usage_bytes": func(s docker.Stats) float64 { return float64(s.MemUsage) },
+	"pids":            func(s docker.Stats) float64 { return float64(s.PIDs) },
+}
+
+// parseTimeArg parses a time given either as a duration before now or as
+// an RFC3339 timestamp. An empty string yields the zero time.
+func parseTimeArg(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use a duration such as 20m or an RFC3339 time", s)
+	}
+	return t, nil
+}
+
+// downsample picks at most n points spread evenly over points, always
+// keeping the first and last.
+func downsample(points []statsPoint, n int) []statsPoint {
+	if n <= 0 || len(points) <= n {
+		return points
+	}
+	if n == 1 {
+		return points[len(points)-1:]
+	}
+	out := make([]statsPoint, 0, n)
+	step := float64(len(points)-1) / float64(n-1)
+	for i := 0; i < n; i++ {
+		out = append(out, points[int(math.Round(float64(i)*step))])
+	}
+	return out
+}
+
+func closestPoint(points []statsPoint, t time.Time) statsPoint {
+	best := points[0]
+	for _, p := range points[1:] {
+		if p.at.Sub(t).Abs() < best.at.Sub(t).Abs() {
+			best = p
+		}
+	}
+	return best
+}
+
+func newHistoryPoint(p statsPoint) historyPoint {
+	return historyPoint{Time: p.at, Stats: p.stats}
+}
+
+func handleStatsHistory(recorder *StatsRecorder, args statsHistoryArgs) (string, statsHistoryOutput, error) {
+	out := statsHistoryOutput{Container: args.Container}
+	if recorder == nil {
+		return "", out, fmt.Errorf("stats recording is disabled; start the server with -stats-interval to record history")
+	}
+	if args.Container == "" {
+		return "", out, fmt.Errorf("container is required")
+	}
+	var metric func(docker.Stats) float64
+	if args.Metric != "" {
+		var ok bool
+		if metric, ok = historyMetrics[args.Metric]; !ok {
+			return "", out, fmt.Errorf("unknown metric %q: must be cpu_percent, mem_percent, mem_usage_bytes or pids", args.Metric)
+		}
+	}
+
+	now := recorder.clock.Now()
+	since, err := parseTimeArg(args.Since, now)
+	if err != nil {
+		return "", out, err
+	}
+	until, err := parseTimeArg(args.Until, now)
+	if err != nil {
+		return "", out, err
+	}
+	at, err := parseTimeArg(args.At, now)
+	if err != nil {
+		return "", out, err
+	}
+
+	out.IntervalSeconds = recorder.Interval().Seconds()
+	points, ok := recorder.history(args.Container, since, until)
+	if !ok {
+		recorded := recorder.containers()
+		if len(recorded) == 0 {
+			return "", out, fmt.Errorf("no stats history for %q: nothing has been recorded yet", args.Container)
+		}
+		return "", out, fmt.Errorf("no stats history for %q; recorded containers: %s", args.Container, strings.Join(recorded, ", "))
+	}
+	out.TotalPoints = len(points)
+	if len(points) == 0 {
+		return fmt.Sprintf("No samples of %s in the requested range.", args.Container), out, nil
+	}
+
+	summary := summarizeContainer(points)
+	out.Summary = &summary
+	if !at.IsZero() {
+		p := newHistoryPoint(closestPoint(points, at))
+		out.At = &p
+	}
+	if metric != nil {
+		for _, p := range points {
+			if metric(p.stats) > args.Above {
+				hp := newHistoryPoint(p)
+				out.FirstAbove = &hp
+				break
+			}
+		}
+	}
+
+	maxPoints := args.MaxPoints
+	if maxPoints <= 0 {
+		maxPoints = defaultHistoryPoints
+	}
+	for _, p := range downsample(points, maxPoints) {
+		out.Points = append(out.Points, newHistoryPoint(p))
+	}
+
+	return formatStatsHistory(out, args), out, nil
+}
+
+func formatStatsHistory(out statsHistoryOutput, args statsHistoryArgs) string {
+	var b strings.Builder
+	first, last := out.Points[0].Time, out.Points[len(out.Points)-1].Time
+	b.WriteString(fmt.Sprintf("%s: %d samples from %s to %s (every %.0fs)\n", out.Container, out.TotalPoints, first.Format(time.RFC3339), last.Format(time.RFC3339), out.IntervalSeconds))
+	s := out.Summary
+	b.WriteString(fmt.Sprintf("  CPU %%:      %.2f%% / %.2f%% / %.2f%% / %.2f%% (min / avg / p95 / max)\n", s.CPUPercent.Min, s.CPUPercent.Avg, s.CPUPercent.P95, s.CPUPercent.Max))
+	b.WriteString(fmt.Sprintf("  Memory:     %s / %s / %s / %s\n", formatBinary(s.MemUsageBytes.Min), formatBinary(s.MemUsageBytes.Avg), formatBinary(s.MemUsageBytes.P95), formatBinary(s.MemUsageBytes.Max)))
+	b.WriteString(fmt.Sprintf("  Mem growth: %s/min\n", formatSignedBinary(s.MemGrowthBytesPerMin)))
+
+	if out.At != nil {
+		b.WriteString(fmt.Sprintf("\nAt %s: %s\n", out.At.Time.Format(time.RFC3339), formatHistoryPoint(*out.At)))
+	}
+	if args.Metric != "" {
+		if out.FirstAbove != nil {
+			b.WriteString(fmt.Sprintf("\nFirst %s above %g at %s: %s\n", args.Metric, args.Above, out.FirstAbove.Time.Format(time.RFC3339), formatHistoryPoint(*out.FirstAbove)))
+		} else {
+			b.WriteString(fmt.Sprintf("\n%s never exceeded %g in this range.\n", args.Metric, args.Above))
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("\n%-20s  %8s  %10s  %7s  %5s\n", "TIME", "CPU %", "MEM", "MEM %", "PIDS"))
+	for _, p := range out.Points {
+		b.WriteString(fmt.Sprintf("%-20s  %7.2f%%  %10s  %6.2f%%  %5d\n", p.Time.Format(time.RFC3339), p.CPUPercent, binarySize(p.MemUsage), p.MemPercent, p.PIDs))
+	}
+	return b.String()
+}
+
+func formatHistoryPoint(p historyPoint) string {
+	return fmt.Sprintf("CPU %.2f%%, memory %s (%.2f%%), %d PIDs", p.CPUPercent, binarySize(p.MemUsage), p.MemPercent, p.PIDs)
+}
+
+func registerStatsHistory(server *mcp.Server, recorder *StatsRecorder) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "stats_history",
+		Description: "Query resource usage recorded in the background for a container: samples over a time range, the sample closest to a given time (\"what was memory 20 minutes ago?\"), and when a metric first exceeded a threshold. Requires the server to be started with -stats-interval.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args statsHistoryArgs) (*mcp.CallToolResult, statsHistoryOutput, error) {
+		result, out, err := handleStatsHistory(recorder, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
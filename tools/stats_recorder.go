@@ -0,0 +1,272 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// statsSaveInterval is how often a recorder with a file writes it to disk.
+const statsSaveInterval = time.Minute
+
+// StatsRecorderOptions configures a StatsRecorder.
+type StatsRecorderOptions struct {
+	// Interval between samples.
+	Interval time.Duration
+	// Retention is how far back history is kept per container.
+	Retention time.Duration
+	// File, if set, is where history is persisted across restarts.
+	File string
+}
+
+// StatsRecorder samples the stats of all running containers at a fixed
+// interval into a ring buffer per container. Memory is bounded by the
+// retention window, and the history of removed containers is dropped.
+type StatsRecorder struct {
+	exec     docker.Executor
+	clock    clock
+	interval time.Duration
+	capacity int
+	file     string
+
+	mu       sync.Mutex
+	series   map[string]*statsRing
+	lastSave time.Time
+}
+
+// statsRing is a fixed-capacity ring buffer of samples, oldest first.
+type statsRing struct {
+	points []statsPoint
+	start  int
+}
+
+func (r *statsRing) push(p statsPoint, capacity int) {
+	if len(r.points) < capacity {
+		r.points = append(r.points, p)
+		return
+	}
+	r.points[r.start] = p
+	r.start = (r.start + 1) % len(r.points)
+}
+
+// slice returns the samples in chronological order.
+func (r *statsRing) slice() []statsPoint {
+	out := make([]statsPoint, 0, len(r.points))
+	out = append(out, r.points[r.start:]...)
+	return append(out, r.points[:r.start]...)
+}
+
+// recordedPoint is the on-disk form of a statsPoint.
+type recordedPoint struct {
+	Time  time.Time    `json:"time"`
+	Stats docker.Stats `json:"stats"`
+}
+
+// NewStatsRecorder returns a recorder; call Run to start sampling. If
+// opts.File exists, history younger than the retention window is loaded
+// from it.
+func NewStatsRecorder(exec docker.Executor, opts StatsRecorderOptions) (*StatsRecorder, error) {
+	return newStatsRecorder(exec, realClock{}, opts)
+}
+
+func newStatsRecorder(exec docker.Executor, clk clock, opts StatsRecorderOptions) (*StatsRecorder, error) {
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("stats interval must be positive")
+	}
+	if opts.Retention < opts.Interval {
+		return nil, fmt.Errorf("stats retention %s is shorter than the interval %s", opts.Retention, opts.Interval)
+	}
+	r := &StatsRecorder{
+		exec:     exec,
+		clock:    clk,
+		interval: opts.Interval,
+		capacity: int(opts.Retention / opts.Interval),
+		file:     opts.File,
+		series:   make(map[string]*statsRing),
+		lastSave: clk.Now(),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Interval returns the sampling interval.
+func (r *StatsRecorder) Interval() time.Duration { return r.interval }
+
+// Run samples until ctx is done, then saves the history one last time.
+// Sampling errors are logged and do not stop the recorder.
+func (r *StatsRecorder) Run(ctx context.Context) {
+	for {
+		if err := r.record(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("stats recorder: %v", err)
+		}
+		if r.clock.Sleep(ctx, r.interval) != nil {
+			break
+		}
+	}
+	if err := r.save(); err != nil {
+		log.Printf("stats recorder: %v", err)
+	}
+}
+
+// record takes one sample of every running container and drops the history
+// of containers that no longer exist.
+func (r *StatsRecorder) record(ctx context.Context) error {
+	stats, _, err := readStats(ctx, r.exec, "")
+	if err != nil {
+		return fmt.Errorf("failed to read stats: %w", err)
+	}
+	existing, err := psContainers(ctx, r.exec, true)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	at := r.clock.Now()
+
+	r.mu.Lock()
+	for _, s := range stats {
+		key := statsKey(s)
+		ring, ok := r.series[key]
+		if !ok {
+			ring = &statsRing{}
+			r.series[key] = ring
+		}
+		ring.push(statsPoint{at: at, stats: s}, r.capacity)
+	}
+
+	alive := make(map[string]bool, len(existing))
+	for _, c := range existing {
+		alive[c.Names] = true
+		alive[c.ID] = true
+	}
+	for key := range r.series {
+		if !alive[key] {
+			delete(r.series, key)
+		}
+	}
+	saveDue := r.file != "" && at.Sub(r.lastSave) >= statsSaveInterval
+	r.mu.Unlock()
+
+	if saveDue {
+		return r.save()
+	}
+	return nil
+}
+
+// history returns the recorded samples of container between since and
+// until (inclusive; zero values are unbounded), oldest first.
+func (r *StatsRecorder) history(container string, since, until time.Time) ([]statsPoint, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ring, ok := r.series[container]
+	if !ok {
+		// Accept an ID prefix as well as a name.
+		for _, candidate := range r.series {
+			if len(candidate.points) > 0 && container != "" && hasIDPrefix(candidate.points[0].stats.ID, container) {
+				ring, ok = candidate, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+
+	var points []statsPoint
+	for _, p := range ring.slice() {
+		if (!since.IsZero() && p.at.Before(since)) || (!until.IsZero() && p.at.After(until)) {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points, true
+}
+
+// containers returns the names of containers with recorded history.
+func (r *StatsRecorder) containers() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.series))
+	for name := range r.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func hasIDPrefix(id, prefix string) bool {
+	return len(prefix) >= 4 && len(id) >= len(prefix) && id[:len(prefix)] == prefix
+}
+
+func (r *StatsRecorder) load() error {
+	if r.file == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read stats history: %w", err)
+	}
+	var saved map[string][]recordedPoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse stats history %s: %w", r.file, err)
+	}
+
+	cutoff := r.clock.Now().Add(-time.Duration(r.capacity) * r.interval)
+	for key, points := range saved {
+		ring := &statsRing{}
+		for _, p := range points {
+			if p.Time.Before(cutoff) {
+				continue
+			}
+			ring.push(statsPoint{at: p.Time, stats: p.Stats}, r.capacity)
+		}
+		if len(ring.points) > 0 {
+			r.series[key] = ring
+		}
+	}
+	return nil
+}
+
+func (r *StatsRecorder) save() error {
+	if r.file == "" {
+		return nil
+	}
+	r.mu.Lock()
+	saved := make(map[string][]recordedPoint, len(r.series))
+	for key, ring := range r.series {
+		for _, p := range ring.slice() {
+			saved[key] = append(saved[key], recordedPoint{Time: p.at, Stats: p.stats})
+		}
+	}
+	r.lastSave = r.clock.Now()
+	r.mu.Unlock()
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.file), 0o755); err != nil {
+		return fmt.Errorf("failed to save stats history: %w", err)
+	}
+	tmp := r.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save stats history: %w", err)
+	}
+	if err := os.Rename(tmp, r.file); err != nil {
+		return fmt.Errorf("failed to save stats history: %w", err)
+	}
+	return nil
+}
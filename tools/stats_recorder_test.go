@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	recorderStatsCmd = "stats --no-stream --format {{json .}}"
+	recorderPsCmd    = "ps -a --format {{json .}}"
+)
+
+func recordedStatsLine(name string, cpu float64, memMiB int) string {
+	return fmt.Sprintf(`{"Name":%q,"ID":"%s0000","CPUPerc":"%.2f%%","MemUsage":"%dMiB / 1GiB","MemPerc":"%.2f%%","NetIO":"0B / 0B","BlockIO":"0B / 0B","PIDs":"3"}`,
+		name, name, cpu, memMiB, float64(memMiB)/10.24)
+}
+
+func psLine(names ...string) string {
+	var lines []string
+	for _, n := range names {
+		lines = append(lines, fmt.Sprintf(`{"ID":"%s0000","Names":%q,"State":"running"}`, n, n))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// recordN runs n recorder ticks, advancing the fake clock by the interval
+// after each one.
+func recordN(t *testing.T, r *StatsRecorder, clk *fakeClock, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := r.record(context.Background()); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		clk.Advance(r.interval)
+	}
+}
+
+func TestStatsRecorder_RingBufferIsBounded(t *testing.T) {
+	mock := docker.NewMock()
+	var outputs []string
+	for i := 1; i <= 8; i++ {
+		outputs = append(outputs, recordedStatsLine("web", float64(i), 100+i))
+	}
+	mock.OnSequence(recorderStatsCmd, outputs...)
+	mock.On(recorderPsCmd, psLine("web"), nil)
+
+	clk := newFakeClock()
+	r, err := newStatsRecorder(mock, clk, StatsRecorderOptions{Interval: 10 * time.Second, Retention: 50 * time.Second})
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	recordN(t, r, clk, 8)
+
+	points, ok := r.history("web", time.Time{}, time.Time{})
+	if !ok {
+		t.Fatal("expected history for web")
+	}
+	if len(points) != 5 {
+		t.Fatalf("expected 5 retained samples, got %d", len(points))
+	}
+	// The oldest three samples were overwritten; the rest stay in order.
+	for i, p := range points {
+		if want := float64(i + 4); p.stats.CPUPercent != want {
+			t.Errorf("point %d: expected CPU %v, got %v", i, want, p.stats.CPUPercent)
+		}
+	}
+}
+
+func TestStatsRecorder_DropsRemovedContainers(t *testing.T) {
+	mock := docker.NewMock()
+	mock.OnSequence(recorderStatsCmd,
+		recordedStatsLine("web", 1, 100)+"\n"+recordedStatsLine("worker", 2, 200),
+		recordedStatsLine("web", 1, 100),
+	)
+	mock.OnSequence(recorderPsCmd, psLine("web", "worker"), psLine("web"))
+
+	clk := newFakeClock()
+	r, err := newStatsRecorder(mock, clk, StatsRecorderOptions{Interval: time.Second, Retention: time.Minute})
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+
+	recordN(t, r, clk, 1)
+	if got := r.containers(); len(got) != 2 {
+		t.Fatalf("expected two recorded containers, got %v", got)
+	}
+	recordN(t, r, clk, 1)
+	if got := r.containers(); len(got) != 1 || got[0] != "web" {
+		t.Errorf("expected worker to be dropped, got %v", got)
+	}
+}
+
+func TestStatsRecorder_PersistsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	mock := docker.NewMock()
+	mock.On(recorderStatsCmd, recordedStatsLine("web", 5, 100), nil)
+	mock.On(recorderPsCmd, psLine("web"), nil)
+
+	clk := newFakeClock()
+	opts := StatsRecorderOptions{Interval: 30 * time.Second, Retention: 10 * time.Minute, File: path}
+	r, err := newStatsRecorder(mock, clk, opts)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// Run takes one sample, then saves once ctx is done.
+	r.Run(ctx)
+
+	reloaded, err := newStatsRecorder(mock, clk, opts)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	points, ok := reloaded.history("web", time.Time{}, time.Time{})
+	if !ok || len(points) != 1 || points[0].stats.MemUsage != 100*1024*1024 {
+		t.Fatalf("expected the saved sample after reload, got %+v", points)
+	}
+
+	// History older than the retention window is discarded on load.
+	clk.Advance(11 * time.Minute)
+	stale, err := newStatsRecorder(mock, clk, opts)
+	if err != nil {
+		t.Fatalf("reload stale: %v", err)
+	}
+	if _, ok := stale.history("web", time.Time{}, time.Time{}); ok {
+		t.Error("expected expired history to be dropped on load")
+	}
+}
+
+func TestStatsRecorder_SavesPeriodically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	mock := docker.NewMock()
+	mock.On(recorderStatsCmd, recordedStatsLine("web", 5, 100), nil)
+	mock.On(recorderPsCmd, psLine("web"), nil)
+
+	clk := newFakeClock()
+	opts := StatsRecorderOptions{Interval: 20 * time.Second, Retention: time.Hour, File: path}
+	r, err := newStatsRecorder(mock, clk, opts)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	recordN(t, r, clk, 4)
+
+	reloaded, err := newStatsRecorder(mock, clk, opts)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if points, _ := reloaded.history("web", time.Time{}, time.Time{}); len(points) != 4 {
+		t.Errorf("expected 4 samples saved after a minute, got %d", len(points))
+	}
+}
+
+func TestNewStatsRecorder_InvalidOptions(t *testing.T) {
+	if _, err := NewStatsRecorder(docker.NewMock(), StatsRecorderOptions{}); err == nil {
+		t.Error("expected error for zero interval")
+	}
+	if _, err := NewStatsRecorder(docker.NewMock(), StatsRecorderOptions{Interval: time.Minute, Retention: time.Second}); err == nil {
+		t.Error("expected error for retention shorter than the interval")
+	}
+}
+
+func TestHandleStatsHistory(t *testing.T) {
+	mock := docker.NewMock()
+	var outputs []string
+	for _, mem := range []int{100, 200, 300, 950, 400} {
+		outputs = append(outputs, recordedStatsLine("web", 1, mem))
+	}
+	mock.OnSequence(recorderStatsCmd, outputs...)
+	mock.On(recorderPsCmd, psLine("web"), nil)
+
+	clk := newFakeClock()
+	r, err := newStatsRecorder(mock, clk, StatsRecorderOptions{Interval: 5 * time.Minute, Retention: time.Hour})
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	recordN(t, r, clk, 5)
+	// Samples were taken 25, 20, 15, 10 and 5 minutes ago.
+
+	result, out, err := handleStatsHistory(r, statsHistoryArgs{
+		Container: "web",
+		At:        "19m",
+		Metric:    "mem_percent",
+		Above:     90,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.TotalPoints != 5 || len(out.Points) != 5 {
+		t.Errorf("expected 5 points, got %d/%d", out.TotalPoints, len(out.Points))
+	}
+	if out.At == nil || out.At.MemUsage != 200*1024*1024 {
+		t.Errorf("expected the sample from 20 minutes ago, got %+v", out.At)
+	}
+	if out.FirstAbove == nil || out.FirstAbove.MemUsage != 950*1024*1024 {
+		t.Errorf("expected the 950MiB sample above 90%%, got %+v", out.FirstAbove)
+	}
+	if !strings.Contains(result, "First mem_percent above 90") {
+		t.Errorf("expected threshold line, got:\n%s", result)
+	}
+
+	_, out, err = handleStatsHistory(r, statsHistoryArgs{Container: "web", Since: "16m", MaxPoints: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.TotalPoints != 3 || len(out.Points) != 2 || out.Points[1].MemUsage != 400*1024*1024 {
+		t.Errorf("expected 3 samples downsampled to 2, got %d/%+v", out.TotalPoints, out.Points)
+	}
+}
+
+func TestHandleStatsHistory_Errors(t *testing.T) {
+	if _, _, err := handleStatsHistory(nil, statsHistoryArgs{Container: "web"}); err == nil || !strings.Contains(err.Error(), "-stats-interval") {
+		t.Errorf("expected disabled error, got %v", err)
+	}
+
+	mock := docker.NewMock()
+	mock.On(recorderStatsCmd, recordedStatsLine("web", 1, 100), nil)
+	mock.On(recorderPsCmd, psLine("web"), nil)
+	clk := newFakeClock()
+	r, err := newStatsRecorder(mock, clk, StatsRecorderOptions{Interval: time.Second, Retention: time.Minute})
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	recordN(t, r, clk, 1)
+
+	if _, _, err := handleStatsHistory(r, statsHistoryArgs{Container: "db"}); err == nil || !strings.Contains(err.Error(), "recorded containers: web") {
+		t.Errorf("expected unknown container error, got %v", err)
+	}
+	if _, _, err := handleStatsHistory(r, statsHistoryArgs{Container: "web", Metric: "disk"}); err == nil {
+		t.Error("expected error for unknown metric")
+	}
+	if _, _, err := handleStatsHistory(r, statsHistoryArgs{Container: "web", Since: "yesterday"}); err == nil {
+		t.Error("expected error for unparseable time")
+	}
+}
@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	defaultSampleCount  = 10
+	maxSampleCount      = 120
+	defaultSampleWindow = 30
+	maxSampleWindow     = 600
+)
+
+type statsSampleArgs struct {
+	Container string `json:"container,omitempty" jsonschema:"container name or ID (if empty samples all running containers)"`
+	Samples   int    `json:"samples,omitempty" jsonschema:"number of samples to take (default: 10, max: 120)"`
+	Window    int    `json:"window,omitempty" jsonschema:"seconds to spread the samples over (default: 30, max: 600)"`
+}
+
+type statsSampleOutput struct {
+	Samples         int                     `json:"samples" jsonschema:"number of sampling rounds taken"`
+	IntervalSeconds float64                 `json:"interval_seconds"`
+	Containers      []containerStatsSummary `json:"containers"`
+}
+
+// metricSummary summarizes one metric over all samples of a container.
+type metricSummary struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+	P95 float64 `json:"p95"`
+}
+
+type containerStatsSummary struct {
+	Name                  string        `json:"name"`
+	ID                    string        `json:"id"`
+	Samples               int           `json:"samples" jsonschema:"samples that included this container"`
+	CPUPercent            metricSummary `json:"cpu_percent"`
+	MemUsageBytes         metricSummary `json:"mem_usage_bytes"`
+	MemPercent            metricSummary `json:"mem_percent"`
+	MemGrowthBytesPerMin  float64       `json:"mem_growth_bytes_per_min" jsonschema:"least-squares slope of memory usage over time"`
+	NetRxBytesPerSec      metricSummary `json:"net_rx_bytes_per_sec"`
+	NetTxBytesPerSec      metricSummary `json:"net_tx_bytes_per_sec"`
+	BlockReadBytesPerSec  metricSummary `json:"block_read_bytes_per_sec"`
+	BlockWriteBytesPerSec metricSummary `json:"block_write_bytes_per_sec"`
+}
+
+// statsPoint is one sample of one container.
+type statsPoint struct {
+	at    time.Time
+	stats docker.Stats
+}
+
+// summarize computes min/avg/max and the nearest-rank 95th percentile.
+func summarize(values []float64) metricSummary {
+	if len(values) == 0 {
+		return metricSummary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	return metricSummary{
+		Min: sorted[0],
+		Avg: sum / float64(len(sorted)),
+		Max: sorted[len(sorted)-1],
+		P95: sorted[rank],
+	}
+}
+
+// slope returns the least-squares slope of ys over xs, or 0 when it is
+// undefined.
+func slope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	if len(xs) < 2 {
+		return 0
+	}
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	denom := n*sxx - sx*sx
+	if denom == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / denom
+}
+
+// counterRates turns a cumulative counter into per-second rates between
+// consecutive samples. Intervals where the counter went backwards, such as
+// after a container restart, are skipped.
+func counterRates(points []statsPoint, counter func(docker.Stats) uint64) []float64 {
+	var rates []float64
+	for i := 1; i < len(points); i++ {
+		prev, cur := counter(points[i-1].stats), counter(points[i].stats)
+		secs := points[i].at.Sub(points[i-1].at).Seconds()
+		if cur < prev || secs <= 0 {
+			continue
+		}
+		rates = append(rates, float64(cur-prev)/secs)
+	}
+	return rates
+}
+
+// summarizeContainer reduces the samples of one container.
+func summarizeContainer(points []statsPoint) containerStatsSummary {
+	last := points[len(points)-1].stats
+	s := containerStatsSummary{Name: last.Name, ID: last.ID, Samples: len(points)}
+
+	var cpu, mem, memPct, minutes []float64
+	start := points[0].at
+	for _, p := range points {
+		cpu = append(cpu, p.stats.CPUPercent)
+		mem = append(mem, float64(p.stats.MemUsage))
+		memPct = append(memPct, p.stats.MemPercent)
+		minutes = append(minutes, p.at.Sub(start).Minutes())
+	}
+	s.CPUPercent = summarize(cpu)
+	s.MemUsageBytes = summarize(mem)
+	s.MemPercent = summarize(memPct)
+	s.MemGrowthBytesPerMin = slope(minutes, mem)
+	s.NetRxBytesPerSec = summarize(counterRates(points, func(st docker.Stats) uint64 { return st.NetRx }))
+	s.NetTxBytesPerSec = summarize(counterRates(points, func(st docker.Stats) uint64 { return st.NetTx }))
+	s.BlockReadBytesPerSec = summarize(counterRates(points, func(st docker.Stats) uint64 { return st.BlockRead }))
+	s.BlockWriteBytesPerSec = summarize(counterRates(points, func(st docker.Stats) uint64 { return st.BlockWrite }))
+	return s
+}
+
+// statsKey identifies a container across samples.
+func statsKey(s docker.Stats) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.ID
+}
+
+func handleStatsSample(ctx context.Context, exec docker.Executor, clk clock, args statsSampleArgs, notify func(string)) (string, statsSampleOutput, error) {
+	samples := args.Samples
+	if samples <= 0 {
+		samples = defaultSampleCount
+	}
+	if samples > maxSampleCount {
+		samples = maxSampleCount
+	}
+	window := args.Window
+	if window <= 0 {
+		window = defaultSampleWindow
+	}
+	if window > maxSampleWindow {
+		window = maxSampleWindow
+	}
+	var interval time.Duration
+	if samples > 1 {
+		interval = time.Duration(window) * time.Second / time.Duration(samples-1)
+	}
+
+	out := statsSampleOutput{IntervalSeconds: interval.Seconds()}
+	series := make(map[string][]statsPoint)
+	var order []string
+	start := clk.Now()
+	for i := 0; i < samples; i++ {
+		// Schedule against the start time so that the time docker stats
+		// itself takes does not stretch the window.
+		if err := clk.Sleep(ctx, start.Add(time.Duration(i)*interval).Sub(clk.Now())); err != nil {
+			return "", out, fmt.Errorf("stats sampling was cancelled: %w", err)
+		}
+		stats, _, err := readStats(ctx, exec, args.Container)
+		if err != nil {
+			return "", out, fmt.Errorf("failed to get container stats: %w", err)
+		}
+		at := clk.Now()
+		for _, s := range stats {
+			key := statsKey(s)
+			if _, ok := series[key]; !ok {
+				order = append(order, key)
+			}
+			series[key] = append(series[key], statsPoint{at: at, stats: s})
+		}
+		out.Samples++
+		notify(fmt.Sprintf("sample %d/%d: %d containers", i+1, samples, len(stats)))
+	}
+
+	sort.Strings(order)
+	for _, key := range order {
+		out.Containers = append(out.Containers, summarizeContainer(series[key]))
+	}
+	if len(out.Containers) == 0 {
+		return "No running containers found.", out, nil
+	}
+
+	return formatStatsSummary(out), out, nil
+}
+
+func formatStatsSummary(out statsSampleOutput) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%d samples, %.1fs apart (min / avg / p95 / max; I/O as rates per second)\n\n", out.Samples, out.IntervalSeconds))
+	for _, c := range out.Containers {
+		b.WriteString(fmt.Sprintf("%s (%d samples)\n", c.Name, c.Samples))
+		b.WriteString(fmt.Sprintf("  CPU %%:      %.2f%% / %.2f%% / %.2f%% / %.2f%%\n", c.CPUPercent.Min, c.CPUPercent.Avg, c.CPUPercent.P95, c.CPUPercent.Max))
+		b.WriteString(fmt.Sprintf("  Memory:     %s / %s / %s / %s\n", formatBinary(c.MemUsageBytes.Min), formatBinary(c.MemUsageBytes.Avg), formatBinary(c.MemUsageBytes.P95), formatBinary(c.MemUsageBytes.Max)))
+		b.WriteString(fmt.Sprintf("  Mem growth: %s/min\n", formatSignedBinary(c.MemGrowthBytesPerMin)))
+		b.WriteString(fmt.Sprintf("  Net I/O:    rx avg %s/s max %s/s, tx avg %s/s max %s/s\n", formatDecimal(c.NetRxBytesPerSec.Avg), formatDecimal(c.NetRxBytesPerSec.Max), formatDecimal(c.NetTxBytesPerSec.Avg), formatDecimal(c.NetTxBytesPerSec.Max)))
+		b.WriteString(fmt.Sprintf("  Block I/O:  read avg %s/s max %s/s, write avg %s/s max %s/s\n", formatDecimal(c.BlockReadBytesPerSec.Avg), formatDecimal(c.BlockReadBytesPerSec.Max), formatDecimal(c.BlockWriteBytesPerSec.Avg), formatDecimal(c.BlockWriteBytesPerSec.Max)))
+	}
+	return b.String()
+}
+
+func formatBinary(v float64) string { return binarySize(uint64(math.Round(v))) }
+
+func formatDecimal(v float64) string { return decimalSize(uint64(math.Round(v))) }
+
+func formatSignedBinary(v float64) string {
+	if v < 0 {
+		return "-" + formatBinary(-v)
+	}
+	return "+" + formatBinary(v)
+}
+
+func registerStatsSample(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "stats_sample",
+		Description: "Sample container resource usage several times over a window and report min/avg/p95/max per container for CPU, memory, network and block I/O rates, plus the memory growth slope. Useful for spotting leaks and spikes that a single snapshot misses.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args statsSampleArgs) (*mcp.CallToolResult, statsSampleOutput, error) {
+		result, out, err := handleStatsSample(ctx, exec, realClock{}, args, newNotifier(ctx, req, "stats_sample"))
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+func statsLine(name, cpu, mem, net, block string) string {
+	return fmt.Sprintf(`{"Container":"%[1]s","Name":"%[1]s","ID":"%[1]s-id","CPUPerc":"%[2]s","MemUsage":"%[3]s","MemPerc":"1.00%%","NetIO":"%[4]s","BlockIO":"%[5]s","PIDs":"3"}`, name, cpu, mem, net, block)
+}
+
+func TestHandleStatsSample_SummaryAndGrowth(t *testing.T) {
+	mock := docker.NewMock()
+	mock.OnSequence("stats --no-stream --format {{json .}}",
+		statsLine("api", "10.00%", "100MiB / 1GiB", "0B / 0B", "0B / 0B")+"\n"+statsLine("db", "1.00%", "50MiB / 1GiB", "0B / 0B", "0B / 0B"),
+		statsLine("api", "20.00%", "110MiB / 1GiB", "10kB / 1kB", "0B / 0B")+"\n"+statsLine("db", "1.00%", "50MiB / 1GiB", "0B / 0B", "0B / 0B"),
+		statsLine("api", "30.00%", "120MiB / 1GiB", "30kB / 2kB", "0B / 0B")+"\n"+statsLine("db", "1.00%", "50MiB / 1GiB", "0B / 0B", "0B / 0B"),
+		statsLine("api", "40.00%", "130MiB / 1GiB", "60kB / 3kB", "0B / 0B")+"\n"+statsLine("db", "1.00%", "50MiB / 1GiB", "0B / 0B", "0B / 0B"),
+	)
+	clk := newFakeClock()
+
+	var notified []string
+	result, out, err := handleStatsSample(context.Background(), mock, clk, statsSampleArgs{Samples: 4, Window: 30}, func(msg string) {
+		notified = append(notified, msg)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Samples != 4 || out.IntervalSeconds != 10 || len(out.Containers) != 2 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	api := out.Containers[0]
+	if api.Name != "api" || api.Samples != 4 {
+		t.Fatalf("expected api first, got %+v", api)
+	}
+	if api.CPUPercent != (metricSummary{Min: 10, Avg: 25, Max: 40, P95: 40}) {
+		t.Errorf("unexpected CPU summary: %+v", api.CPUPercent)
+	}
+	// 10MiB every 10 seconds is 60MiB per minute.
+	if want := float64(60 << 20); math.Abs(api.MemGrowthBytesPerMin-want) > 1 {
+		t.Errorf("expected memory growth %v/min, got %v", want, api.MemGrowthBytesPerMin)
+	}
+	// rx grows by 10kB, 20kB, 30kB per 10s interval.
+	if api.NetRxBytesPerSec != (metricSummary{Min: 1000, Avg: 2000, Max: 3000, P95: 3000}) {
+		t.Errorf("unexpected rx rates: %+v", api.NetRxBytesPerSec)
+	}
+	if db := out.Containers[1]; db.MemGrowthBytesPerMin != 0 {
+		t.Errorf("expected flat memory for db, got %+v", db)
+	}
+
+	if len(notified) != 4 || notified[3] != "sample 4/4: 2 containers" {
+		t.Errorf("unexpected notifications: %q", notified)
+	}
+	if !strings.Contains(result, "Mem growth: +60MiB/min") {
+		t.Errorf("expected growth in text, got:\n%s", result)
+	}
+	if got := clk.Now().Sub(newFakeClock().Now()).Seconds(); got != 30 {
+		t.Errorf("expected samples to span the 30s window, spanned %vs", got)
+	}
+}
+
+func TestHandleStatsSample_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := handleStatsSample(ctx, docker.NewMock(), newFakeClock(), statsSampleArgs{}, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("expected cancellation error, got %v", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	values := make([]float64, 0, 20)
+	for i := 20; i >= 1; i-- {
+		values = append(values, float64(i))
+	}
+	got := summarize(values)
+	if got != (metricSummary{Min: 1, Avg: 10.5, Max: 20, P95: 19}) {
+		t.Errorf("unexpected summary: %+v", got)
+	}
+	if summarize(nil) != (metricSummary{}) {
+		t.Error("expected zero summary for no values")
+	}
+}
+
+func TestCounterRates_SkipsResets(t *testing.T) {
+	clk := newFakeClock()
+	var points []statsPoint
+	for _, rx := range []uint64{100, 300, 50, 150} {
+		points = append(points, statsPoint{at: clk.Now(), stats: docker.Stats{NetRx: rx}})
+		clk.Advance(1e9)
+	}
+	rates := counterRates(points, func(s docker.Stats) uint64 { return s.NetRx })
+	if len(rates) != 2 || rates[0] != 200 || rates[1] != 100 {
+		t.Errorf("expected the reset interval to be skipped, got %v", rates)
+	}
+}
@@ -0,0 +1,542 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	// backupImage runs tar against the volume in a throwaway container.
+	backupImage = "busybox"
+	// backupTimeLayout stamps backup IDs; it sorts chronologically.
+	backupTimeLayout = "20060102T150405Z"
+	// Files of a backup are named after its ID with these suffixes.
+	backupArchiveSuffix  = ".tar.gz"
+	backupChecksumSuffix = ".tar.gz.sha256"
+	backupManifestSuffix = ".manifest.json"
+)
+
+// backupID matches the IDs of backups, which are volume name and timestamp.
+// Restore passes them to a container, so nothing else is accepted.
+var backupID = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*-\d{8}T\d{6}Z$`)
+
+// volumeName matches the names docker accepts for volumes. Checking it keeps
+// a host path from being mounted in place of a volume.
+var volumeName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// volumeMount returns the --mount value that mounts the named volume at
+// /data. Unlike -v, --mount never treats the source as a host path.
+func volumeMount(name string, readOnly bool) (string, error) {
+	if !volumeName.MatchString(name) {
+		return "", fmt.Errorf("invalid volume name %q", name)
+	}
+	mount := "type=volume,src=" + name + ",dst=/data"
+	if readOnly {
+		mount += ",readonly"
+	}
+	return mount, nil
+}
+
+// backupDirMount returns the --mount value that mounts the backup directory
+// read-only at /backup. docker parses the value as CSV, so a source with a
+// comma or quote is quoted; unlike -v, a colon needs no escaping.
+func backupDirMount(dir string) string {
+	src := "src=" + dir
+	if strings.ContainsAny(dir, `,"`) {
+		src = `"` + strings.ReplaceAll(src, `"`, `""`) + `"`
+	}
+	return "type=bind," + src + ",dst=/backup,readonly"
+}
+
+// restoreScript unpacks the archive $1 into the staging directory $2 inside
+// the volume, and only once that succeeded replaces the volume's contents
+// with it, so a failed unpack leaves the volume as it was. Moving entries
+// within the volume is a rename and does not need free space.
+const restoreScript = `set -e
+rm -rf "$2"
+mkdir "$2"
+if ! tar xzf "$1" -C "$2"; then rm -rf "$2"; exit 1; fi
+find /data -mindepth 1 -maxdepth 1 ! -path "$2" -exec rm -rf {} \;
+find "$2" -mindepth 1 -maxdepth 1 -exec mv {} /data/ \;
+rmdir "$2"`
+
+// volumeBackup is the manifest written next to each archive.
+type volumeBackup struct {
+	ID         string            `json:"id"`
+	Volume     string            `json:"volume"`
+	Project    string            `json:"project,omitempty" jsonschema:"Compose project the volume belonged to"`
+	Driver     string            `json:"driver"`
+	Labels     map[string]string `json:"labels,omitempty" jsonschema:"labels of the volume when it was backed up"`
+	CreatedAt  string            `json:"created_at"`
+	Archive    string            `json:"archive" jsonschema:"archive file name in the backup directory"`
+	Size       int64             `json:"size_bytes" jsonschema:"size of the compressed archive"`
+	VolumeSize int64             `json:"volume_size_bytes" jsonschema:"size of the volume when it was backed up, -1 when docker could not tell"`
+	SHA256     string            `json:"sha256" jsonschema:"SHA-256 of the archive, also written to the .sha256 file"`
+}
+
+// backupDir checks that backups are enabled and returns the absolute
+// backup directory.
+func backupDir(dir string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("volume backups are disabled; start the server with -backup-dir to allow them")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("backup directory: %w", err)
+	}
+	return abs, nil
+}
+
+// readBackup loads the manifest of the backup with the given ID.
+func readBackup(dir, id string) (volumeBackup, error) {
+	var b volumeBackup
+	if !backupID.MatchString(id) {
+		return b, fmt.Errorf("invalid backup ID %q; list backups with list_volume_backups", id)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id+backupManifestSuffix))
+	if err != nil {
+		return b, fmt.Errorf("backup %s: %w", id, err)
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("failed to parse manifest of backup %s: %w", id, err)
+	}
+	if b.ID != id || b.Archive != id+backupArchiveSuffix {
+		return b, fmt.Errorf("manifest of backup %s does not match its file name", id)
+	}
+	return b, nil
+}
+
+// listBackups loads every manifest in dir, newest first. Manifests that
+// cannot be read or do not match their file name are skipped and reported
+// as warnings, so one bad file does not hide the other backups.
+func listBackups(dir string) ([]volumeBackup, []string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []volumeBackup{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+	var warnings []string
+	backups := []volumeBackup{}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), backupManifestSuffix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		b, err := readBackup(dir, id)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: %v", e.Name(), err))
+			continue
+		}
+		backups = append(backups, b)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].CreatedAt > backups[j].CreatedAt
+	})
+	return backups, warnings, nil
+}
+
+// verifyBackup checks the archive of b against the checksum file written
+// with it.
+func verifyBackup(dir string, b volumeBackup) error {
+	want, err := os.ReadFile(filepath.Join(dir, b.ID+backupChecksumSuffix))
+	if err != nil {
+		return fmt.Errorf("backup %s: %w", b.ID, err)
+	}
+	f, err := os.Open(filepath.Join(dir, b.Archive))
+	if err != nil {
+		return fmt.Errorf("backup %s: %w", b.ID, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("backup %s: %w", b.ID, err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if fields := strings.Fields(string(want)); len(fields) == 0 || fields[0] != sum || sum != b.SHA256 {
+		return fmt.Errorf("backup %s is corrupt: archive checksum %s does not match the recorded one", b.ID, sum)
+	}
+	return nil
+}
+
+// runningUsers returns the running containers among a volume's users.
+func runningUsers(users []volumeUser) []string {
+	var running []string
+	for _, u := range users {
+		if u.Running {
+			running = append(running, u.Container)
+		}
+	}
+	return running
+}
+
+type volumeBackupArgs struct {
+	Volume string `json:"volume" jsonschema:"volume to back up"`
+}
+
+type volumeBackupOutput struct {
+	Backup   volumeBackup `json:"backup"`
+	Path     string       `json:"path" jsonschema:"absolute path of the archive on the host"`
+	Running  []string     `json:"running" jsonschema:"running containers that had the volume mounted during the backup"`
+	Warnings []string     `json:"warnings" jsonschema:"volume size or container usage docker could not report"`
+}
+
+// handleVolumeBackup streams a gzipped tar of the volume out of a throwaway
+// container into the backup directory, hashing it on the way, then writes
+// the checksum file and the manifest. Writing through docker's stdout keeps
+// the host directory out of the container.
+func handleVolumeBackup(ctx context.Context, exec docker.Executor, clk clock, dir string, args volumeBackupArgs) (string, volumeBackupOutput, error) {
+	out := volumeBackupOutput{}
+	dir, err := backupDir(dir)
+	if err != nil {
+		return "", out, err
+	}
+	if args.Volume == "" {
+		return "", out, fmt.Errorf("volume is required")
+	}
+	volumes, warnings, err := listVolumeSummaries(ctx, exec, args.Volume)
+	if err != nil {
+		return "", out, err
+	}
+	if len(volumes) != 1 {
+		return "", out, fmt.Errorf("no inspect data returned for volume %s", args.Volume)
+	}
+	v := volumes[0]
+	out.Running, out.Warnings = runningUsers(v.Containers), warnings
+	mount, err := volumeMount(v.Name, true)
+	if err != nil {
+		return "", out, err
+	}
+
+	id := v.Name + "-" + clk.Now().UTC().Format(backupTimeLayout)
+	b := volumeBackup{
+		ID:         id,
+		Volume:     v.Name,
+		Project:    v.Project,
+		Driver:     v.Driver,
+		Labels:     v.Labels,
+		CreatedAt:  clk.Now().UTC().Format(time.RFC3339),
+		Archive:    id + backupArchiveSuffix,
+		VolumeSize: v.Size,
+	}
+	out.Path = filepath.Join(dir, b.Archive)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", out, fmt.Errorf("backup directory: %w", err)
+	}
+	if _, err := os.Stat(out.Path); err == nil {
+		return "", out, fmt.Errorf("backup %s already exists", id)
+	}
+
+	// Write to a temporary file first so a failed backup never looks like
+	// a complete one.
+	tmp, err := os.CreateTemp(dir, "."+id+"-*.partial")
+	if err != nil {
+		return "", out, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	err = exec.ExecTo(ctx, io.MultiWriter(tmp, h), "run", "--rm", "--mount", mount, backupImage, "tar", "czf", "-", "-C", "/data", ".")
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", out, fmt.Errorf("backup of volume %s failed: %w", v.Name, err)
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return "", out, fmt.Errorf("failed to stat archive: %w", err)
+	}
+	b.Size = info.Size()
+	b.SHA256 = hex.EncodeToString(h.Sum(nil))
+	if err := os.Rename(tmp.Name(), out.Path); err != nil {
+		return "", out, fmt.Errorf("failed to save archive: %w", err)
+	}
+
+	checksum := fmt.Sprintf("%s  %s\n", b.SHA256, b.Archive)
+	if err := os.WriteFile(filepath.Join(dir, id+backupChecksumSuffix), []byte(checksum), 0o644); err != nil {
+		return "", out, fmt.Errorf("failed to write checksum: %w", err)
+	}
+	manifest, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", out, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+backupManifestSuffix), append(manifest, '\n'), 0o644); err != nil {
+		return "", out, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	out.Backup = b
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Backed up volume %s (%s) to %s: %s, sha256 %s.\n", v.Name, volumeSize(v.Size), out.Path, decimalSize(uint64(b.Size)), b.SHA256)
+	if len(out.Running) > 0 {
+		fmt.Fprintf(&sb, "Warning: %s had the volume mounted while it was read; stop them first for a consistent snapshot.\n", strings.Join(out.Running, ", "))
+	}
+	writeWarnings(&sb, out.Warnings)
+	return sb.String(), out, nil
+}
+
+type volumeRestoreArgs struct {
+	Backup string `json:"backup" jsonschema:"backup ID from list_volume_backups"`
+	Volume string `json:"volume,omitempty" jsonschema:"volume to restore into (default: the volume that was backed up); created if missing"`
+	Force  bool   `json:"force,omitempty" jsonschema:"restore even if running containers have the volume mounted"`
+}
+
+type volumeRestoreOutput struct {
+	Backup     volumeBackup `json:"backup"`
+	Volume     string       `json:"volume"`
+	Containers []volumeUser `json:"containers" jsonschema:"containers, running or stopped, that mount the volume"`
+}
+
+// handleVolumeRestore verifies a backup and replaces the contents of the
+// volume with it in a throwaway container that mounts the backup directory
+// read-only. See restoreScript for how a failed unpack is kept from
+// emptying the volume.
+func handleVolumeRestore(ctx context.Context, exec docker.Executor, dir string, args volumeRestoreArgs) (string, volumeRestoreOutput, error) {
+	out := volumeRestoreOutput{Volume: args.Volume, Containers: []volumeUser{}}
+	dir, err := backupDir(dir)
+	if err != nil {
+		return "", out, err
+	}
+	if args.Backup == "" {
+		return "", out, fmt.Errorf("backup is required")
+	}
+	b, err := readBackup(dir, args.Backup)
+	if err != nil {
+		return "", out, err
+	}
+	out.Backup = b
+	if out.Volume == "" {
+		out.Volume = b.Volume
+	}
+	mount, err := volumeMount(out.Volume, false)
+	if err != nil {
+		return "", out, err
+	}
+	if err := verifyBackup(dir, b); err != nil {
+		return "", out, err
+	}
+
+	users, err := volumeUsers(ctx, exec)
+	if err != nil {
+		return "", out, err
+	}
+	if u := users[out.Volume]; u != nil {
+		out.Containers = u
+	}
+	if running := runningUsers(out.Containers); len(running) > 0 && !args.Force {
+		return "", out, fmt.Errorf("volume %s is mounted by running containers %s; stop them first or set force", out.Volume, strings.Join(running, ", "))
+	}
+
+	// The archive name is checked by readBackup, and is passed as an
+	// argument rather than spliced into the script.
+	staging := "/data/.restore-" + b.ID
+	_, err = exec.ExecCombined(ctx, "run", "--rm", "--mount", mount, "--mount", backupDirMount(dir), backupImage,
+		"sh", "-c", restoreScript, "restore", "/backup/"+b.Archive, staging)
+	if err != nil {
+		return "", out, fmt.Errorf("restore of volume %s failed: %w; if unpacking the archive failed, the volume was left unchanged, otherwise it may be partly replaced, with the rest of the backup in %s inside it", out.Volume, err, strings.TrimPrefix(staging, "/data/"))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Restored volume %s from backup %s (taken %s", out.Volume, b.ID, b.CreatedAt)
+	if b.Project != "" {
+		fmt.Fprintf(&sb, " from Compose project %s", b.Project)
+	}
+	sb.WriteString("); its previous contents were replaced.\n")
+	if running := runningUsers(out.Containers); len(running) > 0 {
+		fmt.Fprintf(&sb, "Running containers %s had it mounted; restart them to pick up the restored data.\n", strings.Join(running, ", "))
+	}
+	return sb.String(), out, nil
+}
+
+type listVolumeBackupsArgs struct {
+	Volume string `json:"volume,omitempty" jsonschema:"only list backups of this volume"`
+}
+
+type listVolumeBackupsOutput struct {
+	Dir      string         `json:"dir" jsonschema:"backup directory"`
+	Backups  []volumeBackup `json:"backups" jsonschema:"backups, newest first"`
+	Warnings []string       `json:"warnings" jsonschema:"manifests that were skipped because they are invalid"`
+}
+
+func handleListVolumeBackups(dir string, args listVolumeBackupsArgs) (string, listVolumeBackupsOutput, error) {
+	out := listVolumeBackupsOutput{Backups: []volumeBackup{}}
+	dir, err := backupDir(dir)
+	if err != nil {
+		return "", out, err
+	}
+	out.Dir = dir
+	backups, warnings, err := listBackups(dir)
+	if err != nil {
+		return "", out, err
+	}
+	out.Warnings = warnings
+	for _, b := range backups {
+		if args.Volume == "" || b.Volume == args.Volume {
+			out.Backups = append(out.Backups, b)
+		}
+	}
+
+	var sb strings.Builder
+	if len(out.Backups) == 0 {
+		fmt.Fprintf(&sb, "No backups in %s.\n", dir)
+	} else {
+		fmt.Fprintf(&sb, "%d backups in %s:\n", len(out.Backups), dir)
+	}
+	for _, b := range out.Backups {
+		project := b.Project
+		if project == "" {
+			project = "-"
+		}
+		fmt.Fprintf(&sb, "  %-50s %-20s %-16s %8s\n", b.ID, b.CreatedAt, project, decimalSize(uint64(b.Size)))
+	}
+	writeWarnings(&sb, out.Warnings)
+	return sb.String(), out, nil
+}
+
+type volumeBackupPruneArgs struct {
+	Volume        string `json:"volume,omitempty" jsonschema:"only prune backups of this volume"`
+	Keep          int    `json:"keep,omitempty" jsonschema:"always keep the newest N backups of each volume"`
+	OlderThanDays int    `json:"older_than_days,omitempty" jsonschema:"only prune backups older than this many days"`
+	DryRun        bool   `json:"dry_run,omitempty" jsonschema:"only list the backups that would be removed"`
+}
+
+type volumeBackupPruneOutput struct {
+	DryRun    bool           `json:"dry_run"`
+	Removed   []volumeBackup `json:"removed" jsonschema:"backups removed, or that would be for a dry run"`
+	Reclaimed uint64         `json:"reclaimed_bytes"`
+	Warnings  []string       `json:"warnings" jsonschema:"manifests that were skipped because they are invalid; their files are left alone"`
+}
+
+func handleVolumeBackupPrune(clk clock, dir string, args volumeBackupPruneArgs) (string, volumeBackupPruneOutput, error) {
+	out := volumeBackupPruneOutput{DryRun: args.DryRun, Removed: []volumeBackup{}}
+	dir, err := backupDir(dir)
+	if err != nil {
+		return "", out, err
+	}
+	if args.Keep <= 0 && args.OlderThanDays <= 0 {
+		return "", out, fmt.Errorf("keep or older_than_days is required")
+	}
+	backups, warnings, err := listBackups(dir)
+	if err != nil {
+		return "", out, err
+	}
+	out.Warnings = warnings
+
+	cutoff := clk.Now().AddDate(0, 0, -args.OlderThanDays)
+	kept := make(map[string]int)
+	for _, b := range backups {
+		if args.Volume != "" && b.Volume != args.Volume {
+			continue
+		}
+		// Backups are newest first, so the first Keep of each volume stay.
+		kept[b.Volume]++
+		if args.Keep > 0 && kept[b.Volume] <= args.Keep {
+			continue
+		}
+		if args.OlderThanDays > 0 {
+			created, err := time.Parse(time.RFC3339, b.CreatedAt)
+			if err != nil || !created.Before(cutoff) {
+				continue
+			}
+		}
+		out.Removed = append(out.Removed, b)
+		out.Reclaimed += uint64(b.Size)
+	}
+
+	if !args.DryRun {
+		for _, b := range out.Removed {
+			// Remove the manifest last so a partly removed backup is still
+			// listed and can be pruned again.
+			for _, suffix := range []string{backupArchiveSuffix, backupChecksumSuffix, backupManifestSuffix} {
+				if err := os.Remove(filepath.Join(dir, b.ID+suffix)); err != nil && !os.IsNotExist(err) {
+					return "", out, fmt.Errorf("failed to remove backup %s: %w", b.ID, err)
+				}
+			}
+		}
+	}
+
+	verb := "Removed"
+	if args.DryRun {
+		verb = "Dry run: would remove"
+	}
+	var sb strings.Builder
+	if len(out.Removed) == 0 {
+		sb.WriteString("No backups to prune.\n")
+	} else {
+		fmt.Fprintf(&sb, "%s %d backups, %s:\n", verb, len(out.Removed), decimalSize(out.Reclaimed))
+	}
+	for _, b := range out.Removed {
+		fmt.Fprintf(&sb, "  %-50s %s\n", b.ID, b.CreatedAt)
+	}
+	writeWarnings(&sb, out.Warnings)
+	return sb.String(), out, nil
+}
+
+func registerListVolumeBackups(server *mcp.Server, dir string) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_volume_backups",
+		Description: "List volume backups in the server's backup directory, newest first, with backup time, source volume and Compose project, archive size and checksum. Requires the server to be started with -backup-dir.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args listVolumeBackupsArgs) (*mcp.CallToolResult, listVolumeBackupsOutput, error) {
+		result, out, err := handleListVolumeBackups(dir, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
+
+func registerVolumeBackup(server *mcp.Server, exec docker.Executor, dir string) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "volume_backup",
+		Description: "Back up a volume to a gzipped tar archive in the server's backup directory, using a throwaway busybox container, with a SHA-256 checksum file and a manifest recording the backup time and source Compose project. Requires the server to be started with -backup-dir.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args volumeBackupArgs) (*mcp.CallToolResult, volumeBackupOutput, error) {
+		result, out, err := handleVolumeBackup(ctx, exec, realClock{}, dir, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "volume_restore",
+		Description: "Replace the contents of a volume with a backup from the server's backup directory after verifying its checksum. Refuses while running containers have the volume mounted unless force is set.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args volumeRestoreArgs) (*mcp.CallToolResult, volumeRestoreOutput, error) {
+		result, out, err := handleVolumeRestore(ctx, exec, dir, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "volume_backup_prune",
+		Description: "Remove old volume backups, keeping the newest N per volume and/or only removing those older than a number of days. With dry_run, lists the backups that would go.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args volumeBackupPruneArgs) (*mcp.CallToolResult, volumeBackupPruneOutput, error) {
+		result, out, err := handleVolumeBackupPrune(realClock{}, dir, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
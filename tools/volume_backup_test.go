@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// onBackupVolume registers the commands a backup of shop_pgdata runs,
+// producing archive as the tar stream.
+func onBackupVolume(mock *docker.Mock, archive string) {
+	onVolumes(mock)
+	mock.On("volume inspect shop_pgdata", "["+strings.TrimSuffix(strings.Split(volumesInspectJSON, "\n")[1], ",")+"]", nil)
+	mock.On("run --rm --mount type=volume,src=shop_pgdata,dst=/data,readonly busybox tar czf - -C /data .", archive, nil)
+}
+
+func TestHandleVolumeBackup(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+	mock := docker.NewMock()
+	onBackupVolume(mock, "fake tar stream")
+
+	result, out, err := handleVolumeBackup(context.Background(), mock, newFakeClock(), dir, volumeBackupArgs{Volume: "shop_pgdata"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte("fake tar stream"))
+	want := hex.EncodeToString(sum[:])
+	b := out.Backup
+	if b.ID != "shop_pgdata-20240101T000000Z" || b.Project != "shop" || b.CreatedAt != "2024-01-01T00:00:00Z" || b.SHA256 != want || b.Size != 15 || b.VolumeSize != 512_000_000 {
+		t.Errorf("unexpected manifest %+v", b)
+	}
+	if data, err := os.ReadFile(out.Path); err != nil || string(data) != "fake tar stream" {
+		t.Errorf("expected the tar stream in %s, got %q, %v", out.Path, data, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, b.ID+".tar.gz.sha256")); err != nil || string(data) != want+"  "+b.Archive+"\n" {
+		t.Errorf("expected sha256sum-style checksum file, got %q, %v", data, err)
+	}
+	var manifest volumeBackup
+	data, err := os.ReadFile(filepath.Join(dir, b.ID+".manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest.ID != b.ID || manifest.Labels["com.docker.compose.volume"] != "pgdata" {
+		t.Errorf("unexpected manifest file %s, %v", data, err)
+	}
+	if strings.Join(out.Running, ",") != "shop-db-1" || !strings.Contains(result, "Warning: shop-db-1 had the volume mounted") {
+		t.Errorf("expected a consistency warning, got %v:\n%s", out.Running, result)
+	}
+
+	if _, _, err := handleVolumeBackup(context.Background(), mock, newFakeClock(), dir, volumeBackupArgs{Volume: "shop_pgdata"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected a second backup in the same second to be refused, got %v", err)
+	}
+}
+
+func TestHandleVolumeBackup_Failure(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	onVolumes(mock)
+	mock.On("volume inspect shop_pgdata", "["+strings.TrimSuffix(strings.Split(volumesInspectJSON, "\n")[1], ",")+"]", nil)
+	mock.On("run --rm --mount type=volume,src=shop_pgdata,dst=/data,readonly busybox tar czf - -C /data .", "partial", fmt.Errorf("docker run: exit status 1: tar: short write"))
+
+	if _, _, err := handleVolumeBackup(context.Background(), mock, newFakeClock(), dir, volumeBackupArgs{Volume: "shop_pgdata"}); err == nil || !strings.Contains(err.Error(), "short write") {
+		t.Fatalf("expected backup error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no files left behind, got %v", entries)
+	}
+
+	if _, _, err := handleVolumeBackup(context.Background(), mock, newFakeClock(), "", volumeBackupArgs{Volume: "shop_pgdata"}); err == nil || !strings.Contains(err.Error(), "-backup-dir") {
+		t.Errorf("expected backups to be disabled without a directory, got %v", err)
+	}
+}
+
+func TestHandleVolumeRestore(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	onBackupVolume(mock, "fake tar stream")
+	_, backup, err := handleVolumeBackup(context.Background(), mock, newFakeClock(), dir, volumeBackupArgs{Volume: "shop_pgdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := backup.Backup.ID
+	restore := "run --rm --mount type=volume,src=%s,dst=/data --mount type=bind,src=" + dir + ",dst=/backup,readonly busybox sh -c " + restoreScript + " restore /backup/" + id + ".tar.gz /data/.restore-" + id
+	mock.On(fmt.Sprintf(restore, "shop_pgdata"), "", nil)
+	mock.On(fmt.Sprintf(restore, "pgdata_copy"), "", nil)
+
+	_, out, err := handleVolumeRestore(context.Background(), mock, dir, volumeRestoreArgs{Backup: id})
+	if err == nil || !strings.Contains(err.Error(), "mounted by running containers shop-db-1") {
+		t.Fatalf("expected restore into a mounted volume to be refused, got %v", err)
+	}
+	if len(out.Containers) != 2 {
+		t.Errorf("expected the volume's users, got %+v", out.Containers)
+	}
+
+	result, _, err := handleVolumeRestore(context.Background(), mock, dir, volumeRestoreArgs{Backup: id, Force: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "Restored volume shop_pgdata from backup "+id+" (taken 2024-01-01T00:00:00Z from Compose project shop)") || !strings.Contains(result, "restart them") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+
+	if _, out, err := handleVolumeRestore(context.Background(), mock, dir, volumeRestoreArgs{Backup: id, Volume: "pgdata_copy"}); err != nil || out.Volume != "pgdata_copy" {
+		t.Errorf("expected restore into an unused volume, got %+v, %v", out, err)
+	}
+}
+
+func TestHandleVolumeRestore_Refused(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	onBackupVolume(mock, "fake tar stream")
+	_, backup, err := handleVolumeBackup(context.Background(), mock, newFakeClock(), dir, volumeBackupArgs{Volume: "shop_pgdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(backup.Path, []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := handleVolumeRestore(context.Background(), mock, dir, volumeRestoreArgs{Backup: backup.Backup.ID, Force: true}); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("expected checksum mismatch, got %v", err)
+	}
+	for _, id := range []string{"", "../etc-20240101T000000Z", "x; rm -rf /-20240101T000000Z", "ghost-20240101T000000Z"} {
+		if _, _, err := handleVolumeRestore(context.Background(), mock, dir, volumeRestoreArgs{Backup: id}); err == nil {
+			t.Errorf("expected error for backup %q", id)
+		}
+	}
+	if err := os.WriteFile(backup.Path, []byte("fake tar stream"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, volume := range []string{"/etc", "/", "./data", "x,dst=/etc", "v"} {
+		if _, _, err := handleVolumeRestore(context.Background(), mock, dir, volumeRestoreArgs{Backup: backup.Backup.ID, Volume: volume, Force: true}); err == nil || !strings.Contains(err.Error(), "invalid volume name") {
+			t.Errorf("expected volume %q to be refused, got %v", volume, err)
+		}
+	}
+	for _, call := range mock.Calls() {
+		if len(call) > 5 && call[0] == "run" && call[5] == backupDirMount(dir) {
+			t.Errorf("expected no restore to run, got %v", call)
+		}
+	}
+}
+
+func TestHandleVolumeRestore_FailureKeepsContents(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	onBackupVolume(mock, "fake tar stream")
+	_, backup, err := handleVolumeBackup(context.Background(), mock, newFakeClock(), dir, volumeBackupArgs{Volume: "shop_pgdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := backup.Backup.ID
+	mock.On("run --rm --mount type=volume,src=pgdata_copy,dst=/data --mount "+backupDirMount(dir)+" busybox sh -c "+restoreScript+" restore /backup/"+id+".tar.gz /data/.restore-"+id,
+		"tar: write error: No space left on device", fmt.Errorf("docker run: exit status 1"))
+
+	_, _, err = handleVolumeRestore(context.Background(), mock, dir, volumeRestoreArgs{Backup: id, Volume: "pgdata_copy"})
+	if err == nil || !strings.Contains(err.Error(), "the volume was left unchanged") || !strings.Contains(err.Error(), ".restore-"+id) {
+		t.Errorf("expected the error to say what happened to the volume, got %v", err)
+	}
+	// The old contents are only removed after tar succeeded.
+	if strings.Index(restoreScript, "tar xzf") > strings.Index(restoreScript, "rm -rf {}") {
+		t.Errorf("expected the archive to be unpacked before the old contents are removed:\n%s", restoreScript)
+	}
+}
+
+func TestBackupDirMount(t *testing.T) {
+	for dir, want := range map[string]string{
+		"/Users/me/backups":    "type=bind,src=/Users/me/backups,dst=/backup,readonly",
+		"/mnt/c:/backups":      "type=bind,src=/mnt/c:/backups,dst=/backup,readonly",
+		"/srv/a,b":             `type=bind,"src=/srv/a,b",dst=/backup,readonly`,
+		`/srv/"quoted",backup`: `type=bind,"src=/srv/""quoted"",backup",dst=/backup,readonly`,
+	} {
+		if got := backupDirMount(dir); got != want {
+			t.Errorf("backupDirMount(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestHandleVolumeBackupListAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	mock := docker.NewMock()
+	onBackupVolume(mock, "fake tar stream")
+	clk := newFakeClock()
+	for i := 0; i < 3; i++ {
+		if _, _, err := handleVolumeBackup(context.Background(), mock, clk, dir, volumeBackupArgs{Volume: "shop_pgdata"}); err != nil {
+			t.Fatal(err)
+		}
+		clk.Advance(10 * 24 * time.Hour)
+	}
+
+	result, list, err := handleListVolumeBackups(dir, listVolumeBackupsArgs{Volume: "shop_pgdata"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Backups) != 3 || list.Backups[0].ID != "shop_pgdata-20240121T000000Z" {
+		t.Errorf("expected 3 backups newest first, got %+v", list.Backups)
+	}
+	if !strings.Contains(result, "3 backups in "+dir) || !strings.Contains(result, "shop_pgdata-20240111T000000Z") {
+		t.Errorf("unexpected list result:\n%s", result)
+	}
+
+	// Invalid manifests are skipped with a warning rather than failing the
+	// listing, and are left alone by prune.
+	bad := map[string]string{
+		"broken-20240105T000000Z":      "{not json",
+		"shop_pgdata-20240106T000000Z": `{"id":"other-20240106T000000Z","archive":"other-20240106T000000Z.tar.gz"}`,
+		"notes":                        "{}",
+	}
+	for id, data := range bad {
+		if err := os.WriteFile(filepath.Join(dir, id+backupManifestSuffix), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, list, err = handleListVolumeBackups(dir, listVolumeBackupsArgs{})
+	if err != nil || len(list.Backups) != 3 || len(list.Warnings) != 3 {
+		t.Fatalf("expected 3 backups and 3 warnings, got %+v, %v", list, err)
+	}
+	for _, want := range []string{"warning: skipped broken-20240105T000000Z.manifest.json: failed to parse manifest", "does not match its file name", "invalid backup ID"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+	// Now 2024-01-31: keep the newest, and of the rest only prune those
+	// older than 25 days.
+	_, out, err := handleVolumeBackupPrune(clk, dir, volumeBackupPruneArgs{Keep: 1, OlderThanDays: 25, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Removed) != 1 || out.Removed[0].ID != "shop_pgdata-20240101T000000Z" || len(out.Warnings) != 3 {
+		t.Errorf("unexpected dry run %+v", out)
+	}
+	if _, list, _ := handleListVolumeBackups(dir, listVolumeBackupsArgs{}); len(list.Backups) != 3 {
+		t.Errorf("dry run must not remove backups, got %d", len(list.Backups))
+	}
+
+	result, out, err = handleVolumeBackupPrune(clk, dir, volumeBackupPruneArgs{Keep: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Removed) != 2 || out.Reclaimed != 30 || !strings.Contains(result, "Removed 2 backups") {
+		t.Errorf("unexpected prune %+v:\n%s", out, result)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3+len(bad) {
+		t.Errorf("expected the archive, checksum and manifest of one backup and the invalid manifests to remain, got %d files", len(entries))
+	}
+
+	if _, _, err := handleVolumeBackupPrune(clk, dir, volumeBackupPruneArgs{}); err == nil {
+		t.Error("expected error without keep or older_than_days")
+	}
+}
@@ -0,0 +1,524 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+// anonymousVolumeLabel marks volumes docker created for an anonymous mount.
+// Only these are pruned unless all is set.
+const anonymousVolumeLabel = "com.docker.volume.anonymous"
+
+// volumeUser is a container, running or stopped, that mounts a volume.
+type volumeUser struct {
+	Container   string `json:"container"`
+	State       string `json:"state"`
+	Running     bool   `json:"running"`
+	Destination string `json:"destination" jsonschema:"mount point inside the container"`
+	RW          bool   `json:"rw"`
+}
+
+// volumeSummary is one volume with its size and the containers using it.
+type volumeSummary struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Mountpoint string            `json:"mountpoint"`
+	Scope      string            `json:"scope"`
+	Created    string            `json:"created"`
+	Size       int64             `json:"size_bytes" jsonschema:"space the volume uses, -1 when docker cannot tell"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Options    map[string]string `json:"options,omitempty" jsonschema:"driver options"`
+	Project    string            `json:"project,omitempty" jsonschema:"Compose project that created the volume"`
+	Anonymous  bool              `json:"anonymous" jsonschema:"whether docker created the volume for an anonymous mount"`
+	Containers []volumeUser      `json:"containers" jsonschema:"containers, running or stopped, that mount the volume"`
+	Orphaned   bool              `json:"orphaned" jsonschema:"whether no container, running or stopped, mounts the volume; false when the containers could not be listed"`
+}
+
+// volumeUsers maps volume names to the containers, running or stopped, that
+// mount them.
+func volumeUsers(ctx context.Context, exec docker.Executor) (map[string][]volumeUser, error) {
+	details, err := allContainerDetails(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string][]volumeUser)
+	for _, d := range details {
+		for _, m := range d.Mounts {
+			if m.Type != "volume" || m.Name == "" {
+				continue
+			}
+			users[m.Name] = append(users[m.Name], volumeUser{
+				Container:   strings.TrimPrefix(d.Name, "/"),
+				State:       d.State.Status,
+				Running:     d.State.Running,
+				Destination: m.Destination,
+				RW:          m.RW,
+			})
+		}
+	}
+	return users, nil
+}
+
+// volumeSizes maps volume names to their size as reported by docker system
+// df -v. Sizes docker cannot tell, such as for remote drivers, are -1.
+func volumeSizes(ctx context.Context, exec docker.Executor) (map[string]int64, error) {
+	output, err := exec.Exec(ctx, "system", "df", "-v", "--format", "{{json .}}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volume sizes: %w", err)
+	}
+	var df struct {
+		Volumes []struct {
+			Name string `json:"Name"`
+			Size string `json:"Size"`
+		} `json:"Volumes"`
+	}
+	if err := json.Unmarshal([]byte(output), &df); err != nil {
+		return nil, fmt.Errorf("failed to parse system df JSON: %w", err)
+	}
+	sizes := make(map[string]int64, len(df.Volumes))
+	for _, v := range df.Volumes {
+		size, err := parseSize(v.Size)
+		if err != nil {
+			sizes[v.Name] = -1
+			continue
+		}
+		sizes[v.Name] = int64(size)
+	}
+	return sizes, nil
+}
+
+// listVolumeSummaries describes the named volumes, or every volume when
+// none are named, sorted by Compose project and name. Sizes and users are
+// best effort: when docker cannot report them, sizes are -1, no volume is
+// marked orphaned, and the reason is returned as a warning.
+func listVolumeSummaries(ctx context.Context, exec docker.Executor, names ...string) ([]volumeSummary, []string, error) {
+	if len(names) == 0 {
+		output, err := exec.Exec(ctx, "volume", "ls", "--quiet")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list volumes: %w", err)
+		}
+		names = strings.Fields(output)
+		if len(names) == 0 {
+			return []volumeSummary{}, nil, nil
+		}
+	}
+
+	output, err := exec.Exec(ctx, append([]string{"volume", "inspect"}, names...)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("volume inspect failed: %w", err)
+	}
+	var details []struct {
+		Name       string            `json:"Name"`
+		Driver     string            `json:"Driver"`
+		Mountpoint string            `json:"Mountpoint"`
+		Scope      string            `json:"Scope"`
+		CreatedAt  string            `json:"CreatedAt"`
+		Labels     map[string]string `json:"Labels"`
+		Options    map[string]string `json:"Options"`
+	}
+	if err := json.Unmarshal([]byte(output), &details); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse volume inspect JSON: %w", err)
+	}
+	var warnings []string
+	sizes, err := volumeSizes(ctx, exec)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	users, err := volumeUsers(ctx, exec)
+	usersKnown := err == nil
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
+	volumes := make([]volumeSummary, 0, len(details))
+	for _, d := range details {
+		created := d.CreatedAt
+		if t, err := time.Parse(time.RFC3339, d.CreatedAt); err == nil {
+			created = t.UTC().Format(time.RFC3339)
+		}
+		size, ok := sizes[d.Name]
+		if !ok {
+			size = -1
+		}
+		v := volumeSummary{
+			Name:       d.Name,
+			Driver:     d.Driver,
+			Mountpoint: d.Mountpoint,
+			Scope:      d.Scope,
+			Created:    created,
+			Size:       size,
+			Labels:     d.Labels,
+			Options:    d.Options,
+			Project:    d.Labels["com.docker.compose.project"],
+			Containers: users[d.Name],
+		}
+		_, v.Anonymous = d.Labels[anonymousVolumeLabel]
+		v.Orphaned = usersKnown && len(v.Containers) == 0
+		if v.Containers == nil {
+			v.Containers = []volumeUser{}
+		}
+		volumes = append(volumes, v)
+	}
+	sort.SliceStable(volumes, func(i, j int) bool {
+		a, b := volumes[i], volumes[j]
+		if (a.Project == "") != (b.Project == "") {
+			return b.Project == ""
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Name < b.Name
+	})
+	return volumes, warnings, nil
+}
+
+// volumeSize formats a volume size, which may be unknown.
+func volumeSize(n int64) string {
+	if n < 0 {
+		return "unknown"
+	}
+	return decimalSize(uint64(n))
+}
+
+// volumeUse describes the containers mounting a volume in one line.
+func volumeUse(v volumeSummary) string {
+	if v.Orphaned {
+		return "orphaned"
+	}
+	if len(v.Containers) == 0 {
+		return "users unknown"
+	}
+	users := make([]string, len(v.Containers))
+	for i, u := range v.Containers {
+		users[i] = fmt.Sprintf("%s (%s)", u.Container, u.State)
+	}
+	return "used by " + strings.Join(users, ", ")
+}
+
+type listVolumesArgs struct {
+	Project  string `json:"project,omitempty" jsonschema:"only list volumes of this Compose project"`
+	Orphaned bool   `json:"orphaned,omitempty" jsonschema:"only list volumes no container mounts"`
+}
+
+type listVolumesOutput struct {
+	Volumes   []volumeSummary `json:"volumes"`
+	TotalSize int64           `json:"total_size_bytes" jsonschema:"combined size of the listed volumes whose size is known"`
+	Orphaned  int             `json:"orphaned" jsonschema:"number of listed volumes no container mounts"`
+	Warnings  []string        `json:"warnings" jsonschema:"sizes or container usage docker could not report"`
+}
+
+func handleListVolumes(ctx context.Context, exec docker.Executor, args listVolumesArgs) (string, listVolumesOutput, error) {
+	out := listVolumesOutput{Volumes: []volumeSummary{}}
+	volumes, warnings, err := listVolumeSummaries(ctx, exec)
+	if err != nil {
+		return "", out, err
+	}
+	out.Warnings = warnings
+	for _, v := range volumes {
+		if args.Project != "" && v.Project != args.Project {
+			continue
+		}
+		if args.Orphaned && !v.Orphaned {
+			continue
+		}
+		out.Volumes = append(out.Volumes, v)
+		if v.Size > 0 {
+			out.TotalSize += v.Size
+		}
+		if v.Orphaned {
+			out.Orphaned++
+		}
+	}
+
+	if len(out.Volumes) == 0 {
+		return "No volumes found.", out, nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d volumes, %s, %d orphaned.\n", len(out.Volumes), decimalSize(uint64(out.TotalSize)), out.Orphaned)
+	project := "-"
+	for _, v := range out.Volumes {
+		if v.Project != project {
+			project = v.Project
+			if project == "" {
+				sb.WriteString("\nNo project:\n")
+			} else {
+				fmt.Fprintf(&sb, "\nProject %s:\n", project)
+			}
+		}
+		name := v.Name
+		if v.Anonymous {
+			name += " (anonymous)"
+		}
+		fmt.Fprintf(&sb, "  %-40s %-8s %8s  %s\n", name, v.Driver, volumeSize(v.Size), volumeUse(v))
+	}
+	writeWarnings(&sb, out.Warnings)
+	return sb.String(), out, nil
+}
+
+// writeWarnings appends best-effort failures to a tool's text result.
+func writeWarnings(sb *strings.Builder, warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintf(sb, "\nwarning: %s\n", w)
+	}
+}
+
+type volumeInspectArgs struct {
+	Volume string `json:"volume" jsonschema:"volume name"`
+}
+
+type volumeInspectOutput struct {
+	volumeSummary
+	Warnings []string `json:"warnings" jsonschema:"size or container usage docker could not report"`
+}
+
+func handleVolumeInspect(ctx context.Context, exec docker.Executor, args volumeInspectArgs) (string, volumeInspectOutput, error) {
+	out := volumeInspectOutput{volumeSummary: volumeSummary{Name: args.Volume, Containers: []volumeUser{}}}
+	if args.Volume == "" {
+		return "", out, fmt.Errorf("volume is required")
+	}
+	volumes, warnings, err := listVolumeSummaries(ctx, exec, args.Volume)
+	if err != nil {
+		return "", out, err
+	}
+	if len(volumes) != 1 {
+		return "", out, fmt.Errorf("no inspect data returned for volume %s", args.Volume)
+	}
+	out.volumeSummary, out.Warnings = volumes[0], warnings
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Volume: %s\n", out.Name)
+	fmt.Fprintf(&sb, "Driver: %s (%s scope)\n", out.Driver, out.Scope)
+	fmt.Fprintf(&sb, "Mountpoint: %s\n", out.Mountpoint)
+	fmt.Fprintf(&sb, "Created: %s\n", out.Created)
+	fmt.Fprintf(&sb, "Size: %s\n", volumeSize(out.Size))
+	if out.Project != "" {
+		fmt.Fprintf(&sb, "Compose project: %s\n", out.Project)
+	}
+	if out.Anonymous {
+		sb.WriteString("Anonymous: yes\n")
+	}
+	for _, section := range []struct {
+		title  string
+		values map[string]string
+	}{{"Labels", out.Labels}, {"Options", out.Options}} {
+		if len(section.values) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:\n", section.title)
+		keys := make([]string, 0, len(section.values))
+		for k := range section.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&sb, "  %s=%s\n", k, section.values[k])
+		}
+	}
+	switch {
+	case out.Orphaned:
+		sb.WriteString("Containers: none (orphaned)\n")
+	case len(out.Containers) == 0:
+		sb.WriteString("Containers: unknown\n")
+	default:
+		sb.WriteString("Containers:\n")
+		for _, u := range out.Containers {
+			mode := "rw"
+			if !u.RW {
+				mode = "ro"
+			}
+			fmt.Fprintf(&sb, "  %s (%s) -> %s (%s)\n", u.Container, u.State, u.Destination, mode)
+		}
+	}
+	writeWarnings(&sb, out.Warnings)
+	return sb.String(), out, nil
+}
+
+type volumeRemoveArgs struct {
+	Volume string `json:"volume" jsonschema:"volume name"`
+	DryRun bool   `json:"dry_run,omitempty" jsonschema:"only report what would be removed"`
+}
+
+type volumeRemoveOutput struct {
+	Volume     string       `json:"volume"`
+	DryRun     bool         `json:"dry_run"`
+	Size       int64        `json:"size_bytes" jsonschema:"space the volume uses, -1 when docker cannot tell"`
+	Project    string       `json:"project,omitempty"`
+	Containers []volumeUser `json:"containers" jsonschema:"containers that mount the volume, which make docker refuse to remove it"`
+	Removed    bool         `json:"removed"`
+	Warnings   []string     `json:"warnings" jsonschema:"size or container usage docker could not report, for a dry run"`
+}
+
+func handleVolumeRemove(ctx context.Context, exec docker.Executor, args volumeRemoveArgs) (string, volumeRemoveOutput, error) {
+	out := volumeRemoveOutput{Volume: args.Volume, DryRun: args.DryRun, Containers: []volumeUser{}}
+	if args.Volume == "" {
+		return "", out, fmt.Errorf("volume is required")
+	}
+	if !args.DryRun {
+		if _, err := exec.ExecCombined(ctx, "volume", "rm", args.Volume); err != nil {
+			return "", out, fmt.Errorf("volume remove failed: %w", err)
+		}
+		out.Removed = true
+		return fmt.Sprintf("Removed volume %s.\n", args.Volume), out, nil
+	}
+
+	volumes, warnings, err := listVolumeSummaries(ctx, exec, args.Volume)
+	if err != nil {
+		return "", out, err
+	}
+	if len(volumes) != 1 {
+		return "", out, fmt.Errorf("no inspect data returned for volume %s", args.Volume)
+	}
+	v := volumes[0]
+	out.Size, out.Project, out.Containers, out.Warnings = v.Size, v.Project, v.Containers, warnings
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Dry run: would remove volume %s (%s", v.Name, volumeSize(v.Size))
+	if v.Project != "" {
+		fmt.Fprintf(&sb, ", Compose project %s", v.Project)
+	}
+	sb.WriteString(").\n")
+	if len(v.Containers) > 0 {
+		fmt.Fprintf(&sb, "It is %s; docker will refuse until those containers are removed.\n", volumeUse(v))
+	}
+	writeWarnings(&sb, out.Warnings)
+	return sb.String(), out, nil
+}
+
+type volumePruneArgs struct {
+	All    bool `json:"all,omitempty" jsonschema:"remove every volume no container uses, not only anonymous ones"`
+	DryRun bool `json:"dry_run,omitempty" jsonschema:"only list the volumes that would be removed"`
+}
+
+type volumePruneOutput struct {
+	DryRun    bool            `json:"dry_run"`
+	All       bool            `json:"all"`
+	Volumes   []volumeSummary `json:"volumes" jsonschema:"volumes that would be removed, for a dry run"`
+	Deleted   []string        `json:"deleted" jsonschema:"names of the volumes docker removed"`
+	Reclaimed uint64          `json:"reclaimed_bytes" jsonschema:"space docker reclaimed, or would reclaim for a dry run"`
+	Warnings  []string        `json:"warnings" jsonschema:"sizes or container usage docker could not report, for a dry run"`
+}
+
+func handleVolumePrune(ctx context.Context, exec docker.Executor, args volumePruneArgs) (string, volumePruneOutput, error) {
+	out := volumePruneOutput{DryRun: args.DryRun, All: args.All}
+	if !args.DryRun {
+		dockerArgs := []string{"volume", "prune", "--force"}
+		if args.All {
+			dockerArgs = append(dockerArgs, "--all")
+		}
+		output, err := exec.Exec(ctx, dockerArgs...)
+		if err != nil {
+			return "", out, fmt.Errorf("volume prune failed: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "", line == "Deleted Volumes:":
+			case strings.HasPrefix(line, "Total reclaimed space:"):
+				out.Reclaimed, _ = parseSize(strings.TrimPrefix(line, "Total reclaimed space:"))
+			default:
+				out.Deleted = append(out.Deleted, line)
+			}
+		}
+		return fmt.Sprintf("Pruned volumes: %d removed, %s reclaimed.\n", len(out.Deleted), decimalSize(out.Reclaimed)), out, nil
+	}
+
+	volumes, warnings, err := listVolumeSummaries(ctx, exec)
+	if err != nil {
+		return "", out, err
+	}
+	out.Warnings = warnings
+	for _, v := range volumes {
+		// docker keeps any volume a container, even a stopped one, mounts,
+		// and named volumes unless all is set.
+		if !v.Orphaned || (!args.All && !v.Anonymous) {
+			continue
+		}
+		out.Volumes = append(out.Volumes, v)
+		if v.Size > 0 {
+			out.Reclaimed += uint64(v.Size)
+		}
+	}
+
+	kind := "anonymous"
+	if args.All {
+		kind = "unused"
+	}
+	var sb strings.Builder
+	if len(out.Volumes) == 0 {
+		fmt.Fprintf(&sb, "Dry run: no %s volumes to prune.\n", kind)
+	} else {
+		fmt.Fprintf(&sb, "Dry run: would remove %d %s volumes, reclaiming %s:\n", len(out.Volumes), kind, decimalSize(out.Reclaimed))
+	}
+	for _, v := range out.Volumes {
+		project := v.Project
+		if project == "" {
+			project = "-"
+		}
+		fmt.Fprintf(&sb, "  %-40s %-16s %8s  %s\n", v.Name, project, volumeSize(v.Size), v.Created)
+	}
+	writeWarnings(&sb, out.Warnings)
+	return sb.String(), out, nil
+}
+
+func registerVolumeTools(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_volumes",
+		Description: "List volumes grouped by Compose project with driver, mountpoint, size, labels, the containers (running or stopped) that mount each and an orphaned flag, to find leftover state from old stacks.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args listVolumesArgs) (*mcp.CallToolResult, listVolumesOutput, error) {
+		result, out, err := handleListVolumes(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "volume_inspect",
+		Description: "Show a volume's driver, mountpoint, size, labels, options, Compose project and the containers that mount it, with their mount paths.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args volumeInspectArgs) (*mcp.CallToolResult, volumeInspectOutput, error) {
+		result, out, err := handleVolumeInspect(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
+
+func registerVolumeRemoval(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "volume_remove",
+		Description: "Remove a volume (docker volume rm). With dry_run, reports its size, Compose project and the containers that would make docker refuse, without removing anything.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args volumeRemoveArgs) (*mcp.CallToolResult, volumeRemoveOutput, error) {
+		result, out, err := handleVolumeRemove(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "volume_prune",
+		Description: "Remove anonymous volumes no container uses, or with all every unused volume (docker volume prune). With dry_run, lists the volumes that would go and their size, without removing anything.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args volumePruneArgs) (*mcp.CallToolResult, volumePruneOutput, error) {
+		result, out, err := handleVolumePrune(ctx, exec, args)
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
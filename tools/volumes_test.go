@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const volumesInspectJSON = `[
+{"Name":"shop_pgdata","Driver":"local","Mountpoint":"/var/lib/docker/volumes/shop_pgdata/_data","Scope":"local","CreatedAt":"2024-01-01T09:00:00+09:00","Labels":{"com.docker.compose.project":"shop","com.docker.compose.volume":"pgdata"},"Options":null},
+{"Name":"oldstack_cache","Driver":"local","Mountpoint":"/var/lib/docker/volumes/oldstack_cache/_data","Scope":"local","CreatedAt":"2023-06-01T00:00:00Z","Labels":{"com.docker.compose.project":"oldstack"},"Options":null},
+{"Name":"3f2a9c","Driver":"local","Mountpoint":"/var/lib/docker/volumes/3f2a9c/_data","Scope":"local","CreatedAt":"2023-05-01T00:00:00Z","Labels":{"com.docker.volume.anonymous":""},"Options":null},
+{"Name":"nfs_share","Driver":"local","Mountpoint":"/var/lib/docker/volumes/nfs_share/_data","Scope":"local","CreatedAt":"2023-05-01T00:00:00Z","Labels":null,"Options":{"type":"nfs","device":":/export"}}
+]`
+
+// onVolumes registers the commands listVolumeSummaries runs for all volumes.
+func onVolumes(mock *docker.Mock) {
+	mock.On("volume ls --quiet", "shop_pgdata\noldstack_cache\n3f2a9c\nnfs_share\n", nil)
+	mock.On("volume inspect shop_pgdata oldstack_cache 3f2a9c nfs_share", volumesInspectJSON, nil)
+	mock.On("system df -v --format {{json .}}", `{"Images":[],"Containers":[],"Volumes":[{"Name":"shop_pgdata","Size":"512MB"},{"Name":"oldstack_cache","Size":"1.2GB"},{"Name":"3f2a9c","Size":"3MB"},{"Name":"nfs_share","Size":"N/A"}],"BuildCache":[]}`, nil)
+	mock.On("ps -a --format {{json .}}", `{"ID":"c1","Names":"shop-db-1","State":"running","Labels":""}
+{"ID":"c2","Names":"shop-migrate-1","State":"exited","Labels":""}`, nil)
+	mock.On("inspect c1 c2", `[
+{"Id":"c1","Name":"/shop-db-1","State":{"Status":"running","Running":true},"Mounts":[{"Type":"volume","Name":"shop_pgdata","Destination":"/var/lib/postgresql/data","RW":true}]},
+{"Id":"c2","Name":"/shop-migrate-1","State":{"Status":"exited"},"Mounts":[{"Type":"volume","Name":"shop_pgdata","Destination":"/data","RW":false},{"Type":"bind","Source":"/src","Destination":"/src","RW":true}]}
+]`, nil)
+}
+
+func TestHandleListVolumes(t *testing.T) {
+	mock := docker.NewMock()
+	onVolumes(mock)
+
+	result, out, err := handleListVolumes(context.Background(), mock, listVolumesArgs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, v := range out.Volumes {
+		names = append(names, v.Name)
+	}
+	if strings.Join(names, ",") != "oldstack_cache,shop_pgdata,3f2a9c,nfs_share" {
+		t.Errorf("expected volumes sorted by project then name, got %v", names)
+	}
+	pg := out.Volumes[1]
+	if pg.Project != "shop" || pg.Size != 512_000_000 || pg.Orphaned || len(pg.Containers) != 2 || pg.Created != "2024-01-01T00:00:00Z" {
+		t.Errorf("unexpected shop_pgdata %+v", pg)
+	}
+	if u := pg.Containers[1]; u.Container != "shop-migrate-1" || u.Running || u.Destination != "/data" || u.RW {
+		t.Errorf("unexpected user %+v", u)
+	}
+	if !out.Volumes[2].Anonymous || !out.Volumes[2].Orphaned || out.Volumes[3].Size != -1 {
+		t.Errorf("unexpected anonymous or remote volume %+v %+v", out.Volumes[2], out.Volumes[3])
+	}
+	if out.Orphaned != 3 || out.TotalSize != 1_715_000_000 {
+		t.Errorf("expected 3 orphaned and 1.715GB total, got %d and %d", out.Orphaned, out.TotalSize)
+	}
+	for _, want := range []string{
+		"4 volumes, 1.72GB, 3 orphaned.",
+		"Project oldstack:",
+		"shop_pgdata", "used by shop-db-1 (running), shop-migrate-1 (exited)",
+		"No project:",
+		"3f2a9c (anonymous)",
+		"unknown  orphaned",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleListVolumes_Filters(t *testing.T) {
+	mock := docker.NewMock()
+	onVolumes(mock)
+
+	_, out, err := handleListVolumes(context.Background(), mock, listVolumesArgs{Orphaned: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Volumes) != 3 {
+		t.Errorf("expected 3 orphaned volumes, got %+v", out.Volumes)
+	}
+
+	result, out, err := handleListVolumes(context.Background(), mock, listVolumesArgs{Project: "ghost"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Volumes) != 0 || result != "No volumes found." {
+		t.Errorf("expected no volumes, got %q", result)
+	}
+}
+
+func TestHandleListVolumes_BestEffort(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("volume ls --quiet", "shop_pgdata\noldstack_cache\n3f2a9c\nnfs_share\n", nil)
+	mock.On("volume inspect shop_pgdata oldstack_cache 3f2a9c nfs_share", volumesInspectJSON, nil)
+	mock.On("system df -v --format {{json .}}", "", fmt.Errorf("system df timed out"))
+	mock.On("ps -a --format {{json .}}", "", fmt.Errorf("Cannot connect to the Docker daemon"))
+
+	result, out, err := handleListVolumes(context.Background(), mock, listVolumesArgs{})
+	if err != nil {
+		t.Fatalf("expected the listing despite failed sizes and users, got %v", err)
+	}
+	if len(out.Volumes) != 4 || len(out.Warnings) != 2 {
+		t.Fatalf("expected 4 volumes and 2 warnings, got %+v", out)
+	}
+	for _, v := range out.Volumes {
+		if v.Size != -1 || v.Orphaned {
+			t.Errorf("expected unknown size and usage for %+v", v)
+		}
+	}
+	for _, want := range []string{"users unknown", "warning: failed to get volume sizes: system df timed out", "warning: failed to list containers"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+
+	_, prune, err := handleVolumePrune(context.Background(), mock, volumePruneArgs{All: true, DryRun: true})
+	if err != nil || len(prune.Volumes) != 0 || len(prune.Warnings) != 2 {
+		t.Errorf("expected no volume to be offered for pruning while users are unknown, got %+v, %v", prune, err)
+	}
+}
+
+func TestVolumeUsers_ContainerRemovedMeanwhile(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}}", `{"ID":"c1","Names":"shop-db-1","State":"running","Labels":""}
+{"ID":"c2","Names":"shop-migrate-1","State":"exited","Labels":""}`, nil)
+	mock.On("inspect c1 c2", `[{"Id":"c1","Name":"/shop-db-1","State":{"Status":"running","Running":true},"Mounts":[{"Type":"volume","Name":"shop_pgdata","Destination":"/var/lib/postgresql/data","RW":true}]}]`,
+		fmt.Errorf("docker inspect: exit status 1: Error: No such object: c2"))
+
+	users, err := volumeUsers(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("expected the removed container to be skipped, got %v", err)
+	}
+	if u := users["shop_pgdata"]; len(u) != 1 || u[0].Container != "shop-db-1" {
+		t.Errorf("unexpected users %+v", users)
+	}
+
+	mock.On("inspect c1 c2", "[]", fmt.Errorf("docker inspect: exit status 1: Error response from daemon: Cannot connect"))
+	if _, err := volumeUsers(context.Background(), mock); err == nil {
+		t.Error("expected other inspect errors to fail")
+	}
+}
+
+func TestHandleVolumeInspect(t *testing.T) {
+	mock := docker.NewMock()
+	onVolumes(mock)
+	mock.On("volume inspect nfs_share", "["+strings.TrimSuffix(strings.Split(volumesInspectJSON, "\n")[4], ",")+"]", nil)
+	mock.On("volume inspect ghost", "[]", fmt.Errorf("Error response from daemon: get ghost: no such volume"))
+
+	result, out, err := handleVolumeInspect(context.Background(), mock, volumeInspectArgs{Volume: "nfs_share"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Options["type"] != "nfs" || !out.Orphaned {
+		t.Errorf("unexpected output %+v", out)
+	}
+	for _, want := range []string{"Size: unknown", "Options:\n  device=:/export\n  type=nfs", "Containers: none (orphaned)"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+
+	if _, _, err := handleVolumeInspect(context.Background(), mock, volumeInspectArgs{Volume: "ghost"}); err == nil || !strings.Contains(err.Error(), "no such volume") {
+		t.Errorf("expected inspect error, got %v", err)
+	}
+	if _, _, err := handleVolumeInspect(context.Background(), mock, volumeInspectArgs{}); err == nil {
+		t.Error("expected error for missing volume")
+	}
+}
+
+func TestHandleVolumeRemove(t *testing.T) {
+	mock := docker.NewMock()
+	onVolumes(mock)
+	mock.On("volume inspect shop_pgdata", "["+strings.TrimSuffix(strings.Split(volumesInspectJSON, "\n")[1], ",")+"]", nil)
+	mock.On("volume rm oldstack_cache", "oldstack_cache\n", nil)
+
+	result, out, err := handleVolumeRemove(context.Background(), mock, volumeRemoveArgs{Volume: "shop_pgdata", DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Removed || len(out.Containers) != 2 || out.Project != "shop" {
+		t.Errorf("unexpected dry run %+v", out)
+	}
+	if !strings.Contains(result, "would remove volume shop_pgdata (512MB, Compose project shop)") || !strings.Contains(result, "docker will refuse") {
+		t.Errorf("unexpected dry run result:\n%s", result)
+	}
+	for _, call := range mock.Calls() {
+		if call[0] == "volume" && call[1] == "rm" {
+			t.Errorf("dry run must not remove, got %v", call)
+		}
+	}
+
+	if _, out, err := handleVolumeRemove(context.Background(), mock, volumeRemoveArgs{Volume: "oldstack_cache"}); err != nil || !out.Removed {
+		t.Errorf("expected removal, got %+v, %v", out, err)
+	}
+}
+
+func TestHandleVolumePrune(t *testing.T) {
+	mock := docker.NewMock()
+	onVolumes(mock)
+	mock.On("volume prune --force --all", "Deleted Volumes:\noldstack_cache\n3f2a9c\n\nTotal reclaimed space: 1.203GB\n", nil)
+
+	result, out, err := handleVolumePrune(context.Background(), mock, volumePruneArgs{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Volumes) != 1 || out.Volumes[0].Name != "3f2a9c" || out.Reclaimed != 3_000_000 {
+		t.Errorf("expected only the anonymous volume, got %+v", out.Volumes)
+	}
+	if !strings.Contains(result, "would remove 1 anonymous volumes, reclaiming 3MB") {
+		t.Errorf("unexpected dry run result:\n%s", result)
+	}
+
+	_, out, err = handleVolumePrune(context.Background(), mock, volumePruneArgs{All: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Volumes) != 3 || out.Reclaimed != 1_203_000_000 {
+		t.Errorf("expected every orphaned volume, got %d volumes and %d bytes", len(out.Volumes), out.Reclaimed)
+	}
+
+	result, out, err = handleVolumePrune(context.Background(), mock, volumePruneArgs{All: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(out.Deleted, ",") != "oldstack_cache,3f2a9c" || out.Reclaimed != 1_203_000_000 {
+		t.Errorf("unexpected prune output %+v", out)
+	}
+	if !strings.Contains(result, "2 removed, 1.2GB reclaimed") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+}
@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	defaultWaitTimeout = 120
+	maxWaitTimeout     = 600
+)
+
+type waitHealthyArgs struct {
+	Container string `json:"container,omitempty" jsonschema:"container name or ID"`
+	Project   string `json:"project,omitempty" jsonschema:"Compose project name (instead of container); waits for every container of the project"`
+	Service   string `json:"service,omitempty" jsonschema:"only wait for this Compose service of the project"`
+	Timeout   int    `json:"timeout,omitempty" jsonschema:"seconds to wait before giving up (default: 120, max: 600)"`
+}
+
+type waitHealthyOutput struct {
+	Outcome        string          `json:"outcome" jsonschema:"ready, failed or timeout"`
+	ElapsedSeconds float64         `json:"elapsed_seconds"`
+	Containers     []waitContainer `json:"containers"`
+}
+
+type waitContainer struct {
+	Container  string `json:"container"`
+	Service    string `json:"service,omitempty"`
+	Status     string `json:"status" jsonschema:"ready, completed (exited with code 0), waiting, failed or stale (exited before the wait started, left over from an earlier deployment; not waited for)"`
+	State      string `json:"state" jsonschema:"container state, with health status when a healthcheck is configured"`
+	LastOutput string `json:"last_output,omitempty" jsonschema:"output of the most recent health check"`
+}
+
+// pollReadiness inspects every target once and records its readiness. When
+// since is set, a container that would count as failed but already stopped
+// before since is stale: it belongs to an earlier deployment, like a one-off
+// run or a service since removed from the Compose file.
+func pollReadiness(ctx context.Context, exec docker.Executor, targets []containerInfo, results []waitContainer, since time.Time) error {
+	for i, c := range targets {
+		state, err := inspectState(ctx, exec, c.ID)
+		if err != nil {
+			return err
+		}
+		results[i].State = state.describe()
+		results[i].LastOutput = ""
+		if state.Health != nil && len(state.Health.Log) > 0 {
+			results[i].LastOutput = strings.TrimSpace(state.Health.Log[len(state.Health.Log)-1].Output)
+		}
+		switch ready, failed := state.readiness(); {
+		case state.completed():
+			results[i].Status = "completed"
+		case ready:
+			results[i].Status = "ready"
+		case failed && !since.IsZero() && state.finishedBefore(since):
+			results[i].Status = "stale"
+		case failed:
+			results[i].Status = "failed"
+		default:
+			results[i].Status = "waiting"
+		}
+	}
+	return nil
+}
+
+// handleWaitHealthy polls the selected containers until all are ready, one
+// has failed, or the timeout expires. Containers of a project that had
+// already exited when the call started are reported as stale rather than
+// failed, unless no other container is left to wait for. notify receives a
+// short status line whenever the set of pending containers changes.
+func handleWaitHealthy(ctx context.Context, exec docker.Executor, args waitHealthyArgs, notify func(string)) (string, waitHealthyOutput, error) {
+	var out waitHealthyOutput
+	timeout := args.Timeout
+	if timeout <= 0 {
+		timeout = defaultWaitTimeout
+	}
+	if timeout > maxWaitTimeout {
+		timeout = maxWaitTimeout
+	}
+
+	sel := targetSelector{args.Container, args.Project, args.Service}
+	targets, err := resolveTargets(ctx, exec, sel)
+	if err != nil {
+		return "", out, err
+	}
+
+	out.Containers = make([]waitContainer, len(targets))
+	for i, c := range targets {
+		out.Containers[i] = waitContainer{Container: c.Names, Service: c.Labels["com.docker.compose.service"]}
+	}
+
+	start := time.Now()
+	deadline := start.Add(time.Duration(timeout) * time.Second)
+	// A single named container is always waited for, however old its exit.
+	var since time.Time
+	if args.Container == "" {
+		since = start
+	}
+	lastStatus := ""
+	var ready, stale int
+	for {
+		if err := pollReadiness(ctx, exec, targets, out.Containers, since); err != nil {
+			return "", out, err
+		}
+
+		ready, stale = 0, 0
+		var pending, failed, staleNames []string
+		for _, c := range out.Containers {
+			switch c.Status {
+			case "ready", "completed":
+				ready++
+			case "stale":
+				stale++
+				staleNames = append(staleNames, fmt.Sprintf("%s %s", c.Container, c.State))
+			case "failed":
+				failed = append(failed, fmt.Sprintf("%s %s", c.Container, c.State))
+			default:
+				pending = append(pending, fmt.Sprintf("%s %s", c.Container, c.State))
+			}
+		}
+
+		if stale == len(targets) {
+			// Nothing of the current deployment to wait for.
+			failed, stale = staleNames, 0
+			for i := range out.Containers {
+				out.Containers[i].Status = "failed"
+			}
+		}
+
+		status := fmt.Sprintf("%d/%d ready", ready, len(targets)-stale)
+		if len(pending) > 0 {
+			status += "; waiting for " + strings.Join(pending, ", ")
+		}
+		if len(failed) > 0 {
+			status += "; failed: " + strings.Join(failed, ", ")
+		}
+		if stale > 0 {
+			status += "; ignoring stale " + strings.Join(staleNames, ", ")
+		}
+		if status != lastStatus {
+			notify(status)
+			lastStatus = status
+		}
+
+		switch {
+		case len(failed) > 0:
+			out.Outcome = "failed"
+		case ready == len(targets)-stale:
+			out.Outcome = "ready"
+		case !time.Now().Before(deadline):
+			out.Outcome = "timeout"
+		}
+		if out.Outcome != "" {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", out, fmt.Errorf("waiting for %s was cancelled: %w", sel, ctx.Err())
+		case <-time.After(healthPollInterval):
+		}
+	}
+	out.ElapsedSeconds = time.Since(start).Seconds()
+
+	var b strings.Builder
+	elapsed := time.Since(start).Round(100 * time.Millisecond)
+	switch out.Outcome {
+	case "ready":
+		b.WriteString(fmt.Sprintf("All %d containers of %s ready after %s.\n\n", ready, sel, elapsed))
+		if stale > 0 {
+			b.WriteString(fmt.Sprintf("Ignored %d stale container(s) that had already exited before the wait started.\n\n", stale))
+		}
+	case "failed":
+		b.WriteString(fmt.Sprintf("Gave up waiting for %s after %s: a container failed.\n\n", sel, elapsed))
+	default:
+		b.WriteString(fmt.Sprintf("Timed out after %s waiting for %s.\n\n", elapsed, sel))
+	}
+	b.WriteString(formatWaitTable(out.Containers))
+	return b.String(), out, nil
+}
+
+func formatWaitTable(containers []waitContainer) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%-30s %-15s %-8s %-25s %s\n", "CONTAINER", "SERVICE", "STATUS", "STATE", "LAST HEALTH OUTPUT"))
+	for _, c := range containers {
+		output := strings.ReplaceAll(c.LastOutput, "\n", " ")
+		if len(output) > 80 {
+			output = output[:77] + "..."
+		}
+		b.WriteString(fmt.Sprintf("%-30s %-15s %-8s %-25s %s\n", c.Container, c.Service, c.Status, c.State, output))
+	}
+	return b.String()
+}
+
+func registerWaitHealthy(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "wait_healthy",
+		Description: "Wait until a container, or every container of a Compose project, is healthy (or running, when no healthcheck is configured). Stops early if a container fails; project containers that had already exited before the call are reported as stale and ignored. Sends progress notifications while waiting and returns a table of which containers blocked, with their last health check output.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args waitHealthyArgs) (*mcp.CallToolResult, waitHealthyOutput, error) {
+		result, out, err := handleWaitHealthy(ctx, exec, args, newNotifier(ctx, req, "wait_healthy"))
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
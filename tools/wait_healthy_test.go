@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const waitPs = `{"ID":"aaa111","Names":"shop-web-1","State":"running","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}
+{"ID":"bbb222","Names":"shop-db-1","State":"running","Labels":"com.docker.compose.project=shop,com.docker.compose.service=db"}`
+
+func TestHandleWaitHealthy_ProjectBecomesReady(t *testing.T) {
+	fastHealthPolling(t)
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop", waitPs, nil)
+	mock.On("inspect --format {{json .State}} aaa111", `{"Status":"running"}`, nil)
+	mock.OnSequence("inspect --format {{json .State}} bbb222",
+		`{"Status":"running","Health":{"Status":"starting"}}`,
+		`{"Status":"running","Health":{"Status":"starting"}}`,
+		`{"Status":"running","Health":{"Status":"healthy","Log":[{"ExitCode":0,"Output":"accepting connections\n"}]}}`)
+
+	var notified []string
+	result, out, err := handleWaitHealthy(context.Background(), mock, waitHealthyArgs{Project: "shop"}, func(msg string) {
+		notified = append(notified, msg)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Outcome != "ready" {
+		t.Errorf("expected ready outcome, got %+v", out)
+	}
+	if db := out.Containers[1]; db.Service != "db" || db.Status != "ready" || db.LastOutput != "accepting connections" {
+		t.Errorf("unexpected db result: %+v", db)
+	}
+	// Unchanged polls must not produce duplicate notifications.
+	if len(notified) != 2 || !strings.Contains(notified[0], "waiting for shop-db-1 running (starting)") || notified[1] != "2/2 ready" {
+		t.Errorf("unexpected notifications: %q", notified)
+	}
+	if !strings.Contains(result, "All 2 containers of project shop ready") {
+		t.Errorf("expected ready summary, got:\n%s", result)
+	}
+}
+
+func TestHandleWaitHealthy_CompletedJob(t *testing.T) {
+	fastHealthPolling(t)
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop", waitPs, nil)
+	mock.On("inspect --format {{json .State}} aaa111", `{"Status":"running"}`, nil)
+	mock.On("inspect --format {{json .State}} bbb222", `{"Status":"exited","ExitCode":0}`, nil)
+
+	result, out, err := handleWaitHealthy(context.Background(), mock, waitHealthyArgs{Project: "shop"}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Outcome != "ready" || out.Containers[1].Status != "completed" {
+		t.Errorf("expected a finished one-shot container not to fail the wait, got %+v", out)
+	}
+	if !strings.Contains(result, "exited (0)") {
+		t.Errorf("expected exit code in result, got:\n%s", result)
+	}
+}
+
+func TestHandleWaitHealthy_StopsOnFailure(t *testing.T) {
+	fastHealthPolling(t)
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop", waitPs, nil)
+	mock.On("inspect --format {{json .State}} aaa111", `{"Status":"running","Health":{"Status":"unhealthy","Log":[{"ExitCode":1,"Output":"connection refused"}]}}`, nil)
+	mock.On("inspect --format {{json .State}} bbb222", `{"Status":"running","Health":{"Status":"starting"}}`, nil)
+
+	result, out, err := handleWaitHealthy(context.Background(), mock, waitHealthyArgs{Project: "shop"}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Outcome != "failed" || out.Containers[0].Status != "failed" || out.Containers[1].Status != "waiting" {
+		t.Errorf("unexpected output: %+v", out)
+	}
+	for _, want := range []string{"a container failed", "connection refused", "running (unhealthy)"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result, got:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleWaitHealthy_Timeout(t *testing.T) {
+	fastHealthPolling(t)
+	mock := docker.NewMock()
+	mock.On("inspect --format {{json .State}} web", `{"Status":"restarting"}`, nil)
+
+	result, out, err := handleWaitHealthy(context.Background(), mock, waitHealthyArgs{Container: "web", Timeout: 1}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Outcome != "timeout" || out.Containers[0].Status != "waiting" {
+		t.Errorf("expected timeout with web still waiting, got %+v", out)
+	}
+	if !strings.Contains(result, "Timed out") || !strings.Contains(result, "restarting") {
+		t.Errorf("expected timeout summary, got:\n%s", result)
+	}
+}
+
+func TestHandleWaitHealthy_StaleContainers(t *testing.T) {
+	fastHealthPolling(t)
+	const ps = waitPs + `
+{"ID":"ccc333","Names":"shop-web-run-1a2b","State":"exited","Labels":"com.docker.compose.project=shop,com.docker.compose.service=web"}`
+	mock := docker.NewMock()
+	mock.On("ps -a --format {{json .}} --filter label=com.docker.compose.project=shop", ps, nil)
+	mock.On("inspect --format {{json .State}} aaa111", `{"Status":"running"}`, nil)
+	mock.On("inspect --format {{json .State}} bbb222", `{"Status":"running"}`, nil)
+	mock.On("inspect --format {{json .State}} ccc333", `{"Status":"exited","ExitCode":1,"FinishedAt":"2024-01-01T00:10:00Z"}`, nil)
+
+	result, out, err := handleWaitHealthy(context.Background(), mock, waitHealthyArgs{Project: "shop"}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Outcome != "ready" || out.Containers[2].Status != "stale" {
+		t.Errorf("expected a container that exited before the wait not to fail it, got %+v", out)
+	}
+	if !strings.Contains(result, "All 2 containers of project shop ready") || !strings.Contains(result, "Ignored 1 stale container") {
+		t.Errorf("expected ready summary mentioning the stale container, got:\n%s", result)
+	}
+
+	// A named container is waited for regardless of when it exited.
+	mock.On("inspect --format {{json .State}} shop-web-run-1a2b", `{"Status":"exited","ExitCode":1,"FinishedAt":"2024-01-01T00:10:00Z"}`, nil)
+	_, out, err = handleWaitHealthy(context.Background(), mock, waitHealthyArgs{Container: "shop-web-run-1a2b"}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Outcome != "failed" || out.Containers[0].Status != "failed" {
+		t.Errorf("expected the named container to fail the wait, got %+v", out)
+	}
+
+	// With every container stale there is nothing to wait for.
+	mock.On("inspect --format {{json .State}} aaa111", `{"Status":"exited","ExitCode":137,"FinishedAt":"2024-01-01T00:10:00Z"}`, nil)
+	mock.On("inspect --format {{json .State}} bbb222", `{"Status":"exited","ExitCode":137,"FinishedAt":"2024-01-01T00:10:00Z"}`, nil)
+	_, out, err = handleWaitHealthy(context.Background(), mock, waitHealthyArgs{Project: "shop"}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Outcome != "failed" || out.Containers[0].Status != "failed" {
+		t.Errorf("expected a fully stopped project to fail the wait, got %+v", out)
+	}
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const (
+	defaultWatchDuration = 30
+	maxWatchDuration     = 600
+	defaultWatchEvents   = 500
+)
+
+type watchEventsArgs struct {
+	Container string `json:"container,omitempty" jsonschema:"filter events by container name or ID"`
+	Type      string `json:"type,omitempty" jsonschema:"object type to watch: container, image, network or volume (default: container)"`
+	EventType string `json:"event_type,omitempty" jsonschema:"filter by event type: start stop die restart oom kill health_status etc."`
+	Duration  int    `json:"duration,omitempty" jsonschema:"seconds to watch for (default: 30, max: 600)"`
+	MaxEvents int    `json:"max_events,omitempty" jsonschema:"stop after this many events (default: 500)"`
+}
+
+type watchEventsOutput struct {
+	Events          []containerEvent `json:"events" jsonschema:"events in the order they arrived"`
+	Counts          []eventCount     `json:"counts" jsonschema:"number of events per object and action"`
+	DurationSeconds float64          `json:"duration_seconds" jsonschema:"how long events were watched"`
+	StopReason      string           `json:"stop_reason" jsonschema:"why watching stopped: max_events, duration or stream_ended"`
+}
+
+type eventCount struct {
+	Container string `json:"container"`
+	Action    string `json:"action"`
+	Count     int    `json:"count"`
+}
+
+// handleWatchEvents subscribes to docker events until the duration elapses
+// or max_events events have arrived. Each event is passed to notify as it
+// arrives; the returned string is a timeline of the whole run.
+func handleWatchEvents(ctx context.Context, exec docker.Executor, args watchEventsArgs, notify func(string)) (string, watchEventsOutput, error) {
+	var out watchEventsOutput
+	duration := args.Duration
+	if duration <= 0 {
+		duration = defaultWatchDuration
+	}
+	if duration > maxWatchDuration {
+		duration = maxWatchDuration
+	}
+	maxEvents := args.MaxEvents
+	if maxEvents <= 0 {
+		maxEvents = defaultWatchEvents
+	}
+	objectType := args.Type
+	if objectType == "" {
+		objectType = "container"
+	}
+
+	cmdArgs := []string{"events", "--filter", "type=" + objectType}
+	if args.Container != "" {
+		cmdArgs = append(cmdArgs, "--filter", "container="+args.Container)
+	}
+	if args.EventType != "" {
+		cmdArgs = append(cmdArgs, "--filter", "event="+args.EventType)
+	}
+	cmdArgs = append(cmdArgs, "--format", "{{json .}}")
+
+	watchCtx, cancel := context.WithTimeout(ctx, time.Duration(duration)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := exec.Stream(watchCtx, func(line string) {
+		if len(out.Events) >= maxEvents {
+			return
+		}
+		var event dockerEvent
+		if json.Unmarshal([]byte(line), &event) != nil {
+			return
+		}
+		ev := newContainerEvent(event)
+		out.Events = append(out.Events, ev)
+		notify(formatWatchedEvent(ev))
+		if len(out.Events) >= maxEvents {
+			cancel()
+		}
+	}, cmdArgs...)
+	elapsed := time.Since(start).Round(100 * time.Millisecond)
+	out.DurationSeconds = elapsed.Seconds()
+
+	var reason string
+	switch {
+	case len(out.Events) >= maxEvents:
+		reason = fmt.Sprintf("reached max_events (%d)", maxEvents)
+		out.StopReason = "max_events"
+	case ctx.Err() != nil:
+		return "", out, fmt.Errorf("watching events was cancelled: %w", ctx.Err())
+	case errors.Is(err, context.DeadlineExceeded):
+		reason = fmt.Sprintf("duration of %ds elapsed", duration)
+		out.StopReason = "duration"
+	case err != nil:
+		return "", out, fmt.Errorf("failed to watch events: %w", err)
+	default:
+		reason = "event stream ended"
+		out.StopReason = "stream_ended"
+	}
+	out.Counts = countEvents(out.Events)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Watched %s events for %s: %d events, %s.\n", objectType, elapsed, len(out.Events), reason))
+	if len(out.Events) == 0 {
+		return sb.String(), out, nil
+	}
+	sb.WriteString("\nSummary:\n")
+	for _, c := range out.Counts {
+		sb.WriteString(fmt.Sprintf("  %-30s  %-20s  %d\n", c.Container, c.Action, c.Count))
+	}
+	sb.WriteString("\nTimeline:\n")
+	for _, ev := range out.Events {
+		sb.WriteString("  " + formatWatchedEvent(ev) + "\n")
+	}
+	return sb.String(), out, nil
+}
+
+func formatWatchedEvent(ev containerEvent) string {
+	return fmt.Sprintf("%s %s: %s%s", ev.Time.Format("15:04:05.000"), ev.Container, ev.Action, formatEventAttributes(ev.Attributes))
+}
+
+// countEvents counts events per object and action, busiest first.
+func countEvents(events []containerEvent) []eventCount {
+	index := make(map[[2]string]int)
+	var counts []eventCount
+	for _, ev := range events {
+		key := [2]string{ev.Container, ev.Action}
+		i, ok := index[key]
+		if !ok {
+			i = len(counts)
+			index[key] = i
+			counts = append(counts, eventCount{Container: ev.Container, Action: ev.Action})
+		}
+		counts[i].Count++
+	}
+	sort.SliceStable(counts, func(i, j int) bool { return counts[i].Count > counts[j].Count })
+	return counts
+}
+
+func registerWatchEvents(server *mcp.Server, exec docker.Executor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "watch_events",
+		Description: "Subscribe to live docker events (start/stop/die/restart/OOM/health_status etc.) for a bounded duration, with container, object type and event filters. Each event is streamed as a progress/log notification as it arrives; the result is a summarized timeline.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args watchEventsArgs) (*mcp.CallToolResult, watchEventsOutput, error) {
+		result, out, err := handleWatchEvents(ctx, exec, args, newNotifier(ctx, req, "watch_events"))
+		if err != nil {
+			return nil, out, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: result}},
+		}, out, nil
+	})
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/otsukatsuka/orbstack-mcp/docker"
+)
+
+const watchEventsOutputFixture = `{"status":"die","Action":"die","Type":"container","Actor":{"ID":"abc123def4567890","Attributes":{"name":"api","exitCode":"137"}},"time":1700000000,"timeNano":1700000000000000000}
+{"status":"start","Action":"start","Type":"container","Actor":{"ID":"abc123def4567890","Attributes":{"name":"api"}},"time":1700000001,"timeNano":1700000001000000000}
+{"status":"die","Action":"die","Type":"container","Actor":{"ID":"abc123def4567890","Attributes":{"name":"api","exitCode":"137"}},"time":1700000005,"timeNano":1700000005000000000}
+`
+
+func TestHandleWatchEvents_StreamEnded(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("events --filter type=container --filter container=api --format {{json .}}", watchEventsOutputFixture, nil)
+
+	var notified []string
+	result, out, err := handleWatchEvents(context.Background(), mock, watchEventsArgs{Container: "api"}, func(msg string) {
+		notified = append(notified, msg)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(out.Events) != 3 || out.StopReason != "stream_ended" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if len(notified) != 3 || !strings.Contains(notified[0], "api: die (exitCode=137)") {
+		t.Errorf("expected one notification per event, got %v", notified)
+	}
+	if len(out.Counts) != 2 || out.Counts[0] != (eventCount{Container: "api", Action: "die", Count: 2}) {
+		t.Errorf("expected die counted twice first, got %+v", out.Counts)
+	}
+	if !strings.Contains(result, "Timeline:") || !strings.Contains(result, "3 events") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+}
+
+func TestHandleWatchEvents_MaxEventsAndFilters(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("events --filter type=container --filter event=die --format {{json .}}", watchEventsOutputFixture, nil)
+
+	_, out, err := handleWatchEvents(context.Background(), mock, watchEventsArgs{EventType: "die", MaxEvents: 2}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Events) != 2 || out.StopReason != "max_events" {
+		t.Errorf("expected to stop after 2 events, got %d (%s)", len(out.Events), out.StopReason)
+	}
+}
+
+// blockingEvents emits one event and then blocks until ctx is done, like
+// docker events on a quiet daemon.
+type blockingEvents struct {
+	*docker.Mock
+}
+
+func (b blockingEvents) Stream(ctx context.Context, onLine func(string), args ...string) error {
+	onLine(strings.SplitN(watchEventsOutputFixture, "\n", 2)[0])
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestHandleWatchEvents_DurationElapsed(t *testing.T) {
+	result, out, err := handleWatchEvents(context.Background(), blockingEvents{docker.NewMock()}, watchEventsArgs{Duration: 1}, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.StopReason != "duration" || len(out.Events) != 1 {
+		t.Errorf("unexpected output: %+v", out)
+	}
+	if !strings.Contains(result, "duration of 1s elapsed") {
+		t.Errorf("expected duration reason, got:\n%s", result)
+	}
+}
+
+func TestHandleWatchEvents_Errors(t *testing.T) {
+	mock := docker.NewMock()
+	mock.On("events --filter type=network --format {{json .}}", "", fmt.Errorf("daemon connection refused"))
+	if _, _, err := handleWatchEvents(context.Background(), mock, watchEventsArgs{Type: "network"}, func(string) {}); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected docker error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := handleWatchEvents(ctx, blockingEvents{docker.NewMock()}, watchEventsArgs{}, func(string) {}); err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("expected cancellation error, got %v", err)
+	}
+}